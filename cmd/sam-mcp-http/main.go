@@ -2,55 +2,153 @@
 package main
 
 import (
+    "context"
+    "errors"
     "log"
     "net/http"
+    "net/mail"
     "os"
+    "os/signal"
     "strconv"
     "strings"
+    "syscall"
+    "time"
 
+    "sam-mcp/internal/notify"
+    "sam-mcp/internal/prompts"
+    "sam-mcp/internal/sam"
+    "sam-mcp/internal/scheduler"
     "sam-mcp/internal/server"
 )
 
+// shutdownTimeout bounds how long in-flight requests get to finish after SIGINT or SIGTERM.
+const shutdownTimeout = 15 * time.Second
+
 func main() {
     cfg := server.Config{
         Port: getEnv("PORT", "3000"),
         Token: os.Getenv("MCP_TOKEN"),
         SamAPIKey: os.Getenv("SAM_API_KEY"),
         ScheduleToken: os.Getenv("SCHEDULE_TOKEN"),
+        FeedToken: os.Getenv("FEED_TOKEN"),
         PrefetchQ: os.Getenv("PREFETCH_Q"),
         PrefetchNAICS: splitCSV(os.Getenv("PREFETCH_NAICS")),
         PrefetchDays: getEnvInt("PREFETCH_DAYS", 7),
         PrefetchLimit: getEnvInt("PREFETCH_LIMIT", 25),
         PrefetchType: os.Getenv("PREFETCH_NOTICE_TYPE"),
         PrefetchOrg: os.Getenv("PREFETCH_ORG"),
+        SamEnv: os.Getenv("SAM_ENV"),
+        SamBaseURL: os.Getenv("SAM_BASE_URL"),
+        SamAPIVersion: os.Getenv("SAM_API_VERSION"),
+        PromptsDir: os.Getenv("PROMPTS_DIR"),
+        PrefetchSchedule: getSchedule("PREFETCH_SCHEDULE", "0 6,18 * * *"),
+        HierarchySchedule: getSchedule("HIERARCHY_SCHEDULE", "@daily"),
+        SchedulerJitter: getEnvDuration("SCHEDULER_JITTER", 5*time.Minute),
+        DataDir: os.Getenv("DATA_DIR"),
+        WebhookSecret: os.Getenv("WEBHOOK_SECRET"),
+        NotifyAllowedHosts: splitCSV(os.Getenv("NOTIFY_ALLOWED_HOSTS")),
+        SMTP: notify.SMTPConfig{
+            Host: os.Getenv("SMTP_HOST"),
+            Port: getEnvInt("SMTP_PORT", 587),
+            Username: os.Getenv("SMTP_USERNAME"),
+            Password: os.Getenv("SMTP_PASSWORD"),
+            From: os.Getenv("SMTP_FROM"),
+            Security: getEnv("SMTP_SECURITY", notify.SecuritySTARTTLS),
+        },
+        DigestTo: splitCSV(os.Getenv("DIGEST_TO")),
+        DigestSchedule: getSchedule("DIGEST_SCHEDULE", "0 7 * * *"),
+        DigestTemplatesDir: os.Getenv("DIGEST_TEMPLATES_DIR"),
     }
     if cfg.Token == "" {
         log.Println("WARN: MCP_TOKEN not set; endpoints will be open. Set MCP_TOKEN to secure.")
     }
+    samEnv, samBaseURL, err := sam.ResolveEnvironment(cfg.SamEnv, cfg.SamBaseURL)
+    if err != nil {
+        log.Fatalf("invalid SAM_ENV: %v", err)
+    }
+    cfg.SamEnv, cfg.SamBaseURL = samEnv, samBaseURL
+    if _, err := prompts.Load(cfg.PromptsDir); err != nil {
+        log.Fatalf("invalid PROMPTS_DIR: %v", err)
+    }
+    for env, spec := range map[string]string{"PREFETCH_SCHEDULE": cfg.PrefetchSchedule, "HIERARCHY_SCHEDULE": cfg.HierarchySchedule, "DIGEST_SCHEDULE": cfg.DigestSchedule} {
+        if _, err := scheduler.Parse(spec); spec != "" && err != nil {
+            log.Fatalf("invalid %s: %v", env, err)
+        }
+    }
+    if cfg.DataDir == "" {
+        log.Println("INFO: DATA_DIR not set; saved searches, search snapshots and queued notifications are kept in memory and lost on restart.")
+    } else if err := server.CheckDataDir(cfg.DataDir); err != nil {
+        log.Fatalf("invalid DATA_DIR: %v", err)
+    }
+    if cfg.PrefetchProfiles, err = server.LoadProfiles(os.Getenv("PREFETCH_PROFILES_FILE"), cfg.PrefetchSchedule); err != nil {
+        log.Fatalf("invalid PREFETCH_PROFILES_FILE: %v", err)
+    }
+    if cfg.WebhookSecret == "" {
+        log.Println("INFO: WEBHOOK_SECRET not set; webhook notifications are sent unsigned.")
+    }
+    if cfg.SMTP.Host != "" {
+        if err := cfg.SMTP.Validate(); err != nil {
+            log.Fatalf("invalid SMTP settings: %v", err)
+        }
+        for i, addr := range cfg.DigestTo {
+            a, err := mail.ParseAddress(addr)
+            if err != nil {
+                log.Fatalf("invalid DIGEST_TO address %q: %v", addr, err)
+            }
+            cfg.DigestTo[i] = a.Address
+        }
+        if _, err := notify.LoadTemplates(cfg.DigestTemplatesDir); err != nil {
+            log.Fatalf("invalid DIGEST_TEMPLATES_DIR: %v", err)
+        }
+        log.Printf("Email digests: schedule %q via %s:%d (%s)\n", cfg.DigestSchedule, cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.Security)
+    } else if len(cfg.DigestTo) > 0 {
+        log.Println("WARN: DIGEST_TO is set but SMTP_HOST is not; email digests are not sent.")
+    }
     if cfg.SamAPIKey == "" {
         log.Println("INFO: SAM_API_KEY not set; sam_search will use mock data until configured.")
+    } else {
+        log.Printf("SAM.gov environment: %s (%s)\n", cfg.SamEnv, cfg.SamBaseURL)
     }
     srv := server.New(cfg)
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    defer stop()
+    srv.Start(ctx)
+    log.Printf("Scheduler: prefetch %q, hierarchy refresh %q (jitter %s)\n", cfg.PrefetchSchedule, cfg.HierarchySchedule, cfg.SchedulerJitter)
+    for _, p := range cfg.PrefetchProfiles {
+        log.Printf("Prefetch profile %s: schedule %q, ttl %s\n", p.Name, p.Schedule, p.TTL)
+    }
+    httpSrv := &http.Server{Addr: ":" + cfg.Port, Handler: srv.Router()}
+    stopped := make(chan struct{})
+    go func() {
+        defer close(stopped)
+        <-ctx.Done()
+        log.Println("Shutting down MCP HTTP server")
+        sctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+        defer cancel()
+        if err := httpSrv.Shutdown(sctx); err != nil {
+            log.Printf("WARN: shutdown: %v", err)
+        }
+    }()
     log.Printf("Starting MCP HTTP server on :%s\n", cfg.Port)
     // Dev convenience: allow HTTP when ALLOW_INSECURE_HTTP=true (or 1). Default requires TLS.
     allowInsecure := strings.EqualFold(os.Getenv("ALLOW_INSECURE_HTTP"), "true") || os.Getenv("ALLOW_INSECURE_HTTP") == "1"
     if allowInsecure {
         log.Println("WARN: ALLOW_INSECURE_HTTP enabled. Serving HTTP without TLS (dev only).")
-        if err := http.ListenAndServe(":"+cfg.Port, srv.Router()); err != nil {
-            log.Fatalf("server error: %v", err)
+        err = httpSrv.ListenAndServe()
+    } else {
+        certFile := os.Getenv("TLS_CERT_FILE")
+        keyFile := os.Getenv("TLS_KEY_FILE")
+        if certFile == "" || keyFile == "" {
+            log.Fatal("TLS_CERT_FILE and TLS_KEY_FILE are required (or set ALLOW_INSECURE_HTTP=true for local dev). Provide TLS cert/key or run behind a TLS-terminating proxy.")
         }
-        return
-    }
-
-    certFile := os.Getenv("TLS_CERT_FILE")
-    keyFile := os.Getenv("TLS_KEY_FILE")
-    if certFile == "" || keyFile == "" {
-        log.Fatal("TLS_CERT_FILE and TLS_KEY_FILE are required (or set ALLOW_INSECURE_HTTP=true for local dev). Provide TLS cert/key or run behind a TLS-terminating proxy.")
+        log.Println("TLS enabled: using provided certificate and key")
+        err = httpSrv.ListenAndServeTLS(certFile, keyFile)
     }
-    log.Println("TLS enabled: using provided certificate and key")
-    if err := http.ListenAndServeTLS(":"+cfg.Port, certFile, keyFile, srv.Router()); err != nil {
+    if !errors.Is(err, http.ErrServerClosed) {
         log.Fatalf("server error: %v", err)
     }
+    // ListenAndServe returns as soon as Shutdown starts; wait for in-flight requests.
+    <-stopped
 }
 
 func getEnv(key, def string) string {
@@ -69,6 +167,25 @@ func getEnvInt(key string, def int) int {
     return def
 }
 
+// getSchedule reads a cron spec; "off" disables the schedule (the job stays manually triggerable).
+func getSchedule(key, def string) string {
+    v := getEnv(key, def)
+    if strings.EqualFold(v, "off") {
+        return ""
+    }
+    return v
+}
+
+func getEnvDuration(key string, def time.Duration) time.Duration {
+    if v := os.Getenv(key); v != "" {
+        if d, err := time.ParseDuration(v); err == nil {
+            return d
+        }
+        log.Printf("WARN: invalid %s %q; using %s", key, v, def)
+    }
+    return def
+}
+
 func splitCSV(v string) []string {
     if v == "" { return nil }
     parts := strings.Split(v, ",")
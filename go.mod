@@ -4,4 +4,7 @@ go 1.23
 
 toolchain go1.23.12
 
-require github.com/go-chi/chi/v5 v5.2.2
+require (
+	github.com/go-chi/chi/v5 v5.2.2
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,209 @@
+// Package catalog provides embedded NAICS and PSC reference catalogs with code-prefix and
+// keyword lookup.
+package catalog
+
+import (
+	"embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+//go:embed data/*.csv
+var dataFS embed.FS
+
+// Code is one catalog entry. Extra holds the NAICS size standard or the PSC kind
+// (product, service or research).
+type Code struct {
+	Code  string `json:"code"`
+	Title string `json:"title"`
+	Extra string `json:"-"`
+}
+
+// Catalog is an immutable, searchable code list.
+type Catalog struct {
+	codes  []Code
+	byCode map[string]Code
+}
+
+var (
+	naicsOnce, pscOnce sync.Once
+	naics, psc         *Catalog
+)
+
+// NAICS returns the embedded NAICS 2022 catalog.
+func NAICS() *Catalog {
+	naicsOnce.Do(func() { naics = mustLoad("data/naics2022.csv") })
+	return naics
+}
+
+// PSC returns the embedded Product and Service Code catalog.
+func PSC() *Catalog {
+	pscOnce.Do(func() { psc = mustLoad("data/psc.csv") })
+	return psc
+}
+
+func mustLoad(name string) *Catalog {
+	f, err := dataFS.Open(name)
+	if err != nil {
+		panic(fmt.Sprintf("catalog: %v", err))
+	}
+	defer f.Close()
+	c, err := load(f)
+	if err != nil {
+		panic(fmt.Sprintf("catalog %s: %v", name, err))
+	}
+	return c
+}
+
+// load reads a code,title,extra CSV with a header row and # comments.
+func load(r io.Reader) (*Catalog, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = 3
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	c := &Catalog{byCode: make(map[string]Code, len(rows))}
+	for i, row := range rows {
+		if i == 0 {
+			continue
+		}
+		code := Code{Code: strings.TrimSpace(row[0]), Title: strings.TrimSpace(row[1]), Extra: strings.TrimSpace(row[2])}
+		c.codes = append(c.codes, code)
+		c.byCode[strings.ToUpper(code.Code)] = code
+	}
+	return c, nil
+}
+
+// Len reports the number of codes in the catalog.
+func (c *Catalog) Len() int { return len(c.codes) }
+
+// Lookup returns the entry for an exact code.
+func (c *Catalog) Lookup(code string) (Code, bool) {
+	v, ok := c.byCode[strings.ToUpper(strings.TrimSpace(code))]
+	return v, ok
+}
+
+// Search finds codes by prefix when query looks like a code, otherwise by keywords that
+// must all appear in the title. Results are ordered by relevance, then code.
+func (c *Catalog) Search(query string, limit int) []Code {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil
+	}
+	var out []Code
+	if looksLikeCode(query) {
+		out = c.byPrefix(strings.ToUpper(query))
+	}
+	if len(out) == 0 {
+		out = c.byKeywords(query)
+	}
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// Suggest returns close matches for an unknown code: codes sharing the longest prefix with
+// it, most specific first.
+func (c *Catalog) Suggest(code string, limit int) []Code {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	for n := len(code); n > 0; n-- {
+		matches := c.byPrefix(code[:n])
+		if len(matches) == 0 {
+			continue
+		}
+		sort.SliceStable(matches, func(i, j int) bool { return len(matches[i].Code) > len(matches[j].Code) })
+		if limit > 0 && len(matches) > limit {
+			matches = matches[:limit]
+		}
+		return matches
+	}
+	return nil
+}
+
+func (c *Catalog) byPrefix(prefix string) []Code {
+	var out []Code
+	for _, code := range c.codes {
+		if strings.HasPrefix(strings.ToUpper(code.Code), prefix) {
+			out = append(out, code)
+		}
+	}
+	return out
+}
+
+func (c *Catalog) byKeywords(query string) []Code {
+	terms := words(query)
+	if len(terms) == 0 {
+		return nil
+	}
+	type scored struct {
+		code  Code
+		score int
+	}
+	var hits []scored
+	for _, code := range c.codes {
+		title := words(code.Title)
+		score := 0
+		for _, t := range terms {
+			found := false
+			for _, w := range title {
+				if w == t {
+					score += 2
+					found = true
+					break
+				}
+				if strings.HasPrefix(w, t) {
+					score++
+					found = true
+					break
+				}
+			}
+			if !found {
+				score = 0
+				break
+			}
+		}
+		if score > 0 {
+			hits = append(hits, scored{code, score})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].score != hits[j].score {
+			return hits[i].score > hits[j].score
+		}
+		// Prefer specific codes over categories for keyword matches.
+		return len(hits[i].code.Code) > len(hits[j].code.Code)
+	})
+	out := make([]Code, len(hits))
+	for i, h := range hits {
+		out[i] = h.code
+	}
+	return out
+}
+
+// looksLikeCode reports whether q is a single token containing a digit (NAICS codes,
+// PSC codes like R408 or 7A20) or a short all-caps letter code like DA.
+func looksLikeCode(q string) bool {
+	if strings.ContainsAny(q, " \t") {
+		return false
+	}
+	for _, r := range q {
+		if unicode.IsDigit(r) {
+			return true
+		}
+	}
+	return len(q) <= 2 && strings.ToUpper(q) == q
+}
+
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
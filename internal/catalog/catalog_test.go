@@ -0,0 +1,41 @@
+package catalog
+
+import "testing"
+
+func TestNAICSLookupAndSearch(t *testing.T) {
+	c := NAICS()
+	if c.Len() < 1100 {
+		t.Fatalf("catalog too small: %d", c.Len())
+	}
+	code, ok := c.Lookup("541511")
+	if !ok || code.Title != "Custom Computer Programming Services" || code.Extra != "$34M" {
+		t.Fatalf("unexpected lookup: %+v %v", code, ok)
+	}
+	if got := c.Search("54151", 0); len(got) != 4 {
+		t.Fatalf("expected 4 codes under 54151, got %+v", got)
+	}
+	got := c.Search("janitorial", 5)
+	if len(got) == 0 || got[0].Code != "561720" {
+		t.Fatalf("unexpected keyword search: %+v", got)
+	}
+	if got := c.Search("computer programming", 5); len(got) == 0 || got[0].Code != "541511" {
+		t.Fatalf("unexpected multi-keyword search: %+v", got)
+	}
+	sug := c.Suggest("541599", 3)
+	if len(sug) == 0 || sug[0].Code[:4] != "5415" {
+		t.Fatalf("unexpected suggestions: %+v", sug)
+	}
+}
+
+func TestPSCSearch(t *testing.T) {
+	c := PSC()
+	if code, ok := c.Lookup("r408"); !ok || code.Extra != "service" {
+		t.Fatalf("unexpected lookup: %+v %v", code, ok)
+	}
+	if got := c.Search("DA", 0); len(got) != 2 {
+		t.Fatalf("expected DA01 and DA10, got %+v", got)
+	}
+	if got := c.Search("guard", 3); len(got) == 0 || got[0].Code != "S206" {
+		t.Fatalf("unexpected keyword search: %+v", got)
+	}
+}
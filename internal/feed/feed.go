@@ -0,0 +1,205 @@
+// Package feed renders Atom 1.0 and RSS 2.0 feeds from a format-neutral Feed.
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"time"
+)
+
+// Content types of the rendered feeds.
+const (
+	AtomContentType = "application/atom+xml; charset=utf-8"
+	RSSContentType  = "application/rss+xml; charset=utf-8"
+)
+
+const generator = "sam-mcp"
+
+// Feed is a feed and its entries, newest first.
+type Feed struct {
+	// ID identifies the feed permanently, whatever its title or URL.
+	ID       string
+	Title    string
+	Subtitle string
+	// Link is the web page the feed is about and SelfLink the URL of the feed itself; RSS
+	// falls back to SelfLink for its required channel link.
+	Link     string
+	SelfLink string
+	Author   string
+	Updated  time.Time
+	Entries  []Entry
+}
+
+// Entry is one feed item. Readers recognise an entry by its ID, so a changed entry with
+// the same ID and a later Updated is shown as an update rather than a new item.
+type Entry struct {
+	ID         string
+	Title      string
+	Link       string
+	Author     string
+	Categories []string
+	Published  time.Time
+	Updated    time.Time
+	// Content is HTML.
+	Content string
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Author   *atomPerson `xml:"author"`
+	Gen      string      `xml:"generator"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published,omitempty"`
+	Links      []atomLink     `xml:"link"`
+	Author     *atomPerson    `xml:"author"`
+	Categories []atomCategory `xml:"category"`
+	Content    *atomText      `xml:"content"`
+}
+
+// Atom renders f as an Atom 1.0 document.
+func Atom(f Feed) ([]byte, error) {
+	out := atomFeed{ID: f.ID, Title: f.Title, Subtitle: f.Subtitle, Updated: atomTime(f.Updated), Gen: generator}
+	if f.Link != "" {
+		out.Links = append(out.Links, atomLink{Rel: "alternate", Type: "text/html", Href: f.Link})
+	}
+	if f.SelfLink != "" {
+		out.Links = append(out.Links, atomLink{Rel: "self", Type: "application/atom+xml", Href: f.SelfLink})
+	}
+	// Atom requires an author for every entry; the feed's stands in for entries without one.
+	out.Author = &atomPerson{Name: firstNonEmpty(f.Author, generator)}
+	for _, e := range f.Entries {
+		ae := atomEntry{ID: e.ID, Title: e.Title, Updated: atomTime(e.Updated)}
+		if !e.Published.IsZero() {
+			ae.Published = atomTime(e.Published)
+		}
+		if e.Link != "" {
+			ae.Links = []atomLink{{Rel: "alternate", Type: "text/html", Href: e.Link}}
+		}
+		if e.Author != "" {
+			ae.Author = &atomPerson{Name: e.Author}
+		}
+		for _, c := range e.Categories {
+			ae.Categories = append(ae.Categories, atomCategory{Term: c})
+		}
+		if e.Content != "" {
+			ae.Content = &atomText{Type: "html", Body: e.Content}
+		}
+		out.Entries = append(out.Entries, ae)
+	}
+	return marshal(out)
+}
+
+type rssDoc struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Self          *atomLink `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Gen           string    `xml:"generator"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link,omitempty"`
+	Description string   `xml:"description,omitempty"`
+	Categories  []string `xml:"category"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	// Updated carries the Atom updated date; RSS has no field for it.
+	Updated string `xml:"atom:updated"`
+}
+
+// RSS renders f as an RSS 2.0 document. An item's pubDate is its Updated time, so an
+// amended entry moves up in readers that sort by date.
+func RSS(f Feed) ([]byte, error) {
+	ch := rssChannel{
+		Title:         f.Title,
+		Link:          firstNonEmpty(f.Link, f.SelfLink),
+		Description:   firstNonEmpty(f.Subtitle, f.Title),
+		LastBuildDate: f.Updated.UTC().Format(time.RFC1123Z),
+		Gen:           generator,
+	}
+	if f.SelfLink != "" {
+		ch.Self = &atomLink{Rel: "self", Type: "application/rss+xml", Href: f.SelfLink}
+	}
+	for _, e := range f.Entries {
+		ch.Items = append(ch.Items, rssItem{
+			Title:       e.Title,
+			Link:        e.Link,
+			Description: e.Content,
+			Categories:  e.Categories,
+			GUID:        rssGUID{Value: e.ID},
+			PubDate:     e.Updated.UTC().Format(time.RFC1123Z),
+			Updated:     atomTime(e.Updated),
+		})
+	}
+	return marshal(rssDoc{Version: "2.0", AtomNS: "http://www.w3.org/2005/Atom", Channel: ch})
+}
+
+func marshal(v interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	enc := xml.NewEncoder(&b)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	b.WriteString("\n")
+	return b.Bytes(), nil
+}
+
+func atomTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,93 @@
+package feed
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testFeed() Feed {
+	posted := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	return Feed{
+		ID: "urn:sam-mcp:feed:cyber", Title: "SAM.gov: Cyber", Link: "https://sam.gov/search", SelfLink: "https://mcp.example.com/mcp/feeds/cyber.atom",
+		Updated: posted.Add(48 * time.Hour),
+		Entries: []Entry{{
+			ID: "urn:sam-mcp:notice:A", Title: "SOC <support> & monitoring", Link: "https://sam.gov/opp/A/view", Author: "DHS",
+			Categories: []string{"541512"}, Published: posted, Updated: posted.Add(48 * time.Hour),
+			Content: "<p>Due <strong>Apr 1</strong> & later</p>",
+		}},
+	}
+}
+
+func TestAtom(t *testing.T) {
+	raw, err := Atom(testFeed())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+		ID      string   `xml:"id"`
+		Links   []struct {
+			Rel  string `xml:"rel,attr"`
+			Href string `xml:"href,attr"`
+		} `xml:"link"`
+		Entries []struct {
+			ID        string `xml:"id"`
+			Title     string `xml:"title"`
+			Updated   string `xml:"updated"`
+			Published string `xml:"published"`
+			Author    string `xml:"author>name"`
+			Content   struct {
+				Type string `xml:"type,attr"`
+				Body string `xml:",chardata"`
+			} `xml:"content"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(raw, &doc); err != nil {
+		t.Fatalf("%v:\n%s", err, raw)
+	}
+	if doc.ID != "urn:sam-mcp:feed:cyber" || len(doc.Links) != 2 || doc.Links[1].Rel != "self" || len(doc.Entries) != 1 {
+		t.Fatalf("unexpected feed:\n%s", raw)
+	}
+	e := doc.Entries[0]
+	if e.ID != "urn:sam-mcp:notice:A" || e.Title != "SOC <support> & monitoring" || e.Updated != "2026-03-03T09:00:00Z" ||
+		e.Published != "2026-03-01T09:00:00Z" || e.Author != "DHS" || e.Content.Type != "html" || e.Content.Body != "<p>Due <strong>Apr 1</strong> & later</p>" {
+		t.Fatalf("unexpected entry %+v", e)
+	}
+}
+
+func TestRSS(t *testing.T) {
+	raw, err := RSS(testFeed())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Version string `xml:"version,attr"`
+		Channel struct {
+			Title string `xml:"title"`
+			Items []struct {
+				GUID struct {
+					IsPermaLink string `xml:"isPermaLink,attr"`
+					Value       string `xml:",chardata"`
+				} `xml:"guid"`
+				PubDate     string `xml:"pubDate"`
+				Description string `xml:"description"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	if err := xml.Unmarshal(raw, &doc); err != nil {
+		t.Fatalf("%v:\n%s", err, raw)
+	}
+	if doc.Version != "2.0" || doc.Channel.Title != "SAM.gov: Cyber" || len(doc.Channel.Items) != 1 {
+		t.Fatalf("unexpected feed:\n%s", raw)
+	}
+	item := doc.Channel.Items[0]
+	if item.GUID.Value != "urn:sam-mcp:notice:A" || item.GUID.IsPermaLink != "false" || item.PubDate != "Tue, 03 Mar 2026 09:00:00 +0000" ||
+		!strings.Contains(item.Description, "<strong>Apr 1</strong>") {
+		t.Fatalf("unexpected item %+v", item)
+	}
+	if !strings.Contains(string(raw), `<atom:link rel="self"`) {
+		t.Fatalf("self link missing:\n%s", raw)
+	}
+}
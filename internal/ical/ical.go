@@ -0,0 +1,178 @@
+// Package ical renders iCalendar (RFC 5545) calendars for calendar subscriptions.
+//
+// Timed events are written in UTC, which every client converts to its own time zone, so
+// no VTIMEZONE definitions are needed.
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ContentType is the content type of a rendered calendar.
+const ContentType = "text/calendar; charset=utf-8"
+
+const prodID = "-//sam-mcp//SAM.gov deadlines//EN"
+
+// maxLine is the longest content line, in octets, before it is folded.
+const maxLine = 75
+
+// Calendar is a published calendar.
+type Calendar struct {
+	Name        string
+	Description string
+	// Refresh suggests how often subscribers poll for changes; zero leaves it to them.
+	Refresh time.Duration
+	Events  []Event
+}
+
+// Event is one calendar event. Clients match events across refreshes by UID; a later
+// Sequence marks a changed event.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	URL         string
+	Location    string
+	Categories  []string
+	Start       time.Time
+	// AllDay makes the event cover Start's date rather than start at Start.
+	AllDay       bool
+	Stamp        time.Time
+	LastModified time.Time
+	Sequence     int
+	// Alarms are reminders, each given as how long before Start it fires.
+	Alarms []time.Duration
+}
+
+// Render writes c as an iCalendar document.
+func Render(c Calendar) []byte {
+	w := &writer{}
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", prodID)
+	w.line("CALSCALE", "GREGORIAN")
+	w.line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		w.line("X-WR-CALNAME", escape(c.Name))
+	}
+	if c.Description != "" {
+		w.line("X-WR-CALDESC", escape(c.Description))
+	}
+	if c.Refresh > 0 {
+		w.line("REFRESH-INTERVAL;VALUE=DURATION", duration(c.Refresh))
+		w.line("X-PUBLISHED-TTL", duration(c.Refresh))
+	}
+	for _, e := range c.Events {
+		w.event(e)
+	}
+	w.line("END", "VCALENDAR")
+	return w.b.Bytes()
+}
+
+type writer struct {
+	b bytes.Buffer
+}
+
+func (w *writer) event(e Event) {
+	w.line("BEGIN", "VEVENT")
+	w.line("UID", escape(e.UID))
+	w.line("DTSTAMP", utc(e.Stamp))
+	if e.AllDay {
+		w.line("DTSTART;VALUE=DATE", e.Start.Format("20060102"))
+		w.line("DTEND;VALUE=DATE", e.Start.AddDate(0, 0, 1).Format("20060102"))
+	} else {
+		w.line("DTSTART", utc(e.Start))
+		w.line("DTEND", utc(e.Start))
+	}
+	w.line("SUMMARY", escape(e.Summary))
+	if e.Description != "" {
+		w.line("DESCRIPTION", escape(e.Description))
+	}
+	if e.URL != "" {
+		w.line("URL;VALUE=URI", e.URL)
+	}
+	if e.Location != "" {
+		w.line("LOCATION", escape(e.Location))
+	}
+	if len(e.Categories) > 0 {
+		cats := make([]string, len(e.Categories))
+		for i, c := range e.Categories {
+			cats[i] = escape(c)
+		}
+		w.line("CATEGORIES", strings.Join(cats, ","))
+	}
+	if !e.LastModified.IsZero() {
+		w.line("LAST-MODIFIED", utc(e.LastModified))
+	}
+	w.line("SEQUENCE", fmt.Sprint(e.Sequence))
+	// A deadline takes no time, so it should not block the subscriber's calendar.
+	w.line("TRANSP", "TRANSPARENT")
+	for _, before := range e.Alarms {
+		w.line("BEGIN", "VALARM")
+		w.line("ACTION", "DISPLAY")
+		w.line("DESCRIPTION", escape(e.Summary))
+		w.line("TRIGGER", "-"+duration(before))
+		w.line("END", "VALARM")
+	}
+	w.line("END", "VEVENT")
+}
+
+// line writes a content line, folding it at maxLine octets without splitting a UTF-8
+// sequence.
+func (w *writer) line(name, value string) {
+	s := name + ":" + value
+	width := maxLine
+	for len(s) > width {
+		cut := width
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.b.WriteString(s[:cut])
+		w.b.WriteString("\r\n ")
+		s = s[cut:]
+		// Continuation lines start with the folding space.
+		width = maxLine - 1
+	}
+	w.b.WriteString(s)
+	w.b.WriteString("\r\n")
+}
+
+// escape escapes a TEXT value.
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`).Replace(s)
+}
+
+func utc(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// duration formats d as an RFC 5545 duration, e.g. P7D, PT2H or P1DT12H.
+func duration(d time.Duration) string {
+	if d < 0 {
+		d = -d
+	}
+	days := int(d / (24 * time.Hour))
+	d -= time.Duration(days) * 24 * time.Hour
+	out := "P"
+	if days > 0 {
+		out += fmt.Sprintf("%dD", days)
+	}
+	if d > 0 || days == 0 {
+		out += "T"
+		h, m, s := int(d/time.Hour), int(d%time.Hour/time.Minute), int(d%time.Minute/time.Second)
+		if h > 0 {
+			out += fmt.Sprintf("%dH", h)
+		}
+		if m > 0 {
+			out += fmt.Sprintf("%dM", m)
+		}
+		if s > 0 || (h == 0 && m == 0) {
+			out += fmt.Sprintf("%dS", s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,70 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	eastern, _ := time.LoadLocation("America/New_York")
+	due := time.Date(2026, 7, 1, 14, 0, 0, 0, eastern)
+	raw := string(Render(Calendar{
+		Name:    "SAM.gov deadlines: Facilities",
+		Refresh: time.Hour,
+		Events: []Event{
+			{
+				UID: "A-response@sam-mcp", Summary: "Response due: Guard services, Building 7; phase 2", Start: due,
+				Description: "Agency: GSA\nhttps://sam.gov/opp/A/view", URL: "https://sam.gov/opp/A/view",
+				Stamp: due.Add(-72 * time.Hour), LastModified: due.Add(-96 * time.Hour), Sequence: 2,
+				Alarms: []time.Duration{7 * 24 * time.Hour, 2 * time.Hour},
+			},
+			{UID: "A-questions@sam-mcp", Summary: "Questions due: " + strings.Repeat("é", 60), Start: time.Date(2026, 6, 15, 0, 0, 0, 0, time.UTC), AllDay: true},
+		},
+	}))
+
+	lines := strings.Split(raw, "\r\n")
+	if lines[0] != "BEGIN:VCALENDAR" || lines[len(lines)-2] != "END:VCALENDAR" || lines[len(lines)-1] != "" {
+		t.Fatalf("not a calendar:\n%s", raw)
+	}
+	for _, want := range []string{
+		"REFRESH-INTERVAL;VALUE=DURATION:PT1H",
+		// 14:00 EDT is 18:00 UTC.
+		"DTSTART:20260701T180000Z",
+		`SUMMARY:Response due: Guard services\, Building 7\; phase 2`,
+		`DESCRIPTION:Agency: GSA\nhttps://sam.gov/opp/A/view`,
+		"LAST-MODIFIED:20260627T180000Z",
+		"SEQUENCE:2",
+		"TRIGGER:-P7D",
+		"TRIGGER:-PT2H",
+		"DTSTART;VALUE=DATE:20260615",
+		"DTEND;VALUE=DATE:20260616",
+	} {
+		if !strings.Contains(raw, want+"\r\n") {
+			t.Errorf("calendar lacks %q:\n%s", want, raw)
+		}
+	}
+	for _, l := range lines {
+		if len(l) > maxLine {
+			t.Errorf("line longer than %d octets: %q", maxLine, l)
+		}
+	}
+	// Unfolding restores the long summary.
+	if !strings.Contains(strings.ReplaceAll(raw, "\r\n ", ""), "SUMMARY:Questions due: "+strings.Repeat("é", 60)+"\r\n") {
+		t.Errorf("folded summary does not unfold:\n%s", raw)
+	}
+}
+
+func TestDuration(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		0:                               "PT0S",
+		90 * time.Minute:                "PT1H30M",
+		24 * time.Hour:                  "P1D",
+		36 * time.Hour:                  "P1DT12H",
+		7*24*time.Hour + 30*time.Second: "P7DT30S",
+	} {
+		if got := duration(d); got != want {
+			t.Errorf("duration(%s) = %s, want %s", d, got, want)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package mcp
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// bind copies decoded JSON arguments into dst, a pointer to a struct, matching fields by
+// their json names. Arguments have already been validated, so mismatches are reported
+// plainly rather than per field.
+func bind(args map[string]interface{}, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bind: destination must be a pointer to struct, got %T", dst)
+	}
+	return bindStruct(args, v.Elem())
+}
+
+func bindStruct(args map[string]interface{}, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, ok := jsonName(f)
+		if !ok {
+			continue
+		}
+		fv := v.Field(i)
+		if f.Anonymous && name == "" && fv.Kind() == reflect.Struct {
+			if err := bindStruct(args, fv); err != nil {
+				return err
+			}
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		raw, ok := args[name]
+		if !ok || raw == nil {
+			continue
+		}
+		if err := bindValue(raw, fv); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func bindValue(raw interface{}, v reflect.Value) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return bindValue(raw, v.Elem())
+	}
+	if v.Type() == reflect.TypeOf(time.Time{}) {
+		s, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("expected date-time string, got %T", raw)
+		}
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		s, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("expected string, got %T", raw)
+		}
+		v.SetString(s)
+	case reflect.Bool:
+		b, ok := raw.(bool)
+		if !ok {
+			return fmt.Errorf("expected boolean, got %T", raw)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := number(raw)
+		if !ok {
+			return fmt.Errorf("expected integer, got %T", raw)
+		}
+		v.SetInt(int64(n))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := number(raw)
+		if !ok || n < 0 {
+			return fmt.Errorf("expected non-negative integer, got %v", raw)
+		}
+		v.SetUint(uint64(n))
+	case reflect.Float32, reflect.Float64:
+		n, ok := number(raw)
+		if !ok {
+			return fmt.Errorf("expected number, got %T", raw)
+		}
+		v.SetFloat(n)
+	case reflect.Slice:
+		arr, ok := raw.([]interface{})
+		if !ok {
+			if ss, isStrings := raw.([]string); isStrings {
+				for _, s := range ss {
+					arr = append(arr, s)
+				}
+			} else {
+				return fmt.Errorf("expected array, got %T", raw)
+			}
+		}
+		out := reflect.MakeSlice(v.Type(), len(arr), len(arr))
+		for i, it := range arr {
+			if err := bindValue(it, out.Index(i)); err != nil {
+				return fmt.Errorf("[%d]: %w", i, err)
+			}
+		}
+		v.Set(out)
+	case reflect.Struct:
+		m, ok := raw.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected object, got %T", raw)
+		}
+		return bindStruct(m, v)
+	case reflect.Map:
+		m, ok := raw.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected object, got %T", raw)
+		}
+		out := reflect.MakeMapWithSize(v.Type(), len(m))
+		for k, it := range m {
+			ev := reflect.New(v.Type().Elem()).Elem()
+			if err := bindValue(it, ev); err != nil {
+				return fmt.Errorf("%s: %w", k, err)
+			}
+			out.SetMapIndex(reflect.ValueOf(k), ev)
+		}
+		v.Set(out)
+	case reflect.Interface:
+		v.Set(reflect.ValueOf(raw))
+	default:
+		return fmt.Errorf("unsupported field kind %s", v.Kind())
+	}
+	return nil
+}
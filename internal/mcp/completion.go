@@ -0,0 +1,53 @@
+package mcp
+
+// Completion reference types. ref/tool is an extension of the MCP spec that completes tool
+// arguments the same way prompt arguments are completed.
+const (
+	RefPrompt   = "ref/prompt"
+	RefResource = "ref/resource"
+	RefTool     = "ref/tool"
+)
+
+// MaxCompletionValues is the most values a completion/complete result may carry.
+const MaxCompletionValues = 100
+
+// CompleteRequest is the completion/complete request body.
+type CompleteRequest struct {
+	Ref struct {
+		Type string `json:"type"`
+		Name string `json:"name,omitempty"`
+		URI  string `json:"uri,omitempty"`
+	} `json:"ref"`
+	Argument struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"argument"`
+}
+
+// Candidate is a completion value with a human-readable description.
+type Candidate struct {
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+}
+
+// Completion is the completion field of a completion/complete result. Values are ranked
+// best first; Candidates repeats them with descriptions.
+type Completion struct {
+	Values     []string    `json:"values"`
+	Total      int         `json:"total"`
+	HasMore    bool        `json:"hasMore"`
+	Candidates []Candidate `json:"candidates,omitempty"`
+}
+
+// NewCompletion builds a Completion from ranked candidates, truncating to MaxCompletionValues.
+func NewCompletion(cands []Candidate) Completion {
+	c := Completion{Values: []string{}, Total: len(cands), HasMore: len(cands) > MaxCompletionValues}
+	if c.HasMore {
+		cands = cands[:MaxCompletionValues]
+	}
+	for _, cand := range cands {
+		c.Values = append(c.Values, cand.Value)
+	}
+	c.Candidates = cands
+	return c
+}
@@ -0,0 +1,31 @@
+package mcp
+
+import "fmt"
+
+// JSON-RPC error codes used by MCP, plus a server-defined code for upstream API failures.
+const (
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternal       = -32603
+	CodeUpstream       = -32000
+)
+
+// Error is an MCP error object. Tool functions return it to control the code and data
+// reported to the client; any other error is reported as CodeInternal.
+type Error struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// InvalidParams reports arguments that are well-formed but unusable.
+func InvalidParams(message string, data interface{}) *Error {
+	return &Error{Code: CodeInvalidParams, Message: message, Data: data}
+}
+
+// Upstream wraps a failure of an external API such as SAM.gov.
+func Upstream(format string, err error) *Error {
+	return &Error{Code: CodeUpstream, Message: fmt.Sprintf(format, err)}
+}
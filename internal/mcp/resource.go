@@ -0,0 +1,48 @@
+package mcp
+
+// CodeResourceNotFound is the MCP error code for a resources/read of an unknown URI.
+const CodeResourceNotFound = -32002
+
+// Resource is an entry in a resources/list result.
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ResourceTemplate describes a family of resources addressed by an RFC 6570 URI template.
+type ResourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ResourceContents is one item of a resources/read result.
+type ResourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text"`
+}
+
+// Notification is a JSON-RPC notification pushed to clients, e.g.
+// notifications/resources/updated.
+type Notification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// Notification methods sent by the server.
+const (
+	MethodResourceUpdated     = "notifications/resources/updated"
+	MethodResourceListChanged = "notifications/resources/list_changed"
+)
+
+// NewNotification builds a JSON-RPC 2.0 notification.
+func NewNotification(method string, params interface{}) Notification {
+	return Notification{JSONRPC: "2.0", Method: method, Params: params}
+}
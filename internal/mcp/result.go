@@ -0,0 +1,55 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// Content is an MCP content block. Only text blocks are produced by this server.
+type Content struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// CallToolResult is the MCP tools/call result: content blocks for display to a model, the
+// structured payload matching the tool's outputSchema, and whether the call failed.
+type CallToolResult struct {
+	Content           []Content   `json:"content"`
+	StructuredContent interface{} `json:"structuredContent,omitempty"`
+	IsError           bool        `json:"isError,omitempty"`
+}
+
+// Texter is implemented by tool results that provide a compact text rendering. Results
+// without one are rendered as JSON.
+type Texter interface {
+	Text() string
+}
+
+// NewResult wraps a tool's return value as a successful CallToolResult.
+func NewResult(v interface{}) *CallToolResult {
+	var text string
+	if t, ok := v.(Texter); ok {
+		text = t.Text()
+	} else {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return ErrorResult(err)
+		}
+		text = string(b)
+	}
+	return &CallToolResult{Content: []Content{{Type: "text", Text: text}}, StructuredContent: v}
+}
+
+// ErrorResult reports a failed tool execution as a result with isError set, so the model
+// can read the message and correct its call. The structured payload carries the *Error.
+func ErrorResult(err error) *CallToolResult {
+	var me *Error
+	if !errors.As(err, &me) {
+		me = &Error{Code: CodeInternal, Message: err.Error()}
+	}
+	return &CallToolResult{
+		Content:           []Content{{Type: "text", Text: me.Message}},
+		StructuredContent: map[string]interface{}{"error": me},
+		IsError:           true,
+	}
+}
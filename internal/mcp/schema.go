@@ -0,0 +1,310 @@
+package mcp
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldError describes one argument that does not satisfy a tool's input schema.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidateArgs checks args against a tool's JSON Schema (the subset this server uses:
+// type, properties, required, enum, minimum, maximum, minLength, minItems, maxItems,
+// items and additionalProperties) and returns a copy of args with declared defaults applied.
+func ValidateArgs(schema map[string]interface{}, args map[string]interface{}) (map[string]interface{}, []FieldError) {
+	out := make(map[string]interface{}, len(args))
+	for k, v := range args {
+		out[k] = v
+	}
+	var errs []FieldError
+	props, _ := schema["properties"].(map[string]interface{})
+
+	for _, name := range stringList(schema["required"]) {
+		if v, ok := out[name]; !ok || v == nil {
+			errs = append(errs, FieldError{Field: name, Message: "is required"})
+		}
+	}
+	if ap, ok := schema["additionalProperties"].(bool); ok && !ap {
+		for _, k := range sortedKeys(out) {
+			if _, known := props[k]; !known {
+				errs = append(errs, FieldError{Field: k, Message: "is not a recognized argument"})
+			}
+		}
+	}
+	for _, name := range sortedKeys(props) {
+		ps, _ := props[name].(map[string]interface{})
+		v, ok := out[name]
+		if !ok || v == nil {
+			if def, has := ps["default"]; has {
+				out[name] = def
+			}
+			continue
+		}
+		errs = append(errs, validateValue(name, ps, v)...)
+	}
+	return out, errs
+}
+
+// validateValue checks a single value against a property schema.
+func validateValue(field string, ps map[string]interface{}, v interface{}) []FieldError {
+	typ, _ := ps["type"].(string)
+	fail := func(format string, a ...interface{}) []FieldError {
+		return []FieldError{{Field: field, Message: fmt.Sprintf(format, a...)}}
+	}
+	switch typ {
+	case "string":
+		s, ok := v.(string)
+		if !ok {
+			return fail("must be a string")
+		}
+		if min, ok := number(ps["minLength"]); ok && float64(len(s)) < min {
+			return fail("must be at least %v characters", min)
+		}
+	case "integer", "number":
+		n, ok := number(v)
+		if !ok {
+			return fail("must be a %s", typ)
+		}
+		if typ == "integer" && n != math.Trunc(n) {
+			return fail("must be an integer")
+		}
+		if min, ok := number(ps["minimum"]); ok && n < min {
+			return fail("must be >= %v", min)
+		}
+		if max, ok := number(ps["maximum"]); ok && n > max {
+			return fail("must be <= %v", max)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return fail("must be a boolean")
+		}
+	case "array":
+		arr, ok := v.([]interface{})
+		if !ok {
+			return fail("must be an array")
+		}
+		if min, ok := number(ps["minItems"]); ok && float64(len(arr)) < min {
+			return fail("must contain at least %v items", min)
+		}
+		if max, ok := number(ps["maxItems"]); ok && float64(len(arr)) > max {
+			return fail("must contain at most %v items", max)
+		}
+		items, _ := ps["items"].(map[string]interface{})
+		var errs []FieldError
+		for i, it := range arr {
+			errs = append(errs, validateValue(fmt.Sprintf("%s[%d]", field, i), items, it)...)
+		}
+		return errs
+	case "object":
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return fail("must be an object")
+		}
+		_, errs := ValidateArgs(ps, m)
+		for i := range errs {
+			errs[i].Field = field + "." + errs[i].Field
+		}
+		return errs
+	}
+	if enum := ps["enum"]; enum != nil {
+		allowed := stringList(enum)
+		s, _ := v.(string)
+		for _, a := range allowed {
+			if a == s {
+				return nil
+			}
+		}
+		return fail("must be one of %s", strings.Join(allowed, ", "))
+	}
+	return nil
+}
+
+func number(v interface{}) (float64, bool) {
+	switch t := v.(type) {
+	case float64:
+		return t, true
+	case int:
+		return float64(t), true
+	}
+	return 0, false
+}
+
+// stringList accepts both []string (schemas declared in Go) and []interface{} (decoded JSON).
+func stringList(v interface{}) []string {
+	switch t := v.(type) {
+	case []string:
+		return t
+	case []interface{}:
+		out := make([]string, 0, len(t))
+		for _, x := range t {
+			if s, ok := x.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SchemaFor generates a JSON Schema for a Go type. Struct fields use their json names and
+// may carry a jsonschema tag with comma-separated keywords (required, min=N, max=N,
+// default=V, enum=a|b, minItems=N, maxItems=N) and a description tag.
+func SchemaFor(t reflect.Type) map[string]interface{} {
+	return schemaFor(t, map[reflect.Type]bool{}, false)
+}
+
+// ArgsSchemaFor is SchemaFor for tool arguments: struct objects also declare
+// additionalProperties false, so ValidateArgs rejects misspelled argument names instead of
+// silently dropping them.
+func ArgsSchemaFor(t reflect.Type) map[string]interface{} {
+	return schemaFor(t, map[reflect.Type]bool{}, true)
+}
+
+// schemaFor tracks the structs being expanded so self-referencing types terminate; closed
+// marks struct objects as allowing no other properties.
+func schemaFor(t reflect.Type, expanding map[reflect.Type]bool, closed bool) map[string]interface{} {
+	if t == nil {
+		return map[string]interface{}{}
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": schemaFor(t.Elem(), expanding, closed)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemaFor(t.Elem(), expanding, closed)}
+	case reflect.Struct:
+		if expanding[t] {
+			return map[string]interface{}{"type": "object"}
+		}
+		expanding[t] = true
+		defer delete(expanding, t)
+		props := map[string]interface{}{}
+		var required []string
+		addStructFields(t, props, &required, expanding, closed)
+		s := map[string]interface{}{"type": "object", "properties": props}
+		if len(required) > 0 {
+			s["required"] = required
+		}
+		if closed {
+			s["additionalProperties"] = false
+		}
+		return s
+	}
+	return map[string]interface{}{}
+}
+
+func addStructFields(t reflect.Type, props map[string]interface{}, required *[]string, expanding map[reflect.Type]bool, closed bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, ok := jsonName(f)
+		if !ok {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addStructFields(ft, props, required, expanding, closed)
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+		ps := schemaFor(f.Type, expanding, closed)
+		if d := f.Tag.Get("description"); d != "" {
+			ps["description"] = d
+		}
+		if applyKeywords(ps, f.Tag.Get("jsonschema")) {
+			*required = append(*required, name)
+		}
+		props[name] = ps
+	}
+}
+
+// jsonName returns the field's json name ("" for untagged fields) and false if it is skipped.
+func jsonName(f reflect.StructField) (string, bool) {
+	if !f.IsExported() && !f.Anonymous {
+		return "", false
+	}
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	return name, true
+}
+
+// applyKeywords copies jsonschema tag keywords into ps and reports whether the field is required.
+func applyKeywords(ps map[string]interface{}, tag string) bool {
+	required := false
+	for _, kw := range strings.Split(tag, ",") {
+		key, val, _ := strings.Cut(strings.TrimSpace(kw), "=")
+		switch key {
+		case "required":
+			required = true
+		case "min":
+			ps["minimum"] = parseNumber(val)
+		case "max":
+			ps["maximum"] = parseNumber(val)
+		case "minItems", "maxItems", "minLength":
+			ps[key] = parseNumber(val)
+		case "enum":
+			ps["enum"] = strings.Split(val, "|")
+		case "default":
+			ps["default"] = parseDefault(ps["type"], val)
+		}
+	}
+	return required
+}
+
+func parseNumber(s string) interface{} {
+	if i, err := strconv.Atoi(s); err == nil {
+		return i
+	}
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+func parseDefault(typ interface{}, val string) interface{} {
+	switch typ {
+	case "integer", "number":
+		return parseNumber(val)
+	case "boolean":
+		return val == "true"
+	}
+	return val
+}
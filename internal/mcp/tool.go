@@ -0,0 +1,146 @@
+// Package mcp provides the tool registration API used by the MCP server: typed tool
+// definitions with generated JSON Schemas, argument validation and dispatch.
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Tool describes an MCP tool and its input and output schemas.
+type Tool struct {
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description"`
+	InputSchema  map[string]interface{} `json:"inputSchema"`
+	OutputSchema map[string]interface{} `json:"outputSchema,omitempty"`
+}
+
+// Handler is a registered tool. Most tools are built with NewTool; implement Handler
+// directly only when a schema cannot be derived from a Go type.
+type Handler interface {
+	Info() Tool
+	// Call runs the tool with validated arguments (defaults already applied).
+	Call(ctx context.Context, args map[string]interface{}) (interface{}, error)
+}
+
+// Func is a typed tool implementation.
+type Func[A, R any] func(ctx context.Context, args A) (R, error)
+
+type typedTool[A, R any] struct {
+	info Tool
+	fn   Func[A, R]
+}
+
+// NewTool declares a tool whose input schema is generated from the args struct A, which
+// accepts no other arguments, and whose output schema is generated from the result type R.
+// See SchemaFor for the supported struct tags.
+func NewTool[A, R any](name, description string, fn Func[A, R]) Handler {
+	var a A
+	var r R
+	return &typedTool[A, R]{
+		info: Tool{
+			Name:         name,
+			Description:  description,
+			InputSchema:  ArgsSchemaFor(reflect.TypeOf(a)),
+			OutputSchema: SchemaFor(reflect.TypeOf(&r).Elem()),
+		},
+		fn: fn,
+	}
+}
+
+func (t *typedTool[A, R]) Info() Tool { return t.info }
+
+func (t *typedTool[A, R]) Call(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	var a A
+	if err := bind(args, &a); err != nil {
+		return nil, InvalidParams(err.Error(), nil)
+	}
+	return t.fn(ctx, a)
+}
+
+// Registry holds tools by name in registration order. It is safe for concurrent use.
+type Registry struct {
+	mu    sync.RWMutex
+	tools map[string]Handler
+	order []string
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry { return &Registry{tools: make(map[string]Handler)} }
+
+// Register adds tools, failing on an empty or duplicate name.
+func (r *Registry) Register(hs ...Handler) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, h := range hs {
+		name := h.Info().Name
+		if name == "" {
+			return fmt.Errorf("mcp: tool name is required")
+		}
+		if _, dup := r.tools[name]; dup {
+			return fmt.Errorf("mcp: tool %q already registered", name)
+		}
+		r.tools[name] = h
+		r.order = append(r.order, name)
+	}
+	return nil
+}
+
+// MustRegister is like Register but panics on error; it suits tools declared at startup.
+func (r *Registry) MustRegister(hs ...Handler) {
+	if err := r.Register(hs...); err != nil {
+		panic(err)
+	}
+}
+
+// List returns the definitions of all registered tools in registration order.
+func (r *Registry) List() []Tool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]Tool, 0, len(r.order))
+	for _, name := range r.order {
+		out = append(out, r.tools[name].Info())
+	}
+	return out
+}
+
+// Lookup returns the tool registered under name.
+func (r *Registry) Lookup(name string) (Handler, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	h, ok := r.tools[name]
+	return h, ok
+}
+
+// Call validates args against the tool's input schema, applies declared defaults and
+// invokes the tool. Only an unknown tool is reported as an error; invalid arguments and
+// failures inside the tool come back as a result with IsError set.
+func (r *Registry) Call(ctx context.Context, name string, args map[string]interface{}) (*CallToolResult, error) {
+	h, ok := r.Lookup(name)
+	if !ok {
+		return nil, &Error{Code: CodeMethodNotFound, Message: "unknown tool: " + name}
+	}
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+	validated, errs := ValidateArgs(h.Info().InputSchema, args)
+	if len(errs) > 0 {
+		return ErrorResult(InvalidParams("invalid arguments for "+name+": "+joinFieldErrors(errs), map[string]interface{}{"errors": errs})), nil
+	}
+	v, err := h.Call(ctx, validated)
+	if err != nil {
+		return ErrorResult(err), nil
+	}
+	return NewResult(v), nil
+}
+
+func joinFieldErrors(errs []FieldError) string {
+	parts := make([]string, len(errs))
+	for i, e := range errs {
+		parts[i] = e.Field + " " + e.Message
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,101 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type testArgs struct {
+	Name  string   `json:"name" jsonschema:"required"`
+	Limit int      `json:"limit" jsonschema:"min=1,max=10,default=5"`
+	Kind  string   `json:"kind" jsonschema:"enum=A|B"`
+	Tags  []string `json:"tags" description:"Free-form tags"`
+}
+
+type testResult struct {
+	Name  string      `json:"name"`
+	Limit int         `json:"limit"`
+	At    time.Time   `json:"at"`
+	Next  *testResult `json:"next,omitempty"`
+	skip  bool
+}
+
+func TestNewToolSchemas(t *testing.T) {
+	tool := NewTool("t", "test", func(_ context.Context, a testArgs) (*testResult, error) { return nil, nil }).Info()
+	props := tool.InputSchema["properties"].(map[string]interface{})
+	limit := props["limit"].(map[string]interface{})
+	if limit["type"] != "integer" || limit["maximum"] != 10 || limit["default"] != 5 {
+		t.Fatalf("unexpected limit schema: %+v", limit)
+	}
+	if req := tool.InputSchema["required"].([]string); len(req) != 1 || req[0] != "name" {
+		t.Fatalf("unexpected required: %+v", req)
+	}
+	if props["tags"].(map[string]interface{})["description"] != "Free-form tags" {
+		t.Fatalf("description not applied: %+v", props["tags"])
+	}
+	if tool.InputSchema["additionalProperties"] != false {
+		t.Fatalf("input schema allows unknown arguments: %+v", tool.InputSchema)
+	}
+	if _, ok := tool.OutputSchema["additionalProperties"]; ok {
+		t.Fatalf("output schema should stay open: %+v", tool.OutputSchema)
+	}
+	out := tool.OutputSchema["properties"].(map[string]interface{})
+	if out["at"].(map[string]interface{})["format"] != "date-time" || out["next"].(map[string]interface{})["type"] != "object" {
+		t.Fatalf("unexpected output schema: %+v", out)
+	}
+	if _, ok := out["skip"]; ok {
+		t.Fatal("unexported field in schema")
+	}
+}
+
+func TestRegistryCall(t *testing.T) {
+	r := NewRegistry()
+	var got testArgs
+	r.MustRegister(NewTool("t", "test", func(_ context.Context, a testArgs) (testArgs, error) {
+		got = a
+		return a, nil
+	}))
+	if err := r.Register(NewTool("t", "dup", func(_ context.Context, a testArgs) (int, error) { return 0, nil })); err == nil {
+		t.Fatal("expected duplicate registration error")
+	}
+
+	if _, err := r.Call(context.Background(), "t", map[string]interface{}{"name": "x", "tags": []interface{}{"a", "b"}, "kind": "B"}); err != nil {
+		t.Fatalf("call: %v", err)
+	}
+	if got.Name != "x" || got.Limit != 5 || got.Kind != "B" || len(got.Tags) != 2 {
+		t.Fatalf("unexpected bound args: %+v", got)
+	}
+
+	res, err := r.Call(context.Background(), "t", map[string]interface{}{"limit": 11, "kind": "C"})
+	if err != nil || !res.IsError {
+		t.Fatalf("expected error result, got %+v, %v", res, err)
+	}
+	me := res.StructuredContent.(map[string]interface{})["error"].(*Error)
+	if me.Code != CodeInvalidParams {
+		t.Fatalf("expected invalid params, got %+v", me)
+	}
+	if errs := me.Data.(map[string]interface{})["errors"].([]FieldError); len(errs) != 3 {
+		t.Fatalf("expected 3 field errors, got %+v", errs)
+	}
+
+	// A misspelled argument is rejected rather than dropped.
+	res, _ = r.Call(context.Background(), "t", map[string]interface{}{"name": "x", "limt": 3})
+	if !res.IsError {
+		t.Fatalf("expected error for misspelled argument, got %+v", res)
+	}
+	me = res.StructuredContent.(map[string]interface{})["error"].(*Error)
+	if errs := me.Data.(map[string]interface{})["errors"].([]FieldError); me.Code != CodeInvalidParams || len(errs) != 1 || errs[0].Field != "limt" {
+		t.Fatalf("unexpected error for misspelled argument: %+v", me)
+	}
+
+	res, _ = r.Call(context.Background(), "t", map[string]interface{}{"name": "y"})
+	if res.IsError || res.Content[0].Type != "text" || res.Content[0].Text == "" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+
+	if _, err := r.Call(context.Background(), "missing", nil); !errors.As(err, &me) || me.Code != CodeMethodNotFound {
+		t.Fatalf("expected method not found, got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"sam-mcp/internal/sam"
+)
+
+func chatEvent(n int, now time.Time) Event {
+	due := now.Add(5*24*time.Hour + time.Hour)
+	notices := make([]Notice, n)
+	for i := range notices {
+		notices[i] = Notice{Change: ChangeAdded, Opportunity: sam.Opportunity{
+			NoticeID: fmt.Sprintf("N%d", i), Title: fmt.Sprintf("Guard services <%d> & more", i), Agency: "GSA",
+			NAICS: "561612", NAICSTitle: "Security Guards and Patrol Services", URL: "https://sam.gov/opp/" + fmt.Sprint(i), ResponseDeadline: &due,
+		}}
+	}
+	notices[0].Change, notices[0].Fields = ChangeAmended, []string{"responseDeadline"}
+	return NewEvent(Source{Kind: SourceSavedSearch, ID: "abc", Name: "Facilities"}, now, notices)
+}
+
+func TestSlackMessages(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	parts := (&Slack{}).Split(chatEvent(45, now))
+	if len(parts) != 3 || len(parts[0].Notices) != slackMaxNotices || len(parts[2].Notices) != 5 || parts[2].Part != 3 || parts[2].Parts != 3 {
+		t.Fatalf("unexpected split: %d parts", len(parts))
+	}
+
+	raw, err := renderSlack(parts[0], now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg struct {
+		Text   string `json:"text"`
+		Blocks []struct {
+			Type string `json:"type"`
+			Text struct {
+				Text string `json:"text"`
+			} `json:"text"`
+		} `json:"blocks"`
+	}
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		t.Fatal(err)
+	}
+	if len(msg.Blocks) != slackMaxNotices+2 || msg.Blocks[0].Text.Text != "Facilities: 19 new, 1 amended SAM.gov opportunities (part 1/3)" {
+		t.Fatalf("unexpected message: %s", raw)
+	}
+	first := msg.Blocks[1].Text.Text
+	for _, want := range []string{"*<https://sam.gov/opp/0|Guard services &lt;0&gt; &amp; more>*", "_Amended: responseDeadline_", "*NAICS:* 561612 Security Guards", "(in 5 days)"} {
+		if !strings.Contains(first, want) {
+			t.Errorf("section %q lacks %q", first, want)
+		}
+	}
+}
+
+func TestTeamsMessages(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	if parts := (&Teams{}).Split(chatEvent(30, now)); len(parts) != 2 || len(parts[0].Notices)+len(parts[1].Notices) != 30 {
+		t.Fatalf("unexpected split: %d parts", len(parts))
+	}
+
+	var got []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+	ev := chatEvent(2, time.Now())
+	if err := (&Teams{Client: srv.Client()}).Send(context.Background(), Target{Type: "teams", Address: srv.URL}, Delivery{ID: "d1", Event: ev}); err != nil {
+		t.Fatal(err)
+	}
+	var msg struct {
+		Attachments []struct {
+			ContentType string `json:"contentType"`
+			Content     struct {
+				Type string                   `json:"type"`
+				Body []map[string]interface{} `json:"body"`
+			} `json:"content"`
+		} `json:"attachments"`
+	}
+	if err := json.Unmarshal(got, &msg); err != nil || len(msg.Attachments) != 1 {
+		t.Fatalf("unexpected payload (%v): %s", err, got)
+	}
+	card := msg.Attachments[0].Content
+	if msg.Attachments[0].ContentType != "application/vnd.microsoft.card.adaptive" || card.Type != "AdaptiveCard" || len(card.Body) != 4 {
+		t.Fatalf("unexpected card: %s", got)
+	}
+	if !strings.Contains(string(got), `"text":"[Guard services <1> & more](https://sam.gov/opp/1)"`) || !strings.Contains(string(got), "(in 5 days)") {
+		t.Fatalf("notice not rendered: %s", got)
+	}
+}
+
+func TestQueueSplitsForChatTargets(t *testing.T) {
+	q, _ := Open("")
+	q.Register("slack", &Slack{})
+	ds, err := q.Enqueue(Target{Type: "slack", Address: "https://hooks.slack.com/services/x"}, chatEvent(25, time.Now()))
+	if err != nil || len(ds) != 2 || ds[0].Event.ID != ds[1].Event.ID || ds[1].Event.Part != 2 {
+		t.Fatalf("expected two parts of one event: %+v %v", ds, err)
+	}
+}
+
+func TestDeadlineText(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time { t := now.Add(d); return &t }
+	for _, tc := range []struct {
+		deadline *time.Time
+		want     string
+	}{
+		{nil, ""},
+		{at(-time.Hour), "Mar 1, 2026 11:00 UTC (closed)"},
+		{at(30 * time.Minute), "Mar 1, 2026 12:30 UTC (in under an hour)"},
+		{at(5 * time.Hour), "Mar 1, 2026 17:00 UTC (in 5 hours)"},
+		{at(72 * time.Hour), "Mar 4, 2026 12:00 UTC (in 3 days)"},
+	} {
+		if got := deadlineText(tc.deadline, now); got != tc.want {
+			t.Errorf("deadlineText = %q, want %q", got, tc.want)
+		}
+	}
+}
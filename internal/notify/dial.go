@@ -0,0 +1,55 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress is returned for a target that resolves to an address notifications
+// may not reach.
+var ErrForbiddenAddress = errors.New("address not allowed for notifications")
+
+// NewHTTPClient returns the client webhook, Slack and Teams deliveries are sent with. Since
+// notify targets come from users, it refuses to connect to loopback, private, link-local
+// and other non-public addresses, checked after name resolution so that a public name
+// cannot point inside the server's network. allowedHosts lists host names, such as an
+// internal receiver, that are exempt.
+func NewHTTPClient(timeout time.Duration, allowedHosts []string) *http.Client {
+	allowed := make(map[string]bool, len(allowedHosts))
+	for _, h := range allowedHosts {
+		allowed[strings.ToLower(strings.TrimSpace(h))] = true
+	}
+	open := &net.Dialer{Timeout: timeout}
+	guarded := &net.Dialer{Timeout: timeout, Control: publicOnly}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err == nil && allowed[strings.ToLower(host)] {
+			return open.DialContext(ctx, network, addr)
+		}
+		return guarded.DialContext(ctx, network, addr)
+	}
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// publicOnly is a net.Dialer Control function refusing non-public addresses.
+func publicOnly(_, address string, _ syscall.RawConn) error {
+	ap, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, address)
+	}
+	if ip := ap.Addr().Unmap(); !ip.IsGlobalUnicast() || ip.IsPrivate() || sharedAddressSpace.Contains(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, ip)
+	}
+	return nil
+}
+
+// sharedAddressSpace is carrier-grade NAT space (RFC 6598), which IsPrivate leaves out.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
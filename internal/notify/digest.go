@@ -0,0 +1,131 @@
+package notify
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"sam-mcp/internal/jsonfile"
+)
+
+// Digest collects events for email recipients between digest runs, writing them to a
+// JSON file after every change.
+type Digest struct {
+	mu      sync.Mutex
+	path    string
+	pending map[string][]Event
+}
+
+// OpenDigest loads the digest inbox at path, which need not exist yet. An empty path
+// keeps it in memory only.
+func OpenDigest(path string) (*Digest, error) {
+	d := &Digest{path: path, pending: make(map[string][]Event)}
+	if path == "" {
+		return d, nil
+	}
+	if _, err := jsonfile.Load(path, &d.pending); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Add holds ev for the recipient's next digest.
+func (d *Digest) Add(recipient string, ev Event) error {
+	recipient = strings.ToLower(strings.TrimSpace(recipient))
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.pending[recipient] = append(d.pending[recipient], ev)
+	return d.save()
+}
+
+// Drain returns and forgets the held events, by recipient.
+func (d *Digest) Drain() (map[string][]Event, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	out := d.pending
+	d.pending = make(map[string][]Event)
+	if err := d.save(); err != nil {
+		d.pending = out
+		return nil, err
+	}
+	return out, nil
+}
+
+// save writes the inbox. Callers hold d.mu.
+func (d *Digest) save() error {
+	if d.path == "" {
+		return nil
+	}
+	return jsonfile.Save(d.path, d.pending)
+}
+
+// Compose merges a recipient's events into one digest event. Each notice carries its
+// source; a notice reported by several runs of a source appears once, with its latest
+// state, as added if any run added it and with the union of amended fields otherwise.
+// Notices are ordered by source and then by response deadline, soonest first.
+func Compose(recipient string, events []Event, at time.Time) Event {
+	type key struct{ kind, id, notice string }
+	byKey := make(map[key]*Notice)
+	var order []key
+	samEnv := ""
+	for _, ev := range events {
+		if ev.SamEnv != "" {
+			samEnv = ev.SamEnv
+		}
+		src := ev.Source
+		for _, n := range ev.Notices {
+			k := key{src.Kind, src.ID, n.Opportunity.NoticeID}
+			prev, ok := byKey[k]
+			if !ok {
+				n := n
+				n.Source = &src
+				byKey[k] = &n
+				order = append(order, k)
+				continue
+			}
+			change, fields := prev.Change, prev.Fields
+			if change != ChangeAdded {
+				change, fields = n.Change, mergeFields(fields, n.Fields)
+			}
+			*prev = n
+			prev.Change, prev.Source = change, &src
+			if change == ChangeAdded {
+				prev.Fields = nil
+			} else {
+				prev.Fields = fields
+			}
+		}
+	}
+	notices := make([]Notice, 0, len(order))
+	for _, k := range order {
+		notices = append(notices, *byKey[k])
+	}
+	sort.SliceStable(notices, func(i, j int) bool {
+		a, b := notices[i], notices[j]
+		if a.Source.Kind != b.Source.Kind || a.Source.ID != b.Source.ID {
+			return a.Source.Kind+"/"+a.Source.ID < b.Source.Kind+"/"+b.Source.ID
+		}
+		da, db := a.Opportunity.ResponseDeadline, b.Opportunity.ResponseDeadline
+		switch {
+		case da == nil || db == nil:
+			return da != nil && db == nil
+		default:
+			return da.Before(*db)
+		}
+	})
+	return Event{ID: newID(), Type: EventDigest, Source: Source{Kind: SourceDigest, ID: recipient}, Detected: at.UTC(), SamEnv: samEnv, Notices: notices}
+}
+
+func mergeFields(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var out []string
+	for _, f := range append(append([]string(nil), a...), b...) {
+		if !seen[f] {
+			seen[f] = true
+			out = append(out, f)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,204 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTP connection security modes.
+const (
+	// SecuritySTARTTLS upgrades a plain connection with STARTTLS and fails when the server
+	// does not offer it.
+	SecuritySTARTTLS = "starttls"
+	// SecurityTLS connects with implicit TLS, usually on port 465.
+	SecurityTLS = "tls"
+	// SecurityNone sends in the clear; net/smtp refuses to authenticate over it except to
+	// localhost.
+	SecurityNone = "none"
+)
+
+// smtpTimeout bounds a whole SMTP session when the context has no deadline.
+const smtpTimeout = time.Minute
+
+// SMTPConfig describes the mail server digests are sent through.
+type SMTPConfig struct {
+	Host string
+	Port int
+	// Username and Password authenticate with AUTH PLAIN when Username is set.
+	Username string
+	Password string
+	From     string
+	// Security is SecuritySTARTTLS (the default), SecurityTLS or SecurityNone.
+	Security string
+}
+
+// Validate checks the settings needed to send mail.
+func (c SMTPConfig) Validate() error {
+	if c.Host == "" {
+		return errors.New("SMTP host is required")
+	}
+	if c.Port <= 0 || c.Port > 65535 {
+		return fmt.Errorf("invalid SMTP port %d", c.Port)
+	}
+	if _, err := mail.ParseAddress(c.From); err != nil {
+		return fmt.Errorf("invalid SMTP from address %q: %w", c.From, err)
+	}
+	switch c.Security {
+	case "", SecuritySTARTTLS, SecurityTLS, SecurityNone:
+		return nil
+	default:
+		return fmt.Errorf("invalid SMTP security %q (want %s, %s or %s)", c.Security, SecuritySTARTTLS, SecurityTLS, SecurityNone)
+	}
+}
+
+// Email sends digest events as HTML and plain-text email, one message per recipient.
+type Email struct {
+	SMTP      SMTPConfig
+	Templates *Templates
+}
+
+// Send delivers d to the target's address. SMTP 5xx replies are permanent.
+func (e *Email) Send(ctx context.Context, t Target, d Delivery) error {
+	to, err := mail.ParseAddress(t.Address)
+	if err != nil {
+		return Permanent(err)
+	}
+	subject, text, html, err := e.Templates.Render(d.Event, time.Now())
+	if err != nil {
+		return Permanent(err)
+	}
+	msg, err := e.message(to.Address, d.ID, subject, text, html)
+	if err != nil {
+		return Permanent(err)
+	}
+	err = e.send(ctx, to.Address, msg)
+	var reply *textproto.Error
+	if errors.As(err, &reply) && reply.Code >= 500 {
+		return Permanent(err)
+	}
+	return err
+}
+
+// message builds a multipart/alternative message with quoted-printable text and HTML
+// parts.
+func (e *Email) message(to, id, subject, text, html string) ([]byte, error) {
+	from, err := mail.ParseAddress(e.SMTP.From)
+	if err != nil {
+		return nil, err
+	}
+	domain := from.Address[strings.LastIndex(from.Address, "@")+1:]
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, part := range []struct{ typ, content string }{{"text/plain", text}, {"text/html", html}} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.typ + "; charset=utf-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	for _, h := range [][2]string{
+		{"From", from.String()},
+		{"To", to},
+		{"Subject", mime.QEncoding.Encode("utf-8", subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", "<" + id + "@" + domain + ">"},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "multipart/alternative; boundary=" + strconv.Quote(mw.Boundary())},
+	} {
+		msg.WriteString(h[0] + ": " + h[1] + "\r\n")
+	}
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+// send runs one SMTP session delivering msg to a single recipient.
+func (e *Email) send(ctx context.Context, to string, msg []byte) error {
+	cfg := e.SMTP
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	dialer := &net.Dialer{}
+	var conn net.Conn
+	var err error
+	if cfg.Security == SecurityTLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: cfg.Host}}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(smtpTimeout)
+	}
+	_ = conn.SetDeadline(deadline)
+
+	c, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if cfg.Security == "" || cfg.Security == SecuritySTARTTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return Permanent(fmt.Errorf("SMTP server %s does not offer STARTTLS", addr))
+		}
+		if err := c.StartTLS(&tls.Config{ServerName: cfg.Host}); err != nil {
+			return err
+		}
+	}
+	if cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)); err != nil {
+			return err
+		}
+	}
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return Permanent(err)
+	}
+	if err := c.Mail(from.Address); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
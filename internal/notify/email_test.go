@@ -0,0 +1,236 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"sam-mcp/internal/sam"
+)
+
+// smtpSink is a minimal SMTP server on localhost that records one message per session.
+type smtpSink struct {
+	addr     string
+	auth     chan string
+	messages chan sinkMessage
+}
+
+type sinkMessage struct {
+	from, to string
+	data     string
+}
+
+func newSMTPSink(t *testing.T) *smtpSink {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	s := &smtpSink{addr: ln.Addr().String(), auth: make(chan string, 10), messages: make(chan sinkMessage, 10)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpSink) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(lines ...string) { io.WriteString(conn, strings.Join(lines, "\r\n")+"\r\n") }
+	reply("220 sink ESMTP")
+	var msg sinkMessage
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch {
+		case verb == "EHLO":
+			reply("250-sink", "250 AUTH PLAIN")
+		case verb == "AUTH":
+			raw, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(line, "AUTH PLAIN "))
+			s.auth <- string(raw)
+			reply("235 2.7.0 accepted")
+		case strings.HasPrefix(strings.ToUpper(line), "MAIL FROM:"):
+			msg.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+			reply("250 ok")
+		case strings.HasPrefix(strings.ToUpper(line), "RCPT TO:"):
+			msg.to = strings.Trim(line[len("RCPT TO:"):], "<>")
+			if strings.HasPrefix(msg.to, "bounce@") {
+				reply("550 5.1.1 no such user")
+				continue
+			}
+			reply("250 ok")
+		case verb == "DATA":
+			reply("354 go ahead")
+			var b strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				b.WriteString(strings.TrimPrefix(l, "."))
+			}
+			msg.data = b.String()
+			s.messages <- msg
+			reply("250 queued")
+		case verb == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+func digestEvent(now time.Time) Event {
+	soon, later := now.Add(3*24*time.Hour), now.Add(30*24*time.Hour)
+	facilities := Source{Kind: SourceSavedSearch, ID: "s1", Name: "Facilities"}
+	cyber := Source{Kind: SourceProfile, ID: "cyber"}
+	runs := []Event{
+		NewEvent(facilities, now.Add(-2*time.Hour), []Notice{
+			{Change: ChangeAdded, Opportunity: sam.Opportunity{NoticeID: "A", Title: "Janitorial services", Agency: "GSA", ResponseDeadline: &later, URL: "https://sam.gov/opp/A/view"}},
+			{Change: ChangeAmended, Fields: []string{"title"}, Opportunity: sam.Opportunity{NoticeID: "B", Title: "Roof repair", ResponseDeadline: &soon}},
+		}),
+		NewEvent(cyber, now.Add(-time.Hour), []Notice{
+			{Change: ChangeAdded, Opportunity: sam.Opportunity{NoticeID: "C", Title: "SOC <support> & monitoring"}},
+		}),
+		NewEvent(facilities, now, []Notice{
+			{Change: ChangeAmended, Fields: []string{"responseDeadline"}, Opportunity: sam.Opportunity{NoticeID: "A", Title: "Janitorial services", Agency: "GSA", ResponseDeadline: &later, URL: "https://sam.gov/opp/A/view"}},
+			{Change: ChangeAmended, Fields: []string{"responseDeadline"}, Opportunity: sam.Opportunity{NoticeID: "B", Title: "Roof repair", ResponseDeadline: &soon}},
+		}),
+	}
+	return Compose("lead@example.com", runs, now)
+}
+
+func TestCompose(t *testing.T) {
+	ev := digestEvent(time.Now())
+	if ev.Type != EventDigest || ev.Source.ID != "lead@example.com" || len(ev.Notices) != 3 {
+		t.Fatalf("unexpected digest: %+v", ev)
+	}
+	// Profiles sort before saved searches; within a source the soonest deadline leads.
+	var got []string
+	for _, n := range ev.Notices {
+		got = append(got, n.Source.ID+"/"+n.Opportunity.NoticeID+"/"+n.Change+"/"+strings.Join(n.Fields, ","))
+	}
+	want := "cyber/C/added/ s1/B/amended/responseDeadline,title s1/A/added/"
+	if strings.Join(got, " ") != want {
+		t.Fatalf("got %s, want %s", strings.Join(got, " "), want)
+	}
+}
+
+func TestEmailDigest(t *testing.T) {
+	sink := newSMTPSink(t)
+	host, port, _ := net.SplitHostPort(sink.addr)
+	tmpl, err := LoadTemplates("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	portNum, _ := strconv.Atoi(port)
+	e := &Email{SMTP: SMTPConfig{Host: host, Port: portNum, Username: "digest", Password: "s3cret", From: "SAM alerts <alerts@example.com>", Security: SecurityNone}, Templates: tmpl}
+	if err := e.SMTP.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	ev := digestEvent(time.Now())
+	if err := e.Send(context.Background(), Target{Type: "email", Address: "lead@example.com"}, Delivery{ID: "d1", Event: ev}); err != nil {
+		t.Fatal(err)
+	}
+	if auth := <-sink.auth; auth != "\x00digest\x00s3cret" {
+		t.Fatalf("unexpected AUTH PLAIN credentials %q", auth)
+	}
+	got := <-sink.messages
+	if got.from != "alerts@example.com" || got.to != "lead@example.com" {
+		t.Fatalf("unexpected envelope %s -> %s", got.from, got.to)
+	}
+
+	m, err := mail.ReadMessage(strings.NewReader(got.data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subj := m.Header.Get("Subject"); subj != "SAM.gov digest: 2 new, 1 amended opportunities (1 due within 7 days)" {
+		t.Fatalf("unexpected subject %q", subj)
+	}
+	_, params, err := mime.ParseMediaType(m.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := map[string]string{}
+	mr := multipart.NewReader(m.Body, params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(p)
+		typ, _, _ := mime.ParseMediaType(p.Header.Get("Content-Type"))
+		parts[typ] = strings.ReplaceAll(string(body), "\r\n", "\n")
+	}
+	text, html := parts["text/plain"], parts["text/html"]
+	for _, want := range []string{"== cyber ==", "== Facilities ==", "[DUE SOON] Roof repair\n  Amended: responseDeadline, title", "Agency: GSA", "https://sam.gov/opp/A/view"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text part lacks %q:\n%s", want, text)
+		}
+	}
+	if strings.Index(text, "Roof repair") > strings.Index(text, "Janitorial services") {
+		t.Errorf("notices not ordered by deadline:\n%s", text)
+	}
+	for _, want := range []string{`<a href="https://sam.gov/opp/A/view">Janitorial services</a>`, "SOC &lt;support&gt; &amp; monitoring", "<strong style=\"color: #b50909;\">"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("html part lacks %q:\n%s", want, html)
+		}
+	}
+
+	// A rejected recipient fails permanently.
+	err = e.Send(context.Background(), Target{Type: "email", Address: "bounce@example.com"}, Delivery{ID: "d2", Event: ev})
+	if !IsPermanent(err) {
+		t.Fatalf("expected a permanent error, got %v", err)
+	}
+}
+
+func TestDigestTemplateOverride(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, SubjectTemplate), []byte("{{.Total}} for {{.Recipient}}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := LoadTemplates(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject, text, _, err := tmpl.Render(digestEvent(time.Now()), time.Now())
+	if err != nil || subject != "3 for lead@example.com" || !strings.Contains(text, "== Facilities ==") {
+		t.Fatalf("override not applied: %q %v", subject, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, HTMLTemplate), []byte("{{.Bogus"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadTemplates(dir); err == nil {
+		t.Fatal("expected a parse error")
+	}
+}
@@ -0,0 +1,163 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"sam-mcp/internal/sam"
+)
+
+// Splitter is implemented by senders whose targets limit message size. Enqueue queues
+// one delivery per part, so a failed part is retried without resending the others.
+type Splitter interface {
+	Split(ev Event) []Event
+}
+
+// split packs an event's notices into parts of at most maxNotices whose rendering stays
+// within maxBytes, numbering the parts when there is more than one. A notice too large on
+// its own still gets a part.
+func split(ev Event, maxNotices, maxBytes int, render func(Event, time.Time) ([]byte, error)) []Event {
+	size := func(e Event) int {
+		raw, err := render(e, e.Detected)
+		if err != nil {
+			return 0
+		}
+		return len(raw)
+	}
+	var parts []Event
+	cur := ev
+	cur.Notices = nil
+	for _, n := range ev.Notices {
+		next := cur
+		next.Notices = append(append([]Notice(nil), cur.Notices...), n)
+		if len(cur.Notices) > 0 && (len(next.Notices) > maxNotices || size(next) > maxBytes) {
+			parts = append(parts, cur)
+			cur.Notices = []Notice{n}
+			continue
+		}
+		cur = next
+	}
+	parts = append(parts, cur)
+	if len(parts) > 1 {
+		for i := range parts {
+			parts[i].Part, parts[i].Parts = i+1, len(parts)
+		}
+	}
+	return parts
+}
+
+// headline summarizes an event, e.g. "Cyber portfolio: 2 new, 1 amended (part 1/2)".
+func headline(ev Event) string {
+	added, amended := 0, 0
+	for _, n := range ev.Notices {
+		if n.Change == ChangeAmended {
+			amended++
+		} else {
+			added++
+		}
+	}
+	var counts []string
+	if added > 0 {
+		counts = append(counts, fmt.Sprintf("%d new", added))
+	}
+	if amended > 0 {
+		counts = append(counts, fmt.Sprintf("%d amended", amended))
+	}
+	name := ev.Source.Name
+	if name == "" {
+		name = ev.Source.ID
+	}
+	noun := "opportunities"
+	if len(ev.Notices) == 1 {
+		noun = "opportunity"
+	}
+	out := name + ": " + strings.Join(counts, ", ") + " SAM.gov " + noun
+	if ev.Parts > 1 {
+		out += fmt.Sprintf(" (part %d/%d)", ev.Part, ev.Parts)
+	}
+	return out
+}
+
+// sourceText names the event's source, e.g. "saved search 1a2b3c".
+func sourceText(ev Event) string {
+	kind := "prefetch profile"
+	if ev.Source.Kind == SourceSavedSearch {
+		kind = "saved search"
+	}
+	out := kind + " " + ev.Source.ID
+	if ev.SamEnv != "" {
+		out += " · SAM.gov " + ev.SamEnv
+	}
+	return out
+}
+
+// deadlineText renders a response deadline with a countdown from now, e.g.
+// "Apr 1, 2026 18:00 UTC (in 5 days)".
+func deadlineText(deadline *time.Time, now time.Time) string {
+	if deadline == nil || deadline.IsZero() {
+		return ""
+	}
+	when := deadline.UTC().Format("Jan 2, 2006 15:04 MST")
+	left := deadline.Sub(now)
+	switch {
+	case left < 0:
+		return when + " (closed)"
+	case left < time.Hour:
+		return when + " (in under an hour)"
+	case left < 48*time.Hour:
+		return when + fmt.Sprintf(" (in %s)", plural(int(left.Hours()), "hour", "hours"))
+	default:
+		return when + fmt.Sprintf(" (in %s)", plural(int(math.Floor(left.Hours()/24)), "day", "days"))
+	}
+}
+
+// naicsText renders an opportunity's NAICS code with its title when known.
+func naicsText(o sam.Opportunity) string {
+	if o.NAICSTitle == "" {
+		return o.NAICS
+	}
+	return o.NAICS + " " + o.NAICSTitle
+}
+
+// changeText describes an amended notice, e.g. "Amended: modified, title".
+func changeText(n Notice) string {
+	if n.Change != ChangeAmended {
+		return ""
+	}
+	if len(n.Fields) == 0 {
+		return "Amended"
+	}
+	return "Amended: " + strings.Join(n.Fields, ", ")
+}
+
+// truncate shortens s to at most n runes, marking the cut with an ellipsis.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// encodeJSON marshals a chat payload without escaping <, > and &, which chat services
+// show literally.
+func encodeJSON(v interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n")), nil
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return "1 " + one
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
@@ -0,0 +1,99 @@
+// Package notify delivers notifications about new and changed opportunities to external
+// targets through a persistent queue that retries failed deliveries with backoff and
+// dead-letters those that keep failing.
+package notify
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"sam-mcp/internal/sam"
+)
+
+// Event types.
+const (
+	// EventNotices lists the new and changed opportunities found by one run of a source.
+	EventNotices = "notices"
+	// EventDigest gathers the notices of several runs, and sources, for an email digest.
+	EventDigest = "digest"
+)
+
+// Source kinds.
+const (
+	SourceProfile     = "profile"
+	SourceSavedSearch = "saved_search"
+	// SourceDigest is the source of a digest event; its ID is the recipient.
+	SourceDigest = "digest"
+)
+
+// Change kinds of a notice, as in internal/snapshot.
+const (
+	ChangeAdded   = "added"
+	ChangeAmended = "amended"
+)
+
+// Target is where deliveries are sent. Address is a URL for webhook, Slack and Teams
+// targets and a mailbox for email.
+type Target struct {
+	Type    string `json:"type"`
+	Address string `json:"address"`
+}
+
+// Source identifies the prefetch profile or saved search that produced an event.
+type Source struct {
+	Kind string `json:"kind"`
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// Notice is one new or changed opportunity.
+type Notice struct {
+	Change string `json:"change"`
+	// Fields lists the changed opportunity fields (by JSON name) of an amended notice.
+	Fields      []string        `json:"fields,omitempty"`
+	Opportunity sam.Opportunity `json:"opportunity"`
+	// Source is set in digest events, which mix notices from several sources.
+	Source *Source `json:"source,omitempty"`
+}
+
+// Event is the payload of a delivery.
+type Event struct {
+	ID       string    `json:"id"`
+	Type     string    `json:"type"`
+	Source   Source    `json:"source"`
+	Detected time.Time `json:"detected"`
+	SamEnv   string    `json:"samEnv,omitempty"`
+	Notices  []Notice  `json:"notices"`
+	// Part and Parts number the pieces of an event split to fit a target's message size
+	// limit; both are zero for an event sent whole.
+	Part  int `json:"part,omitempty"`
+	Parts int `json:"parts,omitempty"`
+}
+
+// NewEvent returns a notices event with a fresh id.
+func NewEvent(src Source, detected time.Time, notices []Notice) Event {
+	return Event{ID: newID(), Type: EventNotices, Source: src, Detected: detected.UTC(), Notices: notices}
+}
+
+// Sender delivers an event to one kind of target.
+type Sender interface {
+	Send(ctx context.Context, t Target, d Delivery) error
+}
+
+// permanentError marks a failure that retrying cannot fix.
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so that the queue dead-letters the delivery instead of retrying it.
+func Permanent(err error) error {
+	return permanentError{err}
+}
+
+// IsPermanent reports whether err was wrapped by Permanent.
+func IsPermanent(err error) bool {
+	var p permanentError
+	return errors.As(err, &p)
+}
@@ -0,0 +1,351 @@
+package notify
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"sam-mcp/internal/jsonfile"
+)
+
+// Delivery statuses.
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	// StatusDead marks a delivery that failed permanently or ran out of attempts.
+	StatusDead = "dead"
+)
+
+// Retry and retention defaults.
+const (
+	DefaultMaxAttempts = 8
+	// BaseBackoff is the delay before the first retry; it doubles with each attempt up
+	// to MaxBackoff.
+	BaseBackoff = time.Minute
+	MaxBackoff  = 6 * time.Hour
+	// KeepFinished bounds the delivered and dead deliveries kept for the history.
+	KeepFinished = 1000
+	// KeepFor drops finished deliveries older than this.
+	KeepFor = 30 * 24 * time.Hour
+)
+
+var (
+	// ErrNoSender is returned by Enqueue for a target type without a registered Sender.
+	ErrNoSender = errors.New("no sender for target type")
+	// ErrNotFound is returned for an unknown delivery id.
+	ErrNotFound = errors.New("delivery not found")
+)
+
+// Attempt records one try of a delivery.
+type Attempt struct {
+	At    time.Time `json:"at"`
+	Error string    `json:"error,omitempty"`
+}
+
+// Delivery is one event on its way to one target.
+type Delivery struct {
+	ID      string    `json:"id"`
+	Target  Target    `json:"target"`
+	Event   Event     `json:"event"`
+	Status  string    `json:"status"`
+	Created time.Time `json:"created"`
+	// NextAttempt is when a pending delivery is tried next.
+	NextAttempt *time.Time `json:"nextAttempt,omitempty"`
+	// Tries counts the attempts since the delivery was queued or last retried by hand.
+	Tries    int       `json:"tries"`
+	Attempts []Attempt `json:"attempts"`
+}
+
+// Queue holds deliveries in memory, writing them to a JSON file after every change.
+type Queue struct {
+	mu         sync.Mutex
+	path       string
+	deliveries []*Delivery // oldest first
+	senders    map[string]Sender
+	// processing serializes Process so that a delivery is never sent twice at once.
+	processing  sync.Mutex
+	wake        chan struct{}
+	MaxAttempts int
+}
+
+// Open loads the queue at path, which need not exist yet. An empty path keeps deliveries
+// in memory only.
+func Open(path string) (*Queue, error) {
+	q := &Queue{path: path, senders: make(map[string]Sender), wake: make(chan struct{}, 1), MaxAttempts: DefaultMaxAttempts}
+	if path == "" {
+		return q, nil
+	}
+	if _, err := jsonfile.Load(path, &q.deliveries); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// Register sets the sender for a target type.
+func (q *Queue) Register(targetType string, s Sender) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.senders[targetType] = s
+}
+
+// Handles reports whether a sender is registered for the target type.
+func (q *Queue) Handles(targetType string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.senders[targetType] != nil
+}
+
+// Enqueue stores a delivery of ev to t, due immediately, and wakes Run. Senders that
+// implement Splitter get one delivery per part of the event.
+func (q *Queue) Enqueue(t Target, ev Event) ([]Delivery, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	sender := q.senders[t.Type]
+	if sender == nil {
+		return nil, fmt.Errorf("%w %q", ErrNoSender, t.Type)
+	}
+	parts := []Event{ev}
+	if sp, ok := sender.(Splitter); ok {
+		parts = sp.Split(ev)
+	}
+	now := time.Now().UTC()
+	n := len(q.deliveries)
+	out := make([]Delivery, 0, len(parts))
+	for _, part := range parts {
+		due := now
+		d := &Delivery{ID: newID(), Target: t, Event: part, Status: StatusPending, Created: now, NextAttempt: &due, Attempts: []Attempt{}}
+		q.deliveries = append(q.deliveries, d)
+		out = append(out, d.clone())
+	}
+	if err := q.save(); err != nil {
+		q.deliveries = q.deliveries[:n]
+		return nil, err
+	}
+	q.signal()
+	return out, nil
+}
+
+// Process tries every pending delivery that is due at now, one at a time, and returns
+// how many it tried.
+func (q *Queue) Process(ctx context.Context, now time.Time) int {
+	q.processing.Lock()
+	defer q.processing.Unlock()
+	q.mu.Lock()
+	var due []*Delivery
+	for _, d := range q.deliveries {
+		if d.Status == StatusPending && d.NextAttempt != nil && !d.NextAttempt.After(now) {
+			due = append(due, d)
+		}
+	}
+	q.mu.Unlock()
+
+	for _, d := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		q.mu.Lock()
+		sender, snapshot := q.senders[d.Target.Type], d.clone()
+		q.mu.Unlock()
+		var err error
+		if sender == nil {
+			err = Permanent(fmt.Errorf("%w %q", ErrNoSender, d.Target.Type))
+		} else {
+			err = sender.Send(ctx, snapshot.Target, snapshot)
+		}
+		q.record(d, time.Now().UTC(), err)
+	}
+	return len(due)
+}
+
+// record stores the outcome of an attempt, scheduling a retry or dead-lettering the
+// delivery on failure.
+func (q *Queue) record(d *Delivery, at time.Time, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	a := Attempt{At: at}
+	if err != nil {
+		a.Error = err.Error()
+	}
+	d.Attempts = append(d.Attempts, a)
+	d.Tries++
+	switch {
+	case err == nil:
+		d.Status, d.NextAttempt = StatusDelivered, nil
+	case IsPermanent(err) || d.Tries >= q.MaxAttempts:
+		d.Status, d.NextAttempt = StatusDead, nil
+		log.Printf("WARN: delivery %s to %s %s dead-lettered after %d attempts: %v", d.ID, d.Target.Type, d.Target.Address, d.Tries, err)
+	default:
+		next := at.Add(Backoff(d.Tries))
+		d.NextAttempt = &next
+	}
+	q.prune(at)
+	if err := q.save(); err != nil {
+		log.Printf("WARN: delivery queue not saved: %v", err)
+	}
+}
+
+// Backoff is the delay after the given number of failed attempts.
+func Backoff(attempts int) time.Duration {
+	d := BaseBackoff
+	for i := 1; i < attempts && d < MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, MaxBackoff)
+}
+
+// Run processes deliveries as they fall due until ctx is cancelled.
+func (q *Queue) Run(ctx context.Context) {
+	for {
+		q.Process(ctx, time.Now().UTC())
+		wait := time.Hour
+		if next, ok := q.nextDue(); ok {
+			wait = max(time.Until(next), 0)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-q.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+func (q *Queue) nextDue() (time.Time, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var next time.Time
+	for _, d := range q.deliveries {
+		if d.Status == StatusPending && d.NextAttempt != nil && (next.IsZero() || d.NextAttempt.Before(next)) {
+			next = *d.NextAttempt
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// List returns deliveries newest first, restricted to status when non-empty and to at
+// most limit entries when limit is positive.
+func (q *Queue) List(status string, limit int) []Delivery {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	out := make([]Delivery, 0)
+	for i := len(q.deliveries) - 1; i >= 0; i-- {
+		if limit > 0 && len(out) == limit {
+			break
+		}
+		if d := q.deliveries[i]; status == "" || d.Status == status {
+			out = append(out, d.clone())
+		}
+	}
+	return out
+}
+
+// Counts returns the number of deliveries in each status.
+func (q *Queue) Counts() map[string]int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	out := map[string]int{StatusPending: 0, StatusDelivered: 0, StatusDead: 0}
+	for _, d := range q.deliveries {
+		out[d.Status]++
+	}
+	return out
+}
+
+// Get returns the delivery with the given id.
+func (q *Queue) Get(id string) (Delivery, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, d := range q.deliveries {
+		if d.ID == id {
+			return d.clone(), true
+		}
+	}
+	return Delivery{}, false
+}
+
+// Retry makes a dead delivery pending again, due immediately, with a fresh set of
+// tries; its earlier attempts stay in its history.
+func (q *Queue) Retry(id string) (Delivery, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, d := range q.deliveries {
+		if d.ID != id {
+			continue
+		}
+		if d.Status != StatusDead {
+			return Delivery{}, fmt.Errorf("delivery %s is %s, not %s", id, d.Status, StatusDead)
+		}
+		now := time.Now().UTC()
+		d.Status, d.NextAttempt, d.Tries = StatusPending, &now, 0
+		if err := q.save(); err != nil {
+			return Delivery{}, err
+		}
+		q.signal()
+		return d.clone(), nil
+	}
+	return Delivery{}, ErrNotFound
+}
+
+// prune drops finished deliveries older than KeepFor and beyond the newest KeepFinished.
+// Callers hold q.mu.
+func (q *Queue) prune(now time.Time) {
+	drop := make(map[*Delivery]bool)
+	finished := 0
+	for i := len(q.deliveries) - 1; i >= 0; i-- {
+		if d := q.deliveries[i]; d.Status != StatusPending {
+			finished++
+			if finished > KeepFinished || now.Sub(d.Created) > KeepFor {
+				drop[d] = true
+			}
+		}
+	}
+	if len(drop) == 0 {
+		return
+	}
+	keep := make([]*Delivery, 0, len(q.deliveries)-len(drop))
+	for _, d := range q.deliveries {
+		if !drop[d] {
+			keep = append(keep, d)
+		}
+	}
+	q.deliveries = keep
+}
+
+// signal wakes Run without blocking. Callers hold q.mu.
+func (q *Queue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// save writes the queue. Callers hold q.mu.
+func (q *Queue) save() error {
+	if q.path == "" {
+		return nil
+	}
+	return jsonfile.Save(q.path, q.deliveries)
+}
+
+func (d *Delivery) clone() Delivery {
+	out := *d
+	out.Attempts = append([]Attempt(nil), d.Attempts...)
+	if d.NextAttempt != nil {
+		t := *d.NextAttempt
+		out.NextAttempt = &t
+	}
+	return out
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,122 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// flakySender fails its first failures sends and succeeds after that.
+type flakySender struct {
+	failures int
+	err      error
+	sent     []string
+}
+
+func (f *flakySender) Send(_ context.Context, _ Target, d Delivery) error {
+	f.sent = append(f.sent, d.ID)
+	if len(f.sent) <= f.failures {
+		return f.err
+	}
+	return nil
+}
+
+func TestQueueRetriesWithBackoffAndPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deliveries.json")
+	q, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender := &flakySender{failures: 2, err: errors.New("connection refused")}
+	q.Register("webhook", sender)
+	if _, err := q.Enqueue(Target{Type: "slack", Address: "x"}, Event{}); !errors.Is(err, ErrNoSender) {
+		t.Fatalf("expected ErrNoSender, got %v", err)
+	}
+	ds, err := q.Enqueue(Target{Type: "webhook", Address: "http://example.invalid"}, Event{ID: "e1", Type: EventNotices})
+	if err != nil || len(ds) != 1 {
+		t.Fatal(ds, err)
+	}
+	d := ds[0]
+
+	ctx := context.Background()
+	if n := q.Process(ctx, time.Now()); n != 1 {
+		t.Fatalf("first pass tried %d", n)
+	}
+	got, _ := q.Get(d.ID)
+	if got.Status != StatusPending || got.Tries != 1 || got.NextAttempt == nil || time.Until(*got.NextAttempt) < 50*time.Second {
+		t.Fatalf("failed delivery not rescheduled with backoff: %+v", got)
+	}
+	if n := q.Process(ctx, time.Now()); n != 0 {
+		t.Fatal("delivery retried before its backoff elapsed")
+	}
+
+	// The queue survives a restart.
+	q, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q.Register("webhook", sender)
+	q.Process(ctx, time.Now().Add(2*time.Minute))
+	got, _ = q.Get(d.ID)
+	if got.Tries != 2 || time.Until(*got.NextAttempt) < 110*time.Second {
+		t.Fatalf("second retry should back off two minutes: %+v", got)
+	}
+	q.Process(ctx, time.Now().Add(5*time.Minute))
+	got, _ = q.Get(d.ID)
+	if got.Status != StatusDelivered || len(got.Attempts) != 3 || got.Attempts[2].Error != "" {
+		t.Fatalf("delivery not completed: %+v", got)
+	}
+	if c := q.Counts(); c[StatusDelivered] != 1 || c[StatusPending] != 0 {
+		t.Fatalf("unexpected counts: %v", c)
+	}
+}
+
+func TestQueueDeadLettersAndRetries(t *testing.T) {
+	q, _ := Open("")
+	q.MaxAttempts = 2
+	sender := &flakySender{failures: 100, err: errors.New("status 503")}
+	q.Register("webhook", sender)
+	ds, _ := q.Enqueue(Target{Type: "webhook", Address: "http://example.invalid"}, Event{})
+	d := ds[0]
+
+	ctx := context.Background()
+	q.Process(ctx, time.Now())
+	q.Process(ctx, time.Now().Add(time.Hour))
+	if got, _ := q.Get(d.ID); got.Status != StatusDead || got.NextAttempt != nil {
+		t.Fatalf("delivery not dead-lettered after MaxAttempts: %+v", got)
+	}
+	if dead := q.List(StatusDead, 0); len(dead) != 1 || dead[0].ID != d.ID {
+		t.Fatalf("dead letters: %+v", dead)
+	}
+
+	sender.failures = 0
+	if _, err := q.Retry(d.ID); err != nil {
+		t.Fatal(err)
+	}
+	q.Process(ctx, time.Now())
+	if got, _ := q.Get(d.ID); got.Status != StatusDelivered || len(got.Attempts) != 3 {
+		t.Fatalf("retried delivery: %+v", got)
+	}
+	if _, err := q.Retry(d.ID); err == nil {
+		t.Fatal("retrying a delivered delivery should fail")
+	}
+
+	// Permanent failures skip the remaining attempts.
+	sender.failures, sender.err, sender.sent = 100, Permanent(errors.New("status 410")), nil
+	ds, _ = q.Enqueue(Target{Type: "webhook", Address: "http://example.invalid"}, Event{})
+	d = ds[0]
+	q.Process(ctx, time.Now())
+	if got, _ := q.Get(d.ID); got.Status != StatusDead || got.Tries != 1 {
+		t.Fatalf("permanent failure not dead-lettered: %+v", got)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for attempts, want := range map[int]time.Duration{1: time.Minute, 2: 2 * time.Minute, 4: 8 * time.Minute, 20: MaxBackoff} {
+		if got := Backoff(attempts); got != want {
+			t.Errorf("Backoff(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
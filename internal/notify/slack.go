@@ -0,0 +1,98 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Slack message limits: a message holds at most 50 blocks and a section's text at most
+// 3000 characters. slackMaxNotices leaves room for the header and context blocks, and
+// slackMaxBytes keeps well clear of the overall payload limit.
+const (
+	slackMaxNotices = 20
+	slackMaxBytes   = 30000
+	slackMaxHeader  = 150
+	slackMaxSection = 3000
+)
+
+// Slack posts events to a Slack incoming webhook URL as Block Kit messages.
+type Slack struct {
+	Client *http.Client
+}
+
+// Split implements Splitter.
+func (s *Slack) Split(ev Event) []Event {
+	return split(ev, slackMaxNotices, slackMaxBytes, renderSlack)
+}
+
+// Send delivers d as one Slack message.
+func (s *Slack) Send(ctx context.Context, t Target, d Delivery) error {
+	body, err := renderSlack(d.Event, time.Now())
+	if err != nil {
+		return Permanent(err)
+	}
+	return postJSON(ctx, s.Client, t.Address, body, nil)
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+// renderSlack renders an event as a Block Kit message: a header, one section per notice
+// and a context line naming the source. text is the notification fallback.
+func renderSlack(ev Event, now time.Time) ([]byte, error) {
+	head := headline(ev)
+	blocks := []slackBlock{{Type: "header", Text: &slackText{Type: "plain_text", Text: truncate(head, slackMaxHeader)}}}
+	for _, n := range ev.Notices {
+		blocks = append(blocks, slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: truncate(slackNotice(n, now), slackMaxSection)}})
+	}
+	blocks = append(blocks, slackBlock{Type: "context", Elements: []slackText{{Type: "mrkdwn", Text: slackEscape(sourceText(ev))}}})
+	return encodeJSON(map[string]interface{}{"text": head, "blocks": blocks})
+}
+
+// slackNotice renders one notice as mrkdwn: a linked title, then agency, NAICS and the
+// deadline countdown.
+func slackNotice(n Notice, now time.Time) string {
+	o := n.Opportunity
+	title := "*" + slackEscape(firstNonEmpty(o.Title, o.NoticeID)) + "*"
+	if o.URL != "" {
+		title = "*<" + o.URL + "|" + slackEscape(firstNonEmpty(o.Title, o.NoticeID)) + ">*"
+	}
+	lines := []string{title}
+	if c := changeText(n); c != "" {
+		lines = append(lines, "_"+slackEscape(c)+"_")
+	}
+	var facts []string
+	for _, f := range [][2]string{{"Agency", o.Agency}, {"NAICS", naicsText(o)}, {"Type", o.Type}, {"Due", deadlineText(o.ResponseDeadline, now)}} {
+		if f[1] != "" {
+			facts = append(facts, "*"+f[0]+":* "+slackEscape(f[1]))
+		}
+	}
+	if len(facts) > 0 {
+		lines = append(lines, strings.Join(facts, "\n"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// slackEscape escapes the characters Slack mrkdwn treats as control sequences.
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,82 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Teams limits webhook messages to about 28 KB; teamsMaxBytes leaves headroom for the
+// envelope.
+const (
+	teamsMaxNotices = 25
+	teamsMaxBytes   = 24000
+)
+
+// Teams posts events to a Microsoft Teams incoming webhook or Workflows URL as Adaptive
+// Card messages.
+type Teams struct {
+	Client *http.Client
+}
+
+// Split implements Splitter.
+func (t *Teams) Split(ev Event) []Event {
+	return split(ev, teamsMaxNotices, teamsMaxBytes, renderTeams)
+}
+
+// Send delivers d as one Teams message.
+func (t *Teams) Send(ctx context.Context, target Target, d Delivery) error {
+	body, err := renderTeams(d.Event, time.Now())
+	if err != nil {
+		return Permanent(err)
+	}
+	return postJSON(ctx, t.Client, target.Address, body, nil)
+}
+
+type teamsFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+// renderTeams renders an event as a message with one Adaptive Card: a heading, a
+// container per notice with its linked title and facts, and the source.
+func renderTeams(ev Event, now time.Time) ([]byte, error) {
+	body := []map[string]interface{}{
+		{"type": "TextBlock", "text": headline(ev), "size": "Medium", "weight": "Bolder", "wrap": true},
+	}
+	for _, n := range ev.Notices {
+		o := n.Opportunity
+		title := firstNonEmpty(o.Title, o.NoticeID)
+		if o.URL != "" {
+			title = "[" + strings.NewReplacer("[", "(", "]", ")").Replace(title) + "](" + o.URL + ")"
+		}
+		items := []map[string]interface{}{{"type": "TextBlock", "text": title, "weight": "Bolder", "wrap": true}}
+		if c := changeText(n); c != "" {
+			items = append(items, map[string]interface{}{"type": "TextBlock", "text": c, "isSubtle": true, "wrap": true, "spacing": "None"})
+		}
+		var facts []teamsFact
+		for _, f := range []teamsFact{{"Agency", o.Agency}, {"NAICS", naicsText(o)}, {"Type", o.Type}, {"Due", deadlineText(o.ResponseDeadline, now)}} {
+			if f.Value != "" {
+				facts = append(facts, f)
+			}
+		}
+		if len(facts) > 0 {
+			items = append(items, map[string]interface{}{"type": "FactSet", "facts": facts})
+		}
+		body = append(body, map[string]interface{}{"type": "Container", "separator": true, "items": items})
+	}
+	body = append(body, map[string]interface{}{"type": "TextBlock", "text": sourceText(ev), "isSubtle": true, "size": "Small", "wrap": true})
+
+	card := map[string]interface{}{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body":    body,
+		"msteams": map[string]string{"width": "Full"},
+	}
+	return encodeJSON(map[string]interface{}{
+		"type":        "message",
+		"attachments": []map[string]interface{}{{"contentType": "application/vnd.microsoft.card.adaptive", "content": card}},
+	})
+}
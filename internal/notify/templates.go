@@ -0,0 +1,167 @@
+package notify
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+
+	"sam-mcp/internal/sam"
+)
+
+// Digest template files. A file of the same name in the templates directory replaces the
+// built-in one. Subject and text are text/template files and the HTML body is an
+// html/template file; all three are executed with a DigestData.
+const (
+	SubjectTemplate = "digest.subject.tmpl"
+	TextTemplate    = "digest.txt.tmpl"
+	HTMLTemplate    = "digest.html.tmpl"
+)
+
+// DueSoon is how close a response deadline must be for a digest to highlight it.
+const DueSoon = 7 * 24 * time.Hour
+
+//go:embed templates/*.tmpl
+var builtinTemplates embed.FS
+
+// DigestData is the data digest templates are executed with.
+type DigestData struct {
+	Recipient string
+	Generated time.Time
+	SamEnv    string
+	// Total counts the notices, Added and Amended split them by change, and DueSoon
+	// counts the open notices due within DueSoon.
+	Total, Added, Amended, DueSoon int
+	Groups                         []DigestGroup
+}
+
+// DigestGroup holds the notices reported by one saved search or prefetch profile.
+type DigestGroup struct {
+	Source  Source
+	Name    string
+	Notices []DigestNotice
+}
+
+// DigestNotice is one opportunity in a digest.
+type DigestNotice struct {
+	Opportunity sam.Opportunity
+	Amended     bool
+	// Change is empty for new notices and describes the amendment otherwise, e.g.
+	// "Amended: responseDeadline".
+	Change string
+	// Due renders the response deadline with a countdown; DueSoon and Closed flag
+	// deadlines within DueSoon and past ones.
+	Due             string
+	DueSoon, Closed bool
+	NAICS           string
+}
+
+// Templates renders digest emails.
+type Templates struct {
+	subject *template.Template
+	text    *template.Template
+	html    *htmltemplate.Template
+}
+
+var templateFuncs = map[string]interface{}{
+	"join": strings.Join,
+	"date": func(t time.Time) string { return t.UTC().Format("Jan 2, 2006") },
+}
+
+// LoadTemplates returns the built-in digest templates, with any of the files in dir
+// replacing them. An empty dir loads only the built-ins.
+func LoadTemplates(dir string) (*Templates, error) {
+	read := func(name string) (string, error) {
+		if dir != "" {
+			raw, err := os.ReadFile(path.Join(dir, name))
+			if err == nil {
+				return string(raw), nil
+			}
+			if !errors.Is(err, fs.ErrNotExist) {
+				return "", fmt.Errorf("digest templates dir %s: %w", dir, err)
+			}
+		}
+		raw, err := builtinTemplates.ReadFile("templates/" + name)
+		return string(raw), err
+	}
+	t := &Templates{}
+	src, err := read(SubjectTemplate)
+	if err != nil {
+		return nil, err
+	}
+	if t.subject, err = template.New(SubjectTemplate).Funcs(templateFuncs).Parse(src); err != nil {
+		return nil, err
+	}
+	if src, err = read(TextTemplate); err != nil {
+		return nil, err
+	}
+	if t.text, err = template.New(TextTemplate).Funcs(templateFuncs).Parse(src); err != nil {
+		return nil, err
+	}
+	if src, err = read(HTMLTemplate); err != nil {
+		return nil, err
+	}
+	if t.html, err = htmltemplate.New(HTMLTemplate).Funcs(templateFuncs).Parse(src); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Render executes the templates for a digest event built by Compose.
+func (t *Templates) Render(ev Event, now time.Time) (subject, text, html string, err error) {
+	data := NewDigestData(ev, now)
+	var b bytes.Buffer
+	if err = t.subject.Execute(&b, data); err != nil {
+		return "", "", "", err
+	}
+	subject = strings.Join(strings.Fields(b.String()), " ")
+	b.Reset()
+	if err = t.text.Execute(&b, data); err != nil {
+		return "", "", "", err
+	}
+	text = b.String()
+	b.Reset()
+	if err = t.html.Execute(&b, data); err != nil {
+		return "", "", "", err
+	}
+	return subject, text, b.String(), nil
+}
+
+// NewDigestData groups a digest event's notices by source, keeping their order.
+func NewDigestData(ev Event, now time.Time) DigestData {
+	data := DigestData{Recipient: ev.Source.ID, Generated: ev.Detected, SamEnv: ev.SamEnv, Total: len(ev.Notices)}
+	for _, n := range ev.Notices {
+		src := ev.Source
+		if n.Source != nil {
+			src = *n.Source
+		}
+		if len(data.Groups) == 0 || data.Groups[len(data.Groups)-1].Source != src {
+			data.Groups = append(data.Groups, DigestGroup{Source: src, Name: firstNonEmpty(src.Name, src.ID)})
+		}
+		o := n.Opportunity
+		dn := DigestNotice{Opportunity: o, Amended: n.Change == ChangeAmended, Change: changeText(n), Due: deadlineText(o.ResponseDeadline, now), NAICS: naicsText(o)}
+		if d := o.ResponseDeadline; d != nil && !d.IsZero() {
+			left := d.Sub(now)
+			dn.Closed = left < 0
+			dn.DueSoon = left >= 0 && left <= DueSoon
+		}
+		if dn.Amended {
+			data.Amended++
+		} else {
+			data.Added++
+		}
+		if dn.DueSoon {
+			data.DueSoon++
+		}
+		g := &data.Groups[len(data.Groups)-1]
+		g.Notices = append(g.Notices, dn)
+	}
+	return data
+}
@@ -0,0 +1,109 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Webhook request headers.
+const (
+	HeaderEvent     = "X-Sam-Mcp-Event"
+	HeaderDelivery  = "X-Sam-Mcp-Delivery"
+	HeaderTimestamp = "X-Sam-Mcp-Timestamp"
+	// HeaderSignature carries "sha256=" and the hex HMAC-SHA256 of the timestamp, a "."
+	// and the body, keyed with the shared secret.
+	HeaderSignature = "X-Sam-Mcp-Signature"
+)
+
+// Webhook POSTs events as JSON to the target URL.
+type Webhook struct {
+	// Secret signs each request; requests are unsigned when it is empty.
+	Secret string
+	Client *http.Client
+}
+
+// Send delivers d. Client errors other than 408 and 429 are permanent; a receiver that
+// rejects the payload will not accept it on retry either.
+func (w *Webhook) Send(ctx context.Context, t Target, d Delivery) error {
+	body, err := json.Marshal(d.Event)
+	if err != nil {
+		return Permanent(err)
+	}
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	header := http.Header{}
+	header.Set(HeaderEvent, d.Event.Type)
+	header.Set(HeaderDelivery, d.ID)
+	header.Set(HeaderTimestamp, ts)
+	if w.Secret != "" {
+		header.Set(HeaderSignature, Sign(w.Secret, ts, body))
+	}
+	return postJSON(ctx, w.Client, t.Address, body, header)
+}
+
+// postJSON POSTs a JSON body with the extra headers and maps the response status to a
+// delivery error.
+func postJSON(ctx context.Context, client *http.Client, address string, body []byte, header http.Header) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, address, bytes.NewReader(body))
+	if err != nil {
+		return Permanent(err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if errors.Is(err, ErrForbiddenAddress) {
+		return Permanent(err)
+	}
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return statusError(resp)
+}
+
+// statusError maps a receiver's response status to a delivery error.
+func statusError(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	err := fmt.Errorf("receiver status %s", resp.Status)
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+		return Permanent(err)
+	}
+	return err
+}
+
+// Sign returns the HeaderSignature value for a request body sent at timestamp ts (Unix
+// seconds, as in HeaderTimestamp).
+func Sign(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a request's signature and rejects timestamps more than tolerance away
+// from now, for receivers written in Go.
+func Verify(secret, ts, signature string, body []byte, tolerance time.Duration) bool {
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return false
+	}
+	if d := time.Since(time.Unix(sec, 0)); d > tolerance || d < -tolerance {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, ts, body)), []byte(signature))
+}
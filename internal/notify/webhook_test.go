@@ -0,0 +1,70 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhookSignsRequests(t *testing.T) {
+	var header http.Header
+	var body []byte
+	status := http.StatusNoContent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	w := &Webhook{Secret: "s3cret", Client: srv.Client()}
+	d := Delivery{ID: "d1", Event: Event{ID: "e1", Type: EventNotices, Source: Source{Kind: SourceSavedSearch, ID: "abc"}}}
+	if err := w.Send(context.Background(), Target{Type: "webhook", Address: srv.URL}, d); err != nil {
+		t.Fatal(err)
+	}
+	if header.Get(HeaderDelivery) != "d1" || header.Get(HeaderEvent) != EventNotices {
+		t.Fatalf("unexpected headers: %v", header)
+	}
+	if !Verify("s3cret", header.Get(HeaderTimestamp), header.Get(HeaderSignature), body, 5*time.Minute) {
+		t.Fatalf("signature %q does not verify", header.Get(HeaderSignature))
+	}
+	if Verify("other", header.Get(HeaderTimestamp), header.Get(HeaderSignature), body, 5*time.Minute) {
+		t.Fatal("signature verified with the wrong secret")
+	}
+
+	status = http.StatusGone
+	if err := w.Send(context.Background(), Target{Address: srv.URL}, d); !IsPermanent(err) {
+		t.Fatalf("410 should be permanent, got %v", err)
+	}
+	status = http.StatusServiceUnavailable
+	if err := w.Send(context.Background(), Target{Address: srv.URL}, d); err == nil || IsPermanent(err) {
+		t.Fatalf("503 should be retried, got %v", err)
+	}
+}
+
+func TestHTTPClientRefusesPrivateAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	d := Delivery{ID: "d1", Event: Event{ID: "e1", Type: EventNotices}}
+
+	w := &Webhook{Client: NewHTTPClient(time.Second, nil)}
+	if err := w.Send(context.Background(), Target{Address: srv.URL}, d); !errors.Is(err, ErrForbiddenAddress) || !IsPermanent(err) {
+		t.Fatalf("loopback receiver should be refused permanently, got %v", err)
+	}
+	w.Client = NewHTTPClient(time.Second, []string{"127.0.0.1"})
+	if err := w.Send(context.Background(), Target{Address: srv.URL}, d); err != nil {
+		t.Fatalf("allowed host refused: %v", err)
+	}
+	for _, addr := range []string{"10.0.0.5:443", "169.254.169.254:80", "[::1]:443", "100.64.1.1:443", "0.0.0.0:80"} {
+		if err := publicOnly("tcp", addr, nil); !errors.Is(err, ErrForbiddenAddress) {
+			t.Errorf("%s allowed", addr)
+		}
+	}
+	if err := publicOnly("tcp", "93.184.216.34:443", nil); err != nil {
+		t.Errorf("public address refused: %v", err)
+	}
+}
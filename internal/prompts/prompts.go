@@ -0,0 +1,190 @@
+// Package prompts loads the MCP prompt templates offered by the server: built-in capture
+// workflows embedded in the binary, plus any templates found in a configurable directory.
+//
+// A template is a Go text/template file named <prompt>.tmpl. It starts with a template
+// comment holding JSON metadata, followed by the message text:
+//
+//	{{/* {"title": "...", "description": "...",
+//	      "arguments": [{"name": "noticeId", "description": "...", "required": true}]} */}}
+//	Analyze {{with opportunity .noticeId}}{{.Title}}{{end}} for {{.companyProfile}}.
+//
+// Arguments are available as fields of the template data (.noticeId). Templates can fetch
+// SAM.gov data with the opportunity and search functions and format it with json and date.
+package prompts
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"sam-mcp/internal/mcp"
+	"sam-mcp/internal/sam"
+)
+
+//go:embed templates/*.tmpl
+var builtinFS embed.FS
+
+// Source supplies the SAM.gov data templates embed.
+type Source interface {
+	// Opportunity returns a notice by id.
+	Opportunity(noticeID string) (*sam.Opportunity, error)
+	// Search returns the latest results of a saved search or prefetch profile.
+	Search(id string) ([]sam.Opportunity, error)
+}
+
+// Prompt is a parsed prompt template.
+type Prompt struct {
+	mcp.Prompt
+	tmpl *template.Template
+}
+
+// Set is a collection of prompts keyed by name.
+type Set struct {
+	prompts map[string]*Prompt
+}
+
+// metadata is the JSON header of a template file.
+type metadata struct {
+	Title       string               `json:"title"`
+	Description string               `json:"description"`
+	Arguments   []mcp.PromptArgument `json:"arguments"`
+}
+
+// Load returns the built-in prompts merged with the templates in dir. A template in dir
+// replaces a built-in prompt of the same name. An empty dir loads only the built-ins.
+func Load(dir string) (*Set, error) {
+	set := &Set{prompts: make(map[string]*Prompt)}
+	if err := set.addFS(builtinFS, "templates"); err != nil {
+		return nil, fmt.Errorf("built-in prompts: %w", err)
+	}
+	if dir == "" {
+		return set, nil
+	}
+	if err := set.addFS(os.DirFS(dir), "."); err != nil {
+		return nil, fmt.Errorf("prompts dir %s: %w", dir, err)
+	}
+	return set, nil
+}
+
+func (s *Set) addFS(fsys fs.FS, dir string) error {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.tmpl"))
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		b, err := fs.ReadFile(fsys, f)
+		if err != nil {
+			return err
+		}
+		p, err := parse(strings.TrimSuffix(path.Base(f), ".tmpl"), string(b))
+		if err != nil {
+			return fmt.Errorf("%s: %w", f, err)
+		}
+		s.prompts[p.Name] = p
+	}
+	return nil
+}
+
+func parse(name, text string) (*Prompt, error) {
+	body := strings.TrimLeft(text, " \t\r\n")
+	if !strings.HasPrefix(body, "{{/*") {
+		return nil, errors.New("template must start with a {{/* json metadata */}} comment")
+	}
+	end := strings.Index(body, "*/}}")
+	if end < 0 {
+		return nil, errors.New("unterminated metadata comment")
+	}
+	var meta metadata
+	if err := json.Unmarshal([]byte(body[len("{{/*"):end]), &meta); err != nil {
+		return nil, fmt.Errorf("metadata: %w", err)
+	}
+	tmpl, err := template.New(name).Option("missingkey=zero").Funcs(funcs(nil)).Parse(strings.TrimLeft(body[end+len("*/}}"):], "\r\n"))
+	if err != nil {
+		return nil, err
+	}
+	return &Prompt{
+		Prompt: mcp.Prompt{Name: name, Title: meta.Title, Description: meta.Description, Arguments: meta.Arguments},
+		tmpl:   tmpl,
+	}, nil
+}
+
+// funcs returns the template functions, bound to src when rendering.
+func funcs(src Source) template.FuncMap {
+	return template.FuncMap{
+		"opportunity": func(id string) (*sam.Opportunity, error) {
+			if id == "" {
+				return nil, errors.New("opportunity: notice id is empty")
+			}
+			return src.Opportunity(id)
+		},
+		"search": func(id string) ([]sam.Opportunity, error) {
+			return src.Search(id)
+		},
+		"json": func(v interface{}) (string, error) {
+			b, err := json.MarshalIndent(v, "", "  ")
+			return string(b), err
+		},
+		"date": func(t time.Time) string {
+			if t.IsZero() {
+				return "unknown"
+			}
+			return t.Format("2006-01-02")
+		},
+	}
+}
+
+// List returns the prompts sorted by name.
+func (s *Set) List() []mcp.Prompt {
+	out := make([]mcp.Prompt, 0, len(s.prompts))
+	for _, p := range s.prompts {
+		out = append(out, p.Prompt)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Get returns the prompt with the given name.
+func (s *Set) Get(name string) (*Prompt, bool) {
+	p, ok := s.prompts[name]
+	return p, ok
+}
+
+// MissingArguments returns the required arguments absent from args.
+func (p *Prompt) MissingArguments(args map[string]string) []string {
+	var missing []string
+	for _, a := range p.Arguments {
+		if a.Required && strings.TrimSpace(args[a.Name]) == "" {
+			missing = append(missing, a.Name)
+		}
+	}
+	return missing
+}
+
+// Render executes the template with args, fetching data from src.
+func (p *Prompt) Render(args map[string]string, src Source) (string, error) {
+	tmpl, err := p.tmpl.Clone()
+	if err != nil {
+		return "", err
+	}
+	data := make(map[string]string, len(p.Arguments))
+	for _, a := range p.Arguments {
+		data[a.Name] = ""
+	}
+	for k, v := range args {
+		data[k] = v
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Funcs(funcs(src)).Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
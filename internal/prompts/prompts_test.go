@@ -0,0 +1,93 @@
+package prompts
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"sam-mcp/internal/sam"
+)
+
+type fakeSource struct{}
+
+func (fakeSource) Opportunity(id string) (*sam.Opportunity, error) {
+	if id != "N1" {
+		return nil, errors.New("not found")
+	}
+	return &sam.Opportunity{NoticeID: "N1", Title: "Cloud Support", Agency: "GSA", Modified: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)}, nil
+}
+
+func (fakeSource) Search(string) ([]sam.Opportunity, error) {
+	o, _ := fakeSource{}.Opportunity("N1")
+	return []sam.Opportunity{*o}, nil
+}
+
+func TestBuiltinPrompts(t *testing.T) {
+	set, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, p := range set.List() {
+		names = append(names, p.Name)
+	}
+	if strings.Join(names, ",") != "bid_no_bid,compliance_matrix,weekly_pipeline_summary" {
+		t.Fatalf("unexpected prompts: %v", names)
+	}
+
+	p, _ := set.Get("bid_no_bid")
+	if missing := p.MissingArguments(map[string]string{"noticeId": "N1"}); len(missing) != 1 || missing[0] != "companyProfile" {
+		t.Fatalf("unexpected missing arguments: %v", missing)
+	}
+	text, err := p.Render(map[string]string{"noticeId": "N1", "companyProfile": "8(a) cloud integrator"}, fakeSource{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Opportunity: Cloud Support", "Last modified: 2026-03-01", "8(a) cloud integrator", `"noticeId": "N1"`} {
+		if !strings.Contains(text, want) {
+			t.Errorf("rendered prompt missing %q:\n%s", want, text)
+		}
+	}
+	if _, err := p.Render(map[string]string{"noticeId": "N2"}, fakeSource{}); err == nil {
+		t.Fatal("expected error for unknown notice")
+	}
+
+	weekly, _ := set.Get("weekly_pipeline_summary")
+	text, err = weekly.Render(nil, fakeSource{})
+	if err != nil || !strings.Contains(text, "1 opportunities:") || !strings.Contains(text, "Cloud Support | GSA | notice N1") {
+		t.Fatalf("unexpected weekly summary (%v):\n%s", err, text)
+	}
+}
+
+func TestLoadDirOverridesBuiltins(t *testing.T) {
+	dir := t.TempDir()
+	custom := `{{/* {"description": "Team variant", "arguments": [{"name": "noticeId", "required": true}]} */}}
+Review {{(opportunity .noticeId).Title}}.`
+	if err := os.WriteFile(filepath.Join(dir, "bid_no_bid.tmpl"), []byte(custom), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "teaming.tmpl"), []byte(`{{/* {"title": "Teaming"} */}}Find partners.`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	set, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set.List()) != 4 {
+		t.Fatalf("expected 4 prompts, got %+v", set.List())
+	}
+	p, _ := set.Get("bid_no_bid")
+	if text, err := p.Render(map[string]string{"noticeId": "N1"}, fakeSource{}); err != nil || text != "Review Cloud Support." {
+		t.Fatalf("override not used: %q, %v", text, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "broken.tmpl"), []byte("no metadata"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir); err == nil {
+		t.Fatal("expected error for template without metadata")
+	}
+}
@@ -0,0 +1,132 @@
+package sam
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AssistanceVersion is the Assistance Listings API version (formerly CFDA).
+const AssistanceVersion = "v1"
+
+// maxAssistanceLimit is the largest page the Assistance Listings API returns.
+const maxAssistanceLimit = 100
+
+// AssistanceParams filters assistance listings. ProgramNumber is the listing number such
+// as 10.310; Eligibility holds applicant type names or codes.
+type AssistanceParams struct {
+	Keyword       string
+	Agency        string
+	AgencyCode    string
+	ProgramNumber string
+	Eligibility   []string
+	Limit         int
+	Offset        int
+}
+
+// AssistanceListing is a normalized federal financial assistance program.
+type AssistanceListing struct {
+	Number          string    `json:"number"`
+	Title           string    `json:"title"`
+	Agency          string    `json:"agency"`
+	Objectives      string    `json:"objectives,omitempty"`
+	AssistanceTypes []string  `json:"assistanceTypes,omitempty"`
+	Eligibility     []string  `json:"eligibility,omitempty"`
+	Modified        time.Time `json:"modified"`
+	URL             string    `json:"url"`
+}
+
+// AssistancePage is one page of assistance listing results.
+type AssistancePage struct {
+	TotalRecords int                 `json:"totalRecords"`
+	Limit        int                 `json:"limit"`
+	Offset       int                 `json:"offset"`
+	Listings     []AssistanceListing `json:"listings"`
+}
+
+// SearchAssistance queries the Assistance Listings API.
+func (c *Client) SearchAssistance(ctx context.Context, p AssistanceParams) (*AssistancePage, error) {
+	if p.Limit <= 0 || p.Limit > maxAssistanceLimit {
+		p.Limit = 25
+	}
+	if p.Offset < 0 {
+		p.Offset = 0
+	}
+	u, err := c.endpoint("/assistance-listings/" + AssistanceVersion + "/search")
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	q.Set("api_key", c.APIKey)
+	if p.Keyword != "" {
+		q.Set("keyword", p.Keyword)
+	}
+	if p.AgencyCode != "" {
+		q.Set("organizationId", p.AgencyCode)
+	} else if p.Agency != "" {
+		q.Set("organizationName", p.Agency)
+	}
+	if p.ProgramNumber != "" {
+		q.Set("assistanceListingNumber", p.ProgramNumber)
+	}
+	if len(p.Eligibility) > 0 {
+		q.Set("applicantTypes", strings.Join(p.Eligibility, ","))
+	}
+	q.Set("limit", strconv.Itoa(p.Limit))
+	q.Set("offset", strconv.Itoa(p.Offset))
+	u.RawQuery = q.Encode()
+
+	body, err := c.getJSON(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+	m, _ := body.(map[string]any)
+	page := &AssistancePage{TotalRecords: getInt(m, "totalRecords"), Limit: p.Limit, Offset: p.Offset, Listings: []AssistanceListing{}}
+	items := getSlice(m, "assistanceListingsData")
+	if items == nil {
+		items = extractItems(body)
+	}
+	for _, it := range items {
+		lm, _ := it.(map[string]any)
+		page.Listings = append(page.Listings, normalizeAssistance(lm))
+	}
+	if page.TotalRecords == 0 {
+		page.TotalRecords = len(page.Listings)
+	}
+	return page, nil
+}
+
+func normalizeAssistance(m map[string]any) AssistanceListing {
+	number := firstNonEmpty(getString(m, "assistanceListingId"), getString(m, "programNumber"))
+	l := AssistanceListing{
+		Number:          number,
+		Title:           getString(m, "title"),
+		Agency:          firstNonEmpty(getString(m, "organizationName"), getString(m, "agency")),
+		Objectives:      firstNonEmpty(getString(m, "objectives"), getString(m, "objective")),
+		AssistanceTypes: getStrings(m, "assistanceTypes"),
+		Eligibility:     getStrings(m, "applicantTypes"),
+		Modified:        parseTime(firstNonEmpty(getString(m, "lastModifiedDate"), getString(m, "publishedDate"))),
+		URL:             getString(m, "link"),
+	}
+	if l.URL == "" && number != "" {
+		l.URL = "https://sam.gov/fal/" + number + "/view"
+	}
+	return l
+}
+
+// getStrings reads a list that may hold plain strings or objects with a description.
+func getStrings(m map[string]any, key string) []string {
+	var out []string
+	for _, it := range getSlice(m, key) {
+		switch t := it.(type) {
+		case string:
+			out = append(out, t)
+		case map[string]any:
+			if v := firstNonEmpty(getString(t, "value"), getString(t, "description"), getString(t, "name")); v != "" {
+				out = append(out, v)
+			}
+		}
+	}
+	return out
+}
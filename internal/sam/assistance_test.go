@@ -0,0 +1,36 @@
+package sam
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSearchAssistance(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/assistance-listings/v1/search" || q.Get("keyword") != "broadband" || q.Get("applicantTypes") != "State,Tribal" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		_, _ = w.Write([]byte(`{"totalRecords":1,"assistanceListingsData":[{"assistanceListingId":"10.752","title":"Rural Broadband","organizationName":"RURAL UTILITIES SERVICE",
+		  "assistanceTypes":[{"value":"Project Grants"}],"applicantTypes":["State","Tribal"],"lastModifiedDate":"2024-02-01"}]}`))
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, "", "k", srv.Client())
+	page, err := c.SearchAssistance(context.Background(), AssistanceParams{Keyword: "broadband", Eligibility: []string{"State", "Tribal"}})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if page.TotalRecords != 1 || len(page.Listings) != 1 {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+	l := page.Listings[0]
+	if l.Number != "10.752" || l.Agency != "RURAL UTILITIES SERVICE" || len(l.AssistanceTypes) != 1 || len(l.Eligibility) != 2 {
+		t.Fatalf("unexpected listing: %+v", l)
+	}
+	if l.URL != "https://sam.gov/fal/10.752/view" || l.Modified.Year() != 2024 {
+		t.Fatalf("unexpected listing url/modified: %+v", l)
+	}
+}
@@ -0,0 +1,161 @@
+package sam
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// ContractAwardsVersion is the Contract Awards API version (the FPDS successor on SAM.gov).
+const ContractAwardsVersion = "v1"
+
+// maxAwardsLimit is the largest page the Contract Awards API returns.
+const maxAwardsLimit = 100
+
+// AwardParams filters contract award records. At least one filter is required.
+type AwardParams struct {
+	PIID               string
+	SolicitationNumber string
+	AwardeeUEI         string
+	Agency             string // contracting department code, e.g. 9700
+	NAICS              string
+	Limit              int
+	Offset             int
+}
+
+// ContractAward is a normalized contract action from the Contract Awards API.
+type ContractAward struct {
+	PIID               string    `json:"piid"`
+	ModificationNumber string    `json:"modificationNumber,omitempty"`
+	ReferencedIDVPIID  string    `json:"referencedIdvPiid,omitempty"`
+	SolicitationNumber string    `json:"solicitationNumber,omitempty"`
+	AgencyCode         string    `json:"agencyCode,omitempty"`
+	AgencyName         string    `json:"agencyName,omitempty"`
+	NAICS              string    `json:"naics,omitempty"`
+	NAICSDescription   string    `json:"naicsDescription,omitempty"`
+	Vendor             Vendor    `json:"vendor"`
+	ActionObligation   float64   `json:"actionObligation"`
+	TotalObligated     float64   `json:"totalObligated"`
+	BaseAndAllOptions  float64   `json:"baseAndAllOptions"`
+	DateSigned         time.Time `json:"dateSigned"`
+	PeriodStart        time.Time `json:"periodOfPerformanceStart"`
+	PeriodEnd          time.Time `json:"periodOfPerformanceEnd"`
+	PeriodUltimateEnd  time.Time `json:"periodOfPerformanceUltimateEnd"`
+}
+
+// Vendor identifies the awardee of a contract action.
+type Vendor struct {
+	Name string `json:"name"`
+	UEI  string `json:"uei,omitempty"`
+	CAGE string `json:"cage,omitempty"`
+}
+
+// AwardPage is one page of contract award results.
+type AwardPage struct {
+	TotalRecords int             `json:"totalRecords"`
+	Limit        int             `json:"limit"`
+	Offset       int             `json:"offset"`
+	Awards       []ContractAward `json:"awards"`
+}
+
+// SearchContractAwards queries the Contract Awards API.
+func (c *Client) SearchContractAwards(ctx context.Context, p AwardParams) (*AwardPage, error) {
+	if p.PIID == "" && p.SolicitationNumber == "" && p.AwardeeUEI == "" && p.Agency == "" && p.NAICS == "" {
+		return nil, errors.New("one of piid, solicitationNumber, awardeeUei, agency or naics is required")
+	}
+	if p.Limit <= 0 || p.Limit > maxAwardsLimit {
+		p.Limit = maxAwardsLimit
+	}
+	if p.Offset < 0 {
+		p.Offset = 0
+	}
+	u, err := c.endpoint("/contract-awards/" + ContractAwardsVersion + "/search")
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	q.Set("api_key", c.APIKey)
+	if p.PIID != "" {
+		q.Set("piid", p.PIID)
+	}
+	if p.SolicitationNumber != "" {
+		q.Set("solicitationID", p.SolicitationNumber)
+	}
+	if p.AwardeeUEI != "" {
+		q.Set("awardeeUniqueEntityId", p.AwardeeUEI)
+	}
+	if p.Agency != "" {
+		q.Set("contractingDepartmentCode", p.Agency)
+	}
+	if p.NAICS != "" {
+		q.Set("naicsCode", p.NAICS)
+	}
+	q.Set("limit", strconv.Itoa(p.Limit))
+	q.Set("offset", strconv.Itoa(p.Offset))
+	u.RawQuery = q.Encode()
+
+	body, err := c.getJSON(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+	m, _ := body.(map[string]any)
+	page := &AwardPage{TotalRecords: getInt(m, "totalRecords"), Limit: p.Limit, Offset: p.Offset, Awards: []ContractAward{}}
+	for _, it := range getSlice(m, "awardSummary") {
+		am, _ := it.(map[string]any)
+		page.Awards = append(page.Awards, normalizeContractAward(am))
+	}
+	return page, nil
+}
+
+// normalizeContractAward flattens an awardSummary item into a ContractAward.
+func normalizeContractAward(m map[string]any) ContractAward {
+	id := getMap(m, "contractId")
+	core := getMap(m, "coreData")
+	org := getMap(getMap(core, "federalOrganization"), "contractingInformation")
+	dept := getMap(org, "contractingDepartment")
+	details := getMap(m, "awardDetails")
+	dates := getMap(details, "dates")
+	dollars := getMap(details, "dollars")
+	totals := getMap(details, "totalContractDollars")
+	awardee := getMap(details, "awardeeData")
+	header := getMap(awardee, "awardeeHeader")
+	ueiInfo := getMap(awardee, "awardeeUEIInformation")
+
+	a := ContractAward{
+		PIID:               getString(id, "piid"),
+		ModificationNumber: getString(id, "modificationNumber"),
+		ReferencedIDVPIID:  getString(id, "referencedIDVPiid"),
+		SolicitationNumber: firstNonEmpty(getString(core, "solicitationId"), getString(getMap(core, "solicitationInformation"), "solicitationId")),
+		AgencyCode:         getString(dept, "code"),
+		AgencyName:         getString(dept, "name"),
+		Vendor: Vendor{
+			Name: firstNonEmpty(getString(header, "awardeeName"), getString(header, "legalBusinessName")),
+			UEI:  getString(ueiInfo, "uniqueEntityId"),
+			CAGE: getString(ueiInfo, "cageCode"),
+		},
+		ActionObligation:  getFloat(dollars, "actionObligation"),
+		TotalObligated:    getFloat(totals, "totalActionObligation"),
+		BaseAndAllOptions: firstPositive(getFloat(totals, "totalBaseAndAllOptionsValue"), getFloat(dollars, "baseAndAllOptionsValue")),
+		DateSigned:        parseTime(getString(dates, "dateSigned")),
+		PeriodStart:       parseTime(getString(dates, "periodOfPerformanceStartDate")),
+		PeriodEnd:         parseTime(getString(dates, "currentCompletionDate")),
+		PeriodUltimateEnd: parseTime(getString(dates, "ultimateCompletionDate")),
+	}
+	naics := getSlice(getMap(core, "productOrServiceInformation"), "principalNaics")
+	if len(naics) > 0 {
+		n, _ := naics[0].(map[string]any)
+		a.NAICS = getString(n, "code")
+		a.NAICSDescription = getString(n, "name")
+	}
+	return a
+}
+
+func firstPositive(vals ...float64) float64 {
+	for _, v := range vals {
+		if v > 0 {
+			return v
+		}
+	}
+	return 0
+}
@@ -0,0 +1,55 @@
+package sam
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const awardsFixture = `{"totalRecords":"1","awardSummary":[{
+  "contractId":{"piid":"W912DY20C0001","modificationNumber":"P00003"},
+  "coreData":{"solicitationId":"W912DY19R0001",
+    "federalOrganization":{"contractingInformation":{"contractingDepartment":{"code":"9700","name":"DEPT OF DEFENSE"}}},
+    "productOrServiceInformation":{"principalNaics":[{"code":"541330","name":"ENGINEERING SERVICES"}]}},
+  "awardDetails":{
+    "dates":{"dateSigned":"2020-09-28","periodOfPerformanceStartDate":"2020-10-01","currentCompletionDate":"2025-09-30","ultimateCompletionDate":"2027-09-30"},
+    "dollars":{"actionObligation":"125000.50"},
+    "totalContractDollars":{"totalActionObligation":2500000,"totalBaseAndAllOptionsValue":"9,000,000"},
+    "awardeeData":{"awardeeHeader":{"awardeeName":"INCUMBENT CORP"},"awardeeUEIInformation":{"uniqueEntityId":"INC123INC456","cageCode":"7XYZ1"}}}
+}]}`
+
+func TestSearchContractAwards(t *testing.T) {
+	var gotPath, gotSol string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotSol = r.URL.Query().Get("solicitationID")
+		_, _ = w.Write([]byte(awardsFixture))
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, "", "k", srv.Client())
+	if _, err := c.SearchContractAwards(context.Background(), AwardParams{}); err == nil {
+		t.Fatal("expected error without filters")
+	}
+	page, err := c.SearchContractAwards(context.Background(), AwardParams{SolicitationNumber: "W912DY19R0001"})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if gotPath != "/contract-awards/v1/search" || gotSol != "W912DY19R0001" {
+		t.Fatalf("unexpected request path=%q solicitation=%q", gotPath, gotSol)
+	}
+	if page.TotalRecords != 1 || len(page.Awards) != 1 {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+	a := page.Awards[0]
+	if a.PIID != "W912DY20C0001" || a.AgencyCode != "9700" || a.NAICS != "541330" || a.Vendor.UEI != "INC123INC456" {
+		t.Fatalf("unexpected award: %+v", a)
+	}
+	if a.ActionObligation != 125000.50 || a.TotalObligated != 2500000 || a.BaseAndAllOptions != 9000000 {
+		t.Fatalf("unexpected amounts: %+v", a)
+	}
+	if a.PeriodEnd.Year() != 2025 || a.PeriodUltimateEnd.Year() != 2027 {
+		t.Fatalf("unexpected period of performance: %+v", a)
+	}
+}
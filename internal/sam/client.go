@@ -8,52 +8,165 @@ import (
     "fmt"
     "net/http"
     "net/url"
+    "strconv"
     "strings"
     "time"
 )
 
+const (
+    // DefaultBaseURL is the production SAM.gov API host.
+    DefaultBaseURL = "https://api.sam.gov"
+    // AlphaBaseURL is the SAM.gov alpha (sandbox) API host; it requires alpha API keys.
+    AlphaBaseURL = "https://api-alpha.sam.gov"
+    // DefaultVersion is the Opportunities API version used when none is configured.
+    DefaultVersion = "v2"
+)
+
+// Environment names understood by ResolveEnvironment.
+const (
+    EnvProd   = "prod"
+    EnvAlpha  = "alpha"
+    EnvCustom = "custom"
+)
+
+// ResolveEnvironment maps an environment name and optional base URL override to the
+// effective environment name and API host. An empty env defaults to prod, or to custom
+// when only an override is given (e.g. a local stand-in). The name is validated even when
+// an override is set, and custom requires one.
+func ResolveEnvironment(env, baseURL string) (string, string, error) {
+    baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+    switch strings.ToLower(strings.TrimSpace(env)) {
+    case "":
+        if baseURL != "" {
+            return EnvCustom, baseURL, nil
+        }
+        return EnvProd, DefaultBaseURL, nil
+    case EnvProd, "production":
+        env = EnvProd
+        if baseURL == "" {
+            baseURL = DefaultBaseURL
+        }
+    case EnvAlpha, "sandbox":
+        env = EnvAlpha
+        if baseURL == "" {
+            baseURL = AlphaBaseURL
+        }
+    case EnvCustom:
+        if baseURL == "" {
+            return "", "", errors.New("sam environment custom needs a base url")
+        }
+        env = EnvCustom
+    default:
+        return "", "", fmt.Errorf("unknown sam environment %q (want prod, alpha or custom)", env)
+    }
+    return env, baseURL, nil
+}
+
 // Client is a minimal HTTP client for SAM.gov opportunities search.
 type Client struct {
     BaseURL string
+    Version string
     APIKey  string
     HTTP    *http.Client
 }
 
-// New returns a new client. If httpClient is nil, a default with 15s timeout is used.
-func New(baseURL, apiKey string, httpClient *http.Client) *Client {
+// New returns a new client for the API host at baseURL (e.g. https://api.sam.gov).
+// Empty baseURL and version fall back to DefaultBaseURL and DefaultVersion.
+// If httpClient is nil, a default with 15s timeout is used.
+func New(baseURL, version, apiKey string, httpClient *http.Client) *Client {
+    if baseURL == "" { baseURL = DefaultBaseURL }
+    if version == "" { version = DefaultVersion }
     if httpClient == nil {
         httpClient = &http.Client{Timeout: 15 * time.Second}
     }
-    return &Client{BaseURL: strings.TrimRight(baseURL, "/"), APIKey: apiKey, HTTP: httpClient}
+    return &Client{BaseURL: strings.TrimRight(baseURL, "/"), Version: version, APIKey: apiKey, HTTP: httpClient}
 }
 
 // SearchParams defines supported search filters. All are optional except Days or explicit date filters.
 type SearchParams struct {
-    Q          string
-    NAICS      []string
-    Days       int
-    Limit      int
-    NoticeType string
-    Org        string
+    Q          string   `json:"q,omitempty"`
+    NAICS      []string `json:"naics,omitempty"`
+    Days       int      `json:"days,omitempty"`
+    Limit      int      `json:"limit,omitempty"`
+    NoticeType string   `json:"noticeType,omitempty"`
+    Org        string   `json:"organization,omitempty"`
+    // OrgCode is a resolved Federal Hierarchy organization id; it takes precedence over Org.
+    OrgCode    string   `json:"organizationCode,omitempty"`
+    // NoticeID restricts the search to a single notice.
+    NoticeID   string   `json:"noticeId,omitempty"`
 }
 
 // Opportunity is a small normalized view of an opportunity.
 type Opportunity struct {
+    NoticeID           string `json:"noticeId,omitempty"`
+    SolicitationNumber string `json:"solicitationNumber,omitempty"`
     Title    string    `json:"title"`
     Agency   string    `json:"agency"`
     Modified time.Time `json:"modified"`
     URL      string    `json:"url"`
+    NAICS      string `json:"naics,omitempty"`
+    NAICSTitle string `json:"naicsTitle,omitempty"`
+    PSC        string `json:"psc,omitempty"`
+    PSCTitle   string `json:"pscTitle,omitempty"`
+    Award    *Award    `json:"award,omitempty"`
+    PlaceOfPerformance *Place `json:"placeOfPerformance,omitempty"`
+    // WageDeterminationsURL links to the wage determinations for the place of performance.
+    WageDeterminationsURL string `json:"wageDeterminationsUrl,omitempty"`
+    Type             string     `json:"type,omitempty"`
+    Posted           time.Time  `json:"posted"`
+    ResponseDeadline *time.Time `json:"responseDeadline,omitempty"`
+    // ResponseDeadlineDateOnly is set when SAM.gov gave the response deadline as a date
+    // without a time of day.
+    ResponseDeadlineDateOnly bool `json:"responseDeadlineDateOnly,omitempty"`
+    // DescriptionURL is where SAM.gov serves the notice description (see NoticeDescription);
+    // Description holds its text once fetched.
+    DescriptionURL string `json:"descriptionUrl,omitempty"`
+    Description    string `json:"description,omitempty"`
+    // Attachments are the download links of the notice's attachments (resourceLinks).
+    Attachments []string `json:"attachments,omitempty"`
     Raw      any       `json:"raw,omitempty"`
 }
 
+// Place is an opportunity's place of performance.
+type Place struct {
+    City    string `json:"city,omitempty"`
+    State   string `json:"state,omitempty"`
+    Zip     string `json:"zip,omitempty"`
+    Country string `json:"country,omitempty"`
+}
+
+// Award is the award block present on award notices.
+type Award struct {
+    Number  string   `json:"number,omitempty"`
+    Date    string   `json:"date,omitempty"`
+    Amount  string   `json:"amount,omitempty"`
+    Awardee *Awardee `json:"awardee,omitempty"`
+}
+
+// Awardee identifies the vendor on an award notice. Excluded is only set when an
+// exclusion check was requested.
+type Awardee struct {
+    Name     string `json:"name"`
+    UEI      string `json:"uei,omitempty"`
+    Excluded *bool  `json:"excluded,omitempty"`
+}
+
 // Search performs a search against the opportunities API and returns normalized results.
 // Note: The SAM.gov API parameters and fields may evolve; this method aims to be tolerant.
 func (c *Client) Search(ctx context.Context, p SearchParams) ([]Opportunity, error) {
+    reqURL, err := c.buildSearchURL(p)
+    if err != nil { return nil, err }
+    body, err := c.getJSON(ctx, reqURL)
+    if err != nil { return nil, err }
+    items := extractItems(body)
+    return normalize(items), nil
+}
+
+// getJSON issues an authenticated GET for reqURL and decodes the JSON response.
+func (c *Client) getJSON(ctx context.Context, reqURL string) (any, error) {
     if c.APIKey == "" {
         return nil, errors.New("sam api key missing")
     }
-    reqURL, err := c.buildSearchURL(p)
-    if err != nil { return nil, err }
     req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
     if err != nil { return nil, err }
     resp, err := c.HTTP.Do(req)
@@ -62,10 +175,7 @@ func (c *Client) Search(ctx context.Context, p SearchParams) ([]Opportunity, err
     if resp.StatusCode < 200 || resp.StatusCode >= 300 {
         return nil, fmt.Errorf("sam api status %d", resp.StatusCode)
     }
-    body, err := decodeJSON(resp)
-    if err != nil { return nil, err }
-    items := extractItems(body)
-    return normalize(items), nil
+    return decodeJSON(resp)
 }
 
 func getString(m map[string]any, key string) string {
@@ -79,17 +189,63 @@ func getString(m map[string]any, key string) string {
     return ""
 }
 
+// endpoint resolves an API path (e.g. /opportunities/v2/search) against the client's base URL.
+func (c *Client) endpoint(path string) (*url.URL, error) {
+    u, err := url.Parse(c.BaseURL + path)
+    if err != nil { return nil, fmt.Errorf("invalid base url: %w", err) }
+    return u, nil
+}
+
+func getMap(m map[string]any, key string) map[string]any {
+    if m == nil { return nil }
+    v, _ := m[key].(map[string]any)
+    return v
+}
+
+func getSlice(m map[string]any, key string) []any {
+    if m == nil { return nil }
+    v, _ := m[key].([]any)
+    return v
+}
+
+func getFloat(m map[string]any, key string) float64 {
+    if m == nil { return 0 }
+    switch t := m[key].(type) {
+    case float64:
+        return t
+    case string:
+        if f, err := strconv.ParseFloat(strings.ReplaceAll(t, ",", ""), 64); err == nil { return f }
+    }
+    return 0
+}
+
+func getInt(m map[string]any, key string) int {
+    if m == nil { return 0 }
+    switch t := m[key].(type) {
+    case float64:
+        return int(t)
+    case string:
+        if i, err := strconv.Atoi(t); err == nil { return i }
+    }
+    return 0
+}
+
 // buildSearchURL composes the search URL with query params.
 func (c *Client) buildSearchURL(p SearchParams) (string, error) {
-    u, err := url.Parse(c.BaseURL)
-    if err != nil { return "", fmt.Errorf("invalid base url: %w", err) }
+    u, err := c.endpoint("/opportunities/" + c.Version + "/search")
+    if err != nil { return "", err }
     q := u.Query()
     q.Set("api_key", c.APIKey)
     if p.Q != "" { q.Set("q", p.Q) }
     if len(p.NAICS) > 0 { q.Set("naics", strings.Join(p.NAICS, ",")) }
     if p.Limit > 0 { q.Set("limit", fmt.Sprintf("%d", p.Limit)) }
     if p.NoticeType != "" { q.Set("notice_type", p.NoticeType) }
-    if p.Org != "" { q.Set("organization", p.Org) }
+    if p.NoticeID != "" { q.Set("noticeid", p.NoticeID) }
+    if p.OrgCode != "" {
+        q.Set("organizationCode", p.OrgCode)
+    } else if p.Org != "" {
+        q.Set("organizationName", p.Org)
+    }
     if p.Days > 0 {
         from := time.Now().AddDate(0, 0, -p.Days).Format("2006-01-02")
         q.Set("date_modified_from", from)
@@ -132,19 +288,75 @@ func normalize(items []any) []Opportunity {
         agency := firstNonEmpty(getString(m, "agency"), getString(m, "department"))
         urlStr := firstNonEmpty(getString(m, "uiLink"), getString(m, "url"))
         mod := parseTime(firstNonEmpty(getString(m, "lastModifiedDate"), getString(m, "dateModified")))
-        out = append(out, Opportunity{Title: title, Agency: agency, Modified: mod, URL: urlStr, Raw: it})
+        o := Opportunity{NoticeID: getString(m, "noticeId"), SolicitationNumber: getString(m, "solicitationNumber"), Title: title, Agency: agency, Modified: mod, URL: urlStr, Award: normalizeAward(getMap(m, "award")), Raw: it}
+        o.NAICS = firstNonEmpty(getString(m, "naicsCode"), getString(m, "naics"))
+        o.PSC = firstNonEmpty(getString(m, "classificationCode"), getString(m, "psc"))
+        o.PlaceOfPerformance = normalizePlace(getMap(m, "placeOfPerformance"))
+        if pop := o.PlaceOfPerformance; pop != nil && pop.State != "" && (pop.Country == "" || pop.Country == "USA") {
+            o.WageDeterminationsURL = WageDeterminationSearchURL(pop.State, "")
+        }
+        o.Type = firstNonEmpty(getString(m, "type"), getString(m, "baseType"))
+        o.Posted = parseTime(getString(m, "postedDate"))
+        if t, hasTime := parseDateTime(firstNonEmpty(getString(m, "responseDeadLine"), getString(m, "responseDeadline"))); !t.IsZero() {
+            o.ResponseDeadline, o.ResponseDeadlineDateOnly = &t, !hasTime
+        }
+        // Search results carry a link to the description rather than its text.
+        if d := getString(m, "description"); strings.HasPrefix(d, "http://") || strings.HasPrefix(d, "https://") {
+            o.DescriptionURL = d
+        } else {
+            o.Description = PlainText(d)
+        }
+        for _, l := range getSlice(m, "resourceLinks") {
+            if s, ok := l.(string); ok && s != "" {
+                o.Attachments = append(o.Attachments, s)
+            }
+        }
+        out = append(out, o)
     }
     return out
 }
 
+// normalizePlace reads a placeOfPerformance block whose parts are either strings or {code, name} objects.
+func normalizePlace(m map[string]any) *Place {
+    if m == nil { return nil }
+    code := func(key string) string {
+        if v := getString(m, key); v != "" { return v }
+        sub := getMap(m, key)
+        return firstNonEmpty(getString(sub, "code"), getString(sub, "name"))
+    }
+    city := firstNonEmpty(getString(m, "city"), getString(getMap(m, "city"), "name"))
+    p := &Place{City: city, State: code("state"), Zip: getString(m, "zip"), Country: code("country")}
+    if *p == (Place{}) { return nil }
+    return p
+}
+
+func normalizeAward(m map[string]any) *Award {
+    if m == nil { return nil }
+    a := &Award{Number: getString(m, "number"), Date: getString(m, "date"), Amount: getString(m, "amount")}
+    if am := getMap(m, "awardee"); am != nil {
+        a.Awardee = &Awardee{Name: getString(am, "name"), UEI: firstNonEmpty(getString(am, "ueiSAM"), getString(am, "uei"))}
+    }
+    return a
+}
+
 func firstNonEmpty(vals ...string) string {
     for _, v := range vals { if v != "" { return v } }
     return ""
 }
 
 func parseTime(s string) time.Time {
-    if s == "" { return time.Time{} }
-    if t, err := time.Parse(time.RFC3339, s); err == nil { return t }
-    if t, err := time.Parse("2006-01-02", s); err == nil { return t }
-    return time.Time{}
+    t, _ := parseDateTime(s)
+    return t
+}
+
+// parseDateTime is parseTime also reporting whether s had a time of day; dates alone parse
+// as midnight UTC.
+func parseDateTime(s string) (time.Time, bool) {
+    if s == "" { return time.Time{}, false }
+    if t, err := time.Parse(time.RFC3339, s); err == nil { return t, true }
+    if t, err := time.Parse("2006-01-02", s); err == nil { return t, false }
+    if t, err := time.Parse("2006-01-02 15:04:05", s); err == nil { return t, true }
+    if t, err := time.Parse("2006-01-02T15:04:05-0700", s); err == nil { return t, true }
+    if t, err := time.Parse("01/02/2006", s); err == nil { return t, false }
+    return time.Time{}, false
 }
@@ -0,0 +1,64 @@
+package sam
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSearchUsesBaseURLAndVersion(t *testing.T) {
+	var gotPath, gotKey string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotKey = r.URL.Query().Get("api_key")
+		_, _ = w.Write([]byte(`{"opportunitiesData":[{"title":"A","noticeTitle":"ignored","department":"GSA","uiLink":"https://sam.gov/opp/1"}]}`))
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL+"/", "v3", "k", srv.Client())
+	res, err := c.Search(context.Background(), SearchParams{Q: "x"})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if gotPath != "/opportunities/v3/search" {
+		t.Fatalf("unexpected path %q", gotPath)
+	}
+	if gotKey != "k" {
+		t.Fatalf("api key not sent, got %q", gotKey)
+	}
+	if len(res) != 1 || res[0].Title != "A" || res[0].Agency != "GSA" || res[0].URL != "https://sam.gov/opp/1" {
+		t.Fatalf("unexpected results: %+v", res)
+	}
+}
+
+func TestNewDefaults(t *testing.T) {
+	c := New("", "", "k", nil)
+	if c.BaseURL != DefaultBaseURL || c.Version != DefaultVersion || c.HTTP == nil {
+		t.Fatalf("unexpected defaults: %+v", c)
+	}
+}
+
+func TestResolveEnvironment(t *testing.T) {
+	cases := []struct {
+		env, base, wantEnv, wantURL string
+	}{
+		{"", "", EnvProd, DefaultBaseURL},
+		{"alpha", "", EnvAlpha, AlphaBaseURL},
+		{"PROD", "", EnvProd, DefaultBaseURL},
+		{"", "http://localhost:9000/", EnvCustom, "http://localhost:9000"},
+		{"alpha", "http://localhost:9000", EnvAlpha, "http://localhost:9000"},
+		{"custom", "http://localhost:9000", EnvCustom, "http://localhost:9000"},
+	}
+	for _, tc := range cases {
+		env, u, err := ResolveEnvironment(tc.env, tc.base)
+		if err != nil || env != tc.wantEnv || u != tc.wantURL {
+			t.Errorf("ResolveEnvironment(%q, %q) = %q, %q, %v", tc.env, tc.base, env, u, err)
+		}
+	}
+	for _, tc := range [][2]string{{"staging", ""}, {"staging", "http://localhost:9000"}, {"custom", ""}} {
+		if _, _, err := ResolveEnvironment(tc[0], tc[1]); err == nil {
+			t.Errorf("ResolveEnvironment(%q, %q): expected error", tc[0], tc[1])
+		}
+	}
+}
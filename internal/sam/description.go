@@ -0,0 +1,89 @@
+package sam
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// maxDescriptionBytes bounds the description body read from SAM.gov.
+const maxDescriptionBytes = 1 << 20
+
+// NoticeDescription fetches the text of an opportunity's description from its
+// DescriptionURL. The request is sent to the client's API host, whatever host the link
+// names, so the API key never leaves the configured SAM.gov environment. HTML is reduced
+// to plain text.
+func (c *Client) NoticeDescription(ctx context.Context, descriptionURL string) (string, error) {
+	if c.APIKey == "" {
+		return "", errors.New("sam api key missing")
+	}
+	link, err := url.Parse(descriptionURL)
+	if err != nil || link.Path == "" {
+		return "", errors.New("invalid description url")
+	}
+	u, err := c.endpoint(link.Path)
+	if err != nil {
+		return "", err
+	}
+	q := link.Query()
+	q.Set("api_key", c.APIKey)
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", nil // notices without a description
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", errors.New("sam api status " + resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDescriptionBytes))
+	if err != nil {
+		return "", err
+	}
+	var doc struct {
+		Description *string `json:"description"`
+	}
+	if err := json.Unmarshal(body, &doc); err == nil && doc.Description != nil {
+		return PlainText(*doc.Description), nil
+	}
+	return PlainText(string(body)), nil
+}
+
+var (
+	blockTags  = regexp.MustCompile(`(?i)<\s*(br|/p|/div|/ul|/ol|/h[1-6]|/tr)\s*/?\s*>`)
+	listItems  = regexp.MustCompile(`(?i)<\s*li[^>]*>`)
+	anyTag     = regexp.MustCompile(`<[^>]*>`)
+	blankLines = regexp.MustCompile(`\n{3,}`)
+	spaces     = regexp.MustCompile(`[ \t\r\f\v]+`)
+)
+
+// PlainText reduces an HTML description to text with one paragraph or list item per line.
+func PlainText(s string) string {
+	if !strings.Contains(s, "<") && !strings.Contains(s, "&") {
+		return strings.TrimSpace(s)
+	}
+	s = blockTags.ReplaceAllString(s, "\n")
+	s = listItems.ReplaceAllString(s, "\n- ")
+	s = anyTag.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	s = strings.ReplaceAll(s, "\u00a0", " ")
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimSpace(spaces.ReplaceAllString(l, " "))
+	}
+	return strings.TrimSpace(blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
@@ -0,0 +1,64 @@
+package sam
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSearchNoticeFields(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"opportunitiesData":[{"noticeId":"n1","title":"A","type":"Solicitation","postedDate":"2025-03-01",
+			"responseDeadLine":"2025-04-01T14:00:00-04:00","description":"https://api.sam.gov/prod/opportunities/v1/noticedesc?noticeid=n1",
+			"resourceLinks":["https://sam.gov/api/prod/opps/v3/opportunities/resources/files/a/download"]},
+			{"noticeId":"n2","responseDeadLine":"2025-04-15"},{"noticeId":"n3","responseDeadLine":"2025-04-15T00:00:00Z"}]}`))
+	}))
+	defer srv.Close()
+
+	res, err := New(srv.URL, "", "k", srv.Client()).Search(context.Background(), SearchParams{Days: 30})
+	if err != nil || len(res) != 3 {
+		t.Fatalf("search: %v %+v", err, res)
+	}
+	o := res[0]
+	if o.Type != "Solicitation" || o.Posted.Format("2006-01-02") != "2025-03-01" || len(o.Attachments) != 1 {
+		t.Fatalf("unexpected notice fields: %+v", o)
+	}
+	if o.ResponseDeadline == nil || o.ResponseDeadline.UTC().Format("2006-01-02T15:04") != "2025-04-01T18:00" {
+		t.Fatalf("unexpected deadline: %v", o.ResponseDeadline)
+	}
+	if o.ResponseDeadlineDateOnly || !res[1].ResponseDeadlineDateOnly || res[2].ResponseDeadlineDateOnly {
+		t.Fatalf("date-only deadlines misreported: %v %v %v", o.ResponseDeadlineDateOnly, res[1].ResponseDeadlineDateOnly, res[2].ResponseDeadlineDateOnly)
+	}
+	if o.DescriptionURL == "" || o.Description != "" {
+		t.Fatalf("description link not recognised: %+v", o)
+	}
+}
+
+func TestNoticeDescription(t *testing.T) {
+	var gotPath, gotNotice, gotKey string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotNotice, gotKey = r.URL.Path, r.URL.Query().Get("noticeid"), r.URL.Query().Get("api_key")
+		if gotNotice == "missing" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"description":"<p>Scope&nbsp;of work.</p><ul><li>Item one</li><li>Item two</li></ul>"}`))
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, "", "k", srv.Client())
+	text, err := c.NoticeDescription(context.Background(), "https://api.sam.gov/prod/opportunities/v1/noticedesc?noticeid=n1")
+	if err != nil {
+		t.Fatalf("description: %v", err)
+	}
+	if gotPath != "/prod/opportunities/v1/noticedesc" || gotNotice != "n1" || gotKey != "k" {
+		t.Fatalf("unexpected request: path %q notice %q key %q", gotPath, gotNotice, gotKey)
+	}
+	if want := "Scope of work.\n\n- Item one\n- Item two"; text != want {
+		t.Fatalf("got %q, want %q", text, want)
+	}
+	if text, err := c.NoticeDescription(context.Background(), "https://api.sam.gov/prod/opportunities/v1/noticedesc?noticeid=missing"); err != nil || text != "" {
+		t.Fatalf("missing description: %q %v", text, err)
+	}
+}
@@ -0,0 +1,194 @@
+package sam
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EntityVersion is the Entity Management API version used for registration lookups.
+const EntityVersion = "v3"
+
+// maxEntityPageSize is the largest page size the Entity Management API accepts.
+const maxEntityPageSize = 10
+
+// EntityParams selects registrations by UEI, CAGE code or legal business name.
+// At least one selector is required; Page is zero-based and Size is capped at 10.
+type EntityParams struct {
+	UEI  string
+	CAGE string
+	Name string
+	Page int
+	Size int
+}
+
+// Entity is a normalized view of a SAM.gov entity registration.
+type Entity struct {
+	UEI                string             `json:"uei"`
+	CAGE               string             `json:"cage,omitempty"`
+	LegalName          string             `json:"legalName"`
+	DBAName            string             `json:"dbaName,omitempty"`
+	RegistrationStatus string             `json:"registrationStatus"`
+	RegistrationDate   time.Time          `json:"registrationDate"`
+	ExpirationDate     time.Time          `json:"expirationDate"`
+	LastUpdated        time.Time          `json:"lastUpdated"`
+	PrimaryNAICS       string             `json:"primaryNaics,omitempty"`
+	NAICS              []EntityNAICS      `json:"naics,omitempty"`
+	BusinessTypes      []BusinessType     `json:"businessTypes,omitempty"`
+	SBACertifications  []SBACertification `json:"sbaCertifications,omitempty"`
+	PointsOfContact    []PointOfContact   `json:"pointsOfContact,omitempty"`
+	// Excluded is only set when an exclusion check was requested.
+	Excluded *bool `json:"excluded,omitempty"`
+}
+
+// EntityNAICS is a NAICS code an entity has registered for, with its small-business status.
+type EntityNAICS struct {
+	Code          string `json:"code"`
+	Description   string `json:"description,omitempty"`
+	SmallBusiness bool   `json:"smallBusiness"`
+}
+
+// BusinessType is a self-certified business type (e.g. "2X" For Profit Organization).
+type BusinessType struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+}
+
+// SBACertification is an SBA-issued certification such as 8(a) or HUBZone.
+type SBACertification struct {
+	Code        string    `json:"code"`
+	Description string    `json:"description"`
+	EntryDate   time.Time `json:"entryDate"`
+	ExitDate    time.Time `json:"exitDate"`
+}
+
+// PointOfContact is a public registration contact; Role names the POC section it came from.
+type PointOfContact struct {
+	Role      string `json:"role"`
+	FirstName string `json:"firstName,omitempty"`
+	LastName  string `json:"lastName,omitempty"`
+	Title     string `json:"title,omitempty"`
+	Email     string `json:"email,omitempty"`
+	Phone     string `json:"phone,omitempty"`
+}
+
+// EntityPage is one page of entity lookup results.
+type EntityPage struct {
+	TotalRecords int      `json:"totalRecords"`
+	Page         int      `json:"page"`
+	Size         int      `json:"size"`
+	Entities     []Entity `json:"entities"`
+}
+
+// pocRoles maps the Entity API's POC sections to the roles reported in PointOfContact.
+var pocRoles = []struct{ key, role string }{
+	{"governmentBusinessPOC", "government_business"},
+	{"electronicBusinessPOC", "electronic_business"},
+	{"pastPerformancePOC", "past_performance"},
+}
+
+// LookupEntity queries the Entity Management API for registrations matching p.
+func (c *Client) LookupEntity(ctx context.Context, p EntityParams) (*EntityPage, error) {
+	if p.UEI == "" && p.CAGE == "" && p.Name == "" {
+		return nil, errors.New("one of uei, cage or name is required")
+	}
+	if p.Size <= 0 || p.Size > maxEntityPageSize {
+		p.Size = maxEntityPageSize
+	}
+	if p.Page < 0 {
+		p.Page = 0
+	}
+	u, err := c.endpoint("/entity-information/" + EntityVersion + "/entities")
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	q.Set("api_key", c.APIKey)
+	q.Set("includeSections", "entityRegistration,coreData,assertions,pointsOfContact")
+	if p.UEI != "" {
+		q.Set("ueiSAM", p.UEI)
+	}
+	if p.CAGE != "" {
+		q.Set("cageCode", p.CAGE)
+	}
+	if p.Name != "" {
+		q.Set("legalBusinessName", p.Name)
+	}
+	q.Set("page", strconv.Itoa(p.Page))
+	q.Set("size", strconv.Itoa(p.Size))
+	u.RawQuery = q.Encode()
+
+	body, err := c.getJSON(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+	m, _ := body.(map[string]any)
+	page := &EntityPage{TotalRecords: getInt(m, "totalRecords"), Page: p.Page, Size: p.Size}
+	for _, it := range getSlice(m, "entityData") {
+		em, _ := it.(map[string]any)
+		page.Entities = append(page.Entities, normalizeEntity(em))
+	}
+	return page, nil
+}
+
+// normalizeEntity flattens an entityData item into an Entity.
+func normalizeEntity(m map[string]any) Entity {
+	reg := getMap(m, "entityRegistration")
+	core := getMap(m, "coreData")
+	bt := getMap(core, "businessTypes")
+	goods := getMap(getMap(m, "assertions"), "goodsAndServices")
+
+	e := Entity{
+		UEI:                getString(reg, "ueiSAM"),
+		CAGE:               getString(reg, "cageCode"),
+		LegalName:          getString(reg, "legalBusinessName"),
+		DBAName:            getString(reg, "dbaName"),
+		RegistrationStatus: getString(reg, "registrationStatus"),
+		RegistrationDate:   parseTime(getString(reg, "registrationDate")),
+		ExpirationDate:     parseTime(getString(reg, "registrationExpirationDate")),
+		LastUpdated:        parseTime(getString(reg, "lastUpdateDate")),
+		PrimaryNAICS:       getString(goods, "primaryNaics"),
+	}
+	for _, it := range getSlice(goods, "naicsList") {
+		n, _ := it.(map[string]any)
+		e.NAICS = append(e.NAICS, EntityNAICS{
+			Code:          getString(n, "naicsCode"),
+			Description:   getString(n, "naicsDescription"),
+			SmallBusiness: strings.EqualFold(getString(n, "sbaSmallBusiness"), "Y"),
+		})
+	}
+	for _, it := range getSlice(bt, "businessTypeList") {
+		b, _ := it.(map[string]any)
+		e.BusinessTypes = append(e.BusinessTypes, BusinessType{Code: getString(b, "businessTypeCode"), Description: getString(b, "businessTypeDesc")})
+	}
+	for _, it := range getSlice(bt, "sbaBusinessTypeList") {
+		b, _ := it.(map[string]any)
+		if getString(b, "sbaBusinessTypeCode") == "" {
+			continue
+		}
+		e.SBACertifications = append(e.SBACertifications, SBACertification{
+			Code:        getString(b, "sbaBusinessTypeCode"),
+			Description: getString(b, "sbaBusinessTypeDesc"),
+			EntryDate:   parseTime(getString(b, "certificationEntryDate")),
+			ExitDate:    parseTime(getString(b, "certificationExitDate")),
+		})
+	}
+	pocs := getMap(m, "pointsOfContact")
+	for _, r := range pocRoles {
+		pm := getMap(pocs, r.key)
+		if pm == nil || getString(pm, "firstName")+getString(pm, "lastName") == "" {
+			continue
+		}
+		e.PointsOfContact = append(e.PointsOfContact, PointOfContact{
+			Role:      r.role,
+			FirstName: getString(pm, "firstName"),
+			LastName:  getString(pm, "lastName"),
+			Title:     getString(pm, "title"),
+			Email:     getString(pm, "email"),
+			Phone:     getString(pm, "usPhone"),
+		})
+	}
+	return e
+}
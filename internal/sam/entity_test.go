@@ -0,0 +1,55 @@
+package sam
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const entityFixture = `{"totalRecords":1,"entityData":[{
+  "entityRegistration":{"ueiSAM":"ABC123DEF456","cageCode":"1ABC2","legalBusinessName":"ACME LLC","registrationStatus":"Active","registrationExpirationDate":"2026-03-05"},
+  "coreData":{"businessTypes":{"businessTypeList":[{"businessTypeCode":"2X","businessTypeDesc":"For Profit Organization"}],
+    "sbaBusinessTypeList":[{"sbaBusinessTypeCode":"A6","sbaBusinessTypeDesc":"SBA Certified 8A Program Participant","certificationEntryDate":"2021-01-01","certificationExitDate":"2030-01-01"}]}},
+  "assertions":{"goodsAndServices":{"primaryNaics":"541511","naicsList":[{"naicsCode":"541511","naicsDescription":"Custom Computer Programming Services","sbaSmallBusiness":"Y"}]}},
+  "pointsOfContact":{"governmentBusinessPOC":{"firstName":"Jane","lastName":"Doe","title":"CEO"},"pastPerformancePOC":{}}
+}]}`
+
+func TestLookupEntity(t *testing.T) {
+	var gotPath, gotUEI, gotSize string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotUEI = r.URL.Query().Get("ueiSAM")
+		gotSize = r.URL.Query().Get("size")
+		_, _ = w.Write([]byte(entityFixture))
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, "", "k", srv.Client())
+	if _, err := c.LookupEntity(context.Background(), EntityParams{}); err == nil {
+		t.Fatal("expected error without selector")
+	}
+	page, err := c.LookupEntity(context.Background(), EntityParams{UEI: "ABC123DEF456", Size: 50})
+	if err != nil {
+		t.Fatalf("lookup: %v", err)
+	}
+	if gotPath != "/entity-information/v3/entities" || gotUEI != "ABC123DEF456" || gotSize != "10" {
+		t.Fatalf("unexpected request path=%q uei=%q size=%q", gotPath, gotUEI, gotSize)
+	}
+	if page.TotalRecords != 1 || len(page.Entities) != 1 {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+	e := page.Entities[0]
+	if e.CAGE != "1ABC2" || e.ExpirationDate.Year() != 2026 || e.PrimaryNAICS != "541511" {
+		t.Fatalf("unexpected entity: %+v", e)
+	}
+	if len(e.NAICS) != 1 || !e.NAICS[0].SmallBusiness {
+		t.Fatalf("unexpected naics: %+v", e.NAICS)
+	}
+	if len(e.BusinessTypes) != 1 || len(e.SBACertifications) != 1 || e.SBACertifications[0].Code != "A6" {
+		t.Fatalf("unexpected business types: %+v %+v", e.BusinessTypes, e.SBACertifications)
+	}
+	if len(e.PointsOfContact) != 1 || e.PointsOfContact[0].Role != "government_business" {
+		t.Fatalf("unexpected points of contact: %+v", e.PointsOfContact)
+	}
+}
@@ -0,0 +1,124 @@
+package sam
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ExclusionsVersion is the Exclusions API version used for debarment checks.
+const ExclusionsVersion = "v4"
+
+// ExclusionQuery identifies one entity to check, by UEI or by name.
+type ExclusionQuery struct {
+	UEI  string `json:"uei,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+// Exclusion is a normalized SAM.gov exclusion (debarment/suspension) record.
+type Exclusion struct {
+	Name            string    `json:"name"`
+	UEI             string    `json:"uei,omitempty"`
+	CAGE            string    `json:"cage,omitempty"`
+	Classification  string    `json:"classification,omitempty"`
+	Type            string    `json:"type"`
+	Program         string    `json:"program,omitempty"`
+	AgencyCode      string    `json:"agencyCode,omitempty"`
+	AgencyName      string    `json:"agencyName,omitempty"`
+	ActiveDate      time.Time `json:"activeDate"`
+	TerminationDate time.Time `json:"terminationDate"`
+	TerminationType string    `json:"terminationType,omitempty"`
+	RecordStatus    string    `json:"recordStatus"`
+}
+
+// ExclusionResult reports the active exclusions found for one query.
+type ExclusionResult struct {
+	Query      ExclusionQuery `json:"query"`
+	Excluded   bool           `json:"excluded"`
+	Exclusions []Exclusion    `json:"exclusions"`
+}
+
+// SearchExclusions returns the active exclusion records matching a UEI or name.
+func (c *Client) SearchExclusions(ctx context.Context, q ExclusionQuery) ([]Exclusion, error) {
+	if q.UEI == "" && q.Name == "" {
+		return nil, errors.New("uei or name is required")
+	}
+	u, err := c.endpoint("/entity-information/" + ExclusionsVersion + "/exclusions")
+	if err != nil {
+		return nil, err
+	}
+	v := u.Query()
+	v.Set("api_key", c.APIKey)
+	if q.UEI != "" {
+		v.Set("ueiSAM", q.UEI)
+	} else {
+		v.Set("exclusionName", q.Name)
+	}
+	u.RawQuery = v.Encode()
+
+	body, err := c.getJSON(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+	m, _ := body.(map[string]any)
+	out := []Exclusion{}
+	for _, it := range getSlice(m, "excludedEntity") {
+		em, _ := it.(map[string]any)
+		for _, ex := range normalizeExclusion(em) {
+			if strings.EqualFold(ex.RecordStatus, "Active") {
+				out = append(out, ex)
+			}
+		}
+	}
+	return out, nil
+}
+
+// CheckExclusions runs SearchExclusions for each query, stopping at the first API error.
+func (c *Client) CheckExclusions(ctx context.Context, queries []ExclusionQuery) ([]ExclusionResult, error) {
+	out := make([]ExclusionResult, 0, len(queries))
+	for _, q := range queries {
+		ex, err := c.SearchExclusions(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, ExclusionResult{Query: q, Excluded: len(ex) > 0, Exclusions: ex})
+	}
+	return out, nil
+}
+
+// normalizeExclusion flattens an excludedEntity item; one record is produced per action.
+func normalizeExclusion(m map[string]any) []Exclusion {
+	details := getMap(m, "exclusionDetails")
+	ident := getMap(m, "exclusionIdentification")
+	name := getString(ident, "name")
+	if name == "" {
+		name = strings.TrimSpace(getString(ident, "firstName") + " " + getString(ident, "lastName"))
+	}
+	base := Exclusion{
+		Name:           name,
+		UEI:            getString(ident, "ueiSAM"),
+		CAGE:           getString(ident, "cageCode"),
+		Classification: getString(details, "classificationType"),
+		Type:           getString(details, "exclusionType"),
+		Program:        getString(details, "exclusionProgram"),
+		AgencyCode:     getString(details, "excludingAgencyCode"),
+		AgencyName:     getString(details, "excludingAgencyName"),
+	}
+	actions := getSlice(getMap(m, "exclusionActions"), "listOfActions")
+	if len(actions) == 0 {
+		base.RecordStatus = "Active"
+		return []Exclusion{base}
+	}
+	out := make([]Exclusion, 0, len(actions))
+	for _, it := range actions {
+		am, _ := it.(map[string]any)
+		ex := base
+		ex.ActiveDate = parseTime(getString(am, "activateDate"))
+		ex.TerminationDate = parseTime(getString(am, "terminationDate"))
+		ex.TerminationType = getString(am, "terminationType")
+		ex.RecordStatus = getString(am, "recordStatus")
+		out = append(out, ex)
+	}
+	return out
+}
@@ -0,0 +1,43 @@
+package sam
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const exclusionsFixture = `{"totalRecords":1,"excludedEntity":[{
+  "exclusionDetails":{"classificationType":"Firm","exclusionType":"Ineligible (Proceedings Completed)","exclusionProgram":"Reciprocal","excludingAgencyCode":"HHS","excludingAgencyName":"Health and Human Services"},
+  "exclusionIdentification":{"ueiSAM":"BAD123BAD456","name":"Bad Actor Inc"},
+  "exclusionActions":{"listOfActions":[
+    {"activateDate":"01/02/2020","terminationDate":"Indefinite","terminationType":"Indefinite","recordStatus":"Active"},
+    {"activateDate":"01/02/2010","terminationDate":"01/02/2012","terminationType":"Definite","recordStatus":"Inactive"}]}
+}]}`
+
+func TestCheckExclusions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/entity-information/v4/exclusions" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		if r.URL.Query().Get("ueiSAM") == "BAD123BAD456" {
+			_, _ = w.Write([]byte(exclusionsFixture))
+			return
+		}
+		_, _ = w.Write([]byte(`{"totalRecords":0,"excludedEntity":[]}`))
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, "", "k", srv.Client())
+	res, err := c.CheckExclusions(context.Background(), []ExclusionQuery{{UEI: "BAD123BAD456"}, {Name: "Good Co"}})
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if len(res) != 2 || !res[0].Excluded || res[1].Excluded {
+		t.Fatalf("unexpected results: %+v", res)
+	}
+	ex := res[0].Exclusions
+	if len(ex) != 1 || ex[0].AgencyCode != "HHS" || ex[0].ActiveDate.Year() != 2020 || !ex[0].TerminationDate.IsZero() {
+		t.Fatalf("unexpected exclusions: %+v", ex)
+	}
+}
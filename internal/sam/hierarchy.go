@@ -0,0 +1,249 @@
+package sam
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// HierarchyVersion is the Federal Hierarchy API version used for organization lookups.
+const HierarchyVersion = "v1"
+
+// maxOrgPageSize is the largest page the Federal Hierarchy API returns.
+const maxOrgPageSize = 100
+
+// OrgParams filters Federal Hierarchy organizations. Level 1 is department/independent
+// agency, 2 is sub-tier and 3+ are offices; zero means any level.
+type OrgParams struct {
+	Name   string
+	Level  int
+	Limit  int
+	Offset int
+}
+
+// Organization is a normalized Federal Hierarchy node.
+type Organization struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Type       string   `json:"type"`
+	Level      int      `json:"level"`
+	AgencyCode string   `json:"agencyCode,omitempty"`
+	CGAC       string   `json:"cgac,omitempty"`
+	OfficeCode string   `json:"officeCode,omitempty"`
+	ParentPath []string `json:"parentPath,omitempty"`
+	ParentIDs  []string `json:"parentIds,omitempty"`
+	Status     string   `json:"status,omitempty"`
+}
+
+// Path returns the full hierarchy path, e.g. "DEPT OF DEFENSE > DEPT OF THE ARMY".
+func (o Organization) Path() string {
+	if len(o.ParentPath) == 0 {
+		return o.Name
+	}
+	return strings.Join(o.ParentPath, " > ")
+}
+
+// OrgPage is one page of Federal Hierarchy results.
+type OrgPage struct {
+	TotalRecords  int            `json:"totalRecords"`
+	Organizations []Organization `json:"organizations"`
+}
+
+// hierarchyPath is the Federal Hierarchy orgs path, which the alpha host serves under
+// /prodlike rather than /prod.
+func (c *Client) hierarchyPath() string {
+	stage := "/prod"
+	if c.BaseURL == AlphaBaseURL {
+		stage = "/prodlike"
+	}
+	return stage + "/federalorganizations/" + HierarchyVersion + "/orgs"
+}
+
+// SearchOrganizations queries the Federal Hierarchy API for active organizations.
+func (c *Client) SearchOrganizations(ctx context.Context, p OrgParams) (*OrgPage, error) {
+	if p.Limit <= 0 || p.Limit > maxOrgPageSize {
+		p.Limit = maxOrgPageSize
+	}
+	u, err := c.endpoint(c.hierarchyPath())
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	q.Set("api_key", c.APIKey)
+	q.Set("status", "Active")
+	if p.Name != "" {
+		q.Set("fhorgname", p.Name)
+	}
+	if p.Level > 0 {
+		q.Set("level", strconv.Itoa(p.Level))
+	}
+	q.Set("limit", strconv.Itoa(p.Limit))
+	q.Set("offset", strconv.Itoa(p.Offset))
+	u.RawQuery = q.Encode()
+
+	body, err := c.getJSON(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+	m, _ := body.(map[string]any)
+	page := &OrgPage{TotalRecords: getInt(m, "totalrecords"), Organizations: []Organization{}}
+	for _, it := range getSlice(m, "orglist") {
+		om, _ := it.(map[string]any)
+		page.Organizations = append(page.Organizations, normalizeOrganization(om))
+	}
+	return page, nil
+}
+
+func normalizeOrganization(m map[string]any) Organization {
+	o := Organization{
+		ID:         getID(m, "fhorgid"),
+		Name:       getString(m, "fhorgname"),
+		Type:       getString(m, "fhorgtype"),
+		Level:      getInt(m, "level"),
+		AgencyCode: getString(m, "agencycode"),
+		CGAC:       getString(m, "cgac"),
+		OfficeCode: firstNonEmpty(getString(m, "aacofficecode"), getString(m, "oldfpdsofficecode")),
+		Status:     getString(m, "status"),
+	}
+	// The most recent parent history entry carries the full dotted path of names and ids.
+	hist := getSlice(m, "fhorgparenthistory")
+	if len(hist) > 0 {
+		h, _ := hist[len(hist)-1].(map[string]any)
+		if names := getString(h, "fhfullparentpathname"); names != "" {
+			o.ParentPath = strings.Split(names, ".")
+		}
+		if ids := getString(h, "fhfullparentpathid"); ids != "" {
+			o.ParentIDs = strings.Split(ids, ".")
+		}
+	}
+	return o
+}
+
+// getID reads an identifier the API may encode as a number or a string.
+func getID(m map[string]any, key string) string {
+	if v, ok := m[key].(float64); ok {
+		return strconv.FormatInt(int64(v), 10)
+	}
+	return getString(m, key)
+}
+
+// OrgMatch is an organization ranked against a free-text query; Score is in (0, 1].
+type OrgMatch struct {
+	Organization Organization `json:"organization"`
+	Path         string       `json:"path"`
+	Score        float64      `json:"score"`
+}
+
+// orgStopwords are dropped before matching so "Department of Veterans Affairs" and
+// "VETERANS AFFAIRS, DEPARTMENT OF" compare equal.
+var orgStopwords = map[string]bool{
+	"DEPARTMENT": true, "DEPT": true, "OF": true, "THE": true, "AND": true, "FOR": true, "US": true, "U": true, "S": true,
+}
+
+// orgAliases expands common acronyms that are not derivable from the official names.
+var orgAliases = map[string]string{
+	"DOD":   "DEFENSE",
+	"DHS":   "HOMELAND SECURITY",
+	"HHS":   "HEALTH AND HUMAN SERVICES",
+	"DOE":   "ENERGY",
+	"DOJ":   "JUSTICE",
+	"DOT":   "TRANSPORTATION",
+	"DOI":   "INTERIOR",
+	"DOS":   "STATE",
+	"DOC":   "COMMERCE",
+	"DON":   "NAVY",
+	"USAF":  "AIR FORCE",
+	"USDA":  "AGRICULTURE",
+	"ED":    "EDUCATION",
+	"HUD":   "HOUSING AND URBAN DEVELOPMENT",
+	"DOL":   "LABOR",
+	"USACE": "ARMY CORPS OF ENGINEERS",
+}
+
+// orgTokens upper-cases, strips punctuation and stopwords from s.
+func orgTokens(s string) []string {
+	fields := strings.FieldsFunc(strings.ToUpper(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	out := fields[:0]
+	for _, f := range fields {
+		if !orgStopwords[f] {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// acronym returns the initials of the significant words in name, e.g. "GSA" for
+// General Services Administration or "VA" for Veterans Affairs, Department of.
+func acronym(name string) string {
+	var b strings.Builder
+	for _, f := range orgTokens(name) {
+		b.WriteByte(f[0])
+	}
+	return b.String()
+}
+
+// scoreOrganization rates how well query matches an organization name.
+func scoreOrganization(query string, o Organization) float64 {
+	q := strings.ToUpper(strings.TrimSpace(query))
+	if q == "" {
+		return 0
+	}
+	if q == strings.ToUpper(o.Name) || q == o.ID || (o.AgencyCode != "" && q == o.AgencyCode) {
+		return 1
+	}
+	if alias, ok := orgAliases[q]; ok {
+		query, q = alias, alias
+	}
+	if !strings.Contains(q, " ") && len(q) >= 2 && q == acronym(o.Name) {
+		return 0.95
+	}
+	qt := orgTokens(query)
+	nt := orgTokens(o.Name)
+	if len(qt) == 0 || len(nt) == 0 {
+		return 0
+	}
+	matched := 0
+	for _, a := range qt {
+		for _, b := range nt {
+			if a == b || (len(a) >= 4 && strings.HasPrefix(b, a)) {
+				matched++
+				break
+			}
+		}
+	}
+	if matched == 0 {
+		return 0
+	}
+	recall := float64(matched) / float64(len(qt))
+	precision := float64(matched) / float64(len(nt))
+	score := 0.9 * (2 * recall * precision / (recall + precision))
+	// Prefer higher levels of the hierarchy when scores tie ("Army" is the department, not an office).
+	if o.Level > 0 {
+		score -= 0.01 * float64(o.Level-1)
+	}
+	return score
+}
+
+// MatchOrganizations ranks orgs against query and returns at most limit matches, best first.
+func MatchOrganizations(query string, orgs []Organization, limit int) []OrgMatch {
+	var out []OrgMatch
+	for _, o := range orgs {
+		if sc := scoreOrganization(query, o); sc > 0 {
+			out = append(out, OrgMatch{Organization: o, Path: o.Path(), Score: sc})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		return out[i].Organization.Level < out[j].Organization.Level
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
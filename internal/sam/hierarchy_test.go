@@ -0,0 +1,66 @@
+package sam
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var testOrgs = []Organization{
+	{ID: "100000000", Name: "DEPT OF DEFENSE", Level: 1, AgencyCode: "9700"},
+	{ID: "100000001", Name: "DEPT OF THE ARMY", Level: 2, ParentPath: []string{"DEPT OF DEFENSE", "DEPT OF THE ARMY"}},
+	{ID: "100000002", Name: "ARMY CORPS OF ENGINEERS", Level: 3},
+	{ID: "100000003", Name: "VETERANS AFFAIRS, DEPARTMENT OF", Level: 1, AgencyCode: "3600"},
+	{ID: "100000004", Name: "GENERAL SERVICES ADMINISTRATION", Level: 1, AgencyCode: "4700"},
+}
+
+func TestMatchOrganizations(t *testing.T) {
+	cases := map[string]string{
+		"Army":                           "100000001",
+		"Department of Veterans Affairs": "100000003",
+		"VA":                             "100000003",
+		"gsa":                            "100000004",
+		"DoD":                            "100000000",
+		"9700":                           "100000000",
+	}
+	for q, want := range cases {
+		m := MatchOrganizations(q, testOrgs, 3)
+		if len(m) == 0 || m[0].Organization.ID != want {
+			t.Errorf("MatchOrganizations(%q) = %+v, want %s first", q, m, want)
+		}
+	}
+	if m := MatchOrganizations("Army", testOrgs, 0); m[0].Path != "DEPT OF DEFENSE > DEPT OF THE ARMY" {
+		t.Errorf("unexpected path %q", m[0].Path)
+	}
+	if m := MatchOrganizations("Interior", testOrgs, 3); len(m) != 0 {
+		t.Errorf("expected no matches, got %+v", m)
+	}
+}
+
+func TestSearchOrganizations(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/prod/federalorganizations/v1/orgs" || r.URL.Query().Get("level") != "2" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		_, _ = w.Write([]byte(`{"totalrecords":1,"orglist":[{"fhorgid":100000001,"fhorgname":"DEPT OF THE ARMY","fhorgtype":"Sub-Tier","level":2,"agencycode":"2100",
+		  "fhorgparenthistory":[{"fhfullparentpathid":"100000000.100000001","fhfullparentpathname":"DEPT OF DEFENSE.DEPT OF THE ARMY"}]}]}`))
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, "", "k", srv.Client())
+	page, err := c.SearchOrganizations(context.Background(), OrgParams{Level: 2})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if page.TotalRecords != 1 || len(page.Organizations) != 1 {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+	o := page.Organizations[0]
+	if o.ID != "100000001" || o.AgencyCode != "2100" || len(o.ParentIDs) != 2 || o.Path() != "DEPT OF DEFENSE > DEPT OF THE ARMY" {
+		t.Fatalf("unexpected organization: %+v", o)
+	}
+	if p := New(AlphaBaseURL, "", "k", nil).hierarchyPath(); p != "/prodlike/federalorganizations/v1/orgs" {
+		t.Errorf("alpha hierarchy path = %s", p)
+	}
+}
@@ -0,0 +1,20 @@
+package sam
+
+// NoticeType is an Opportunities API notice type code and its name.
+type NoticeType struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// NoticeTypes lists the notice type codes accepted by the Opportunities search.
+var NoticeTypes = []NoticeType{
+	{Code: "o", Name: "Solicitation"},
+	{Code: "p", Name: "Presolicitation"},
+	{Code: "k", Name: "Combined Synopsis/Solicitation"},
+	{Code: "r", Name: "Sources Sought"},
+	{Code: "s", Name: "Special Notice"},
+	{Code: "g", Name: "Sale of Surplus Property"},
+	{Code: "a", Name: "Award Notice"},
+	{Code: "u", Name: "Justification (J&A)"},
+	{Code: "i", Name: "Intent to Bundle Requirements (DoD-Funded)"},
+}
@@ -0,0 +1,146 @@
+package sam
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	// Zone data for the US time zones notices quote, for hosts without a zoneinfo database.
+	_ "time/tzdata"
+)
+
+// Deadline is a date found in notice text. AllDay is set when the text gives a date but no
+// time; At is then midnight of that date in UTC.
+type Deadline struct {
+	At     time.Time
+	AllDay bool
+}
+
+var (
+	questionCue = regexp.MustCompile(`(?i)\b(questions?|inquiries|RFIs?)\b`)
+	dueCue      = regexp.MustCompile(`(?i)\b(due|no later than|NLT|deadline|submitted|received|by)\b`)
+
+	monthDate = regexp.MustCompile(`(?i)\b(jan|feb|mar|apr|may|jun|jul|aug|sep|sept|oct|nov|dec)[a-z]*\.?\s+(\d{1,2})(?:st|nd|rd|th)?,?\s+(\d{4})\b`)
+	slashDate = regexp.MustCompile(`\b(\d{1,2})/(\d{1,2})/(\d{4})\b`)
+	isoDate   = regexp.MustCompile(`\b(\d{4})-(\d{2})-(\d{2})\b`)
+	clockTime = regexp.MustCompile(`(?i)\b(\d{1,2})(?::(\d{2}))?\s*([ap])\.?\s?m\b\.?|\b([01]?\d|2[0-3]):([0-5]\d)\b`)
+	zoneName  = regexp.MustCompile(`(?i)\b(AK|[ECMPH])[SD]?T\b|\b(eastern|central|mountain|pacific|alaska|hawaii)\b`)
+	// A period ends a sentence when a capitalized word follows, which keeps "Mar. 5" and
+	// "2 p.m. EST" whole.
+	sentenceEnd = regexp.MustCompile(`\.\s+([A-Z][a-z])`)
+)
+
+var months = map[string]time.Month{
+	"jan": time.January, "feb": time.February, "mar": time.March, "apr": time.April, "may": time.May, "jun": time.June,
+	"jul": time.July, "aug": time.August, "sep": time.September, "oct": time.October, "nov": time.November, "dec": time.December,
+}
+
+// zoneLocations maps the zone letters and names notices use to their locations, so that
+// "EST" in July still resolves to daylight time.
+var zoneLocations = map[string]string{
+	"e": "America/New_York", "eastern": "America/New_York",
+	"c": "America/Chicago", "central": "America/Chicago",
+	"m": "America/Denver", "mountain": "America/Denver",
+	"p": "America/Los_Angeles", "pacific": "America/Los_Angeles",
+	"ak": "America/Anchorage", "alaska": "America/Anchorage",
+	"h": "Pacific/Honolulu", "hawaii": "Pacific/Honolulu",
+}
+
+// defaultZone is assumed for a time quoted without a zone.
+const defaultZone = "America/New_York"
+
+// QuestionsDeadline looks for the due date of questions in a notice description, such as
+// "Questions are due no later than March 5, 2026 at 2:00 PM EST". It reads the first
+// sentence that mentions questions and a due date. A time without a zone is taken as
+// US Eastern.
+func QuestionsDeadline(text string) (Deadline, bool) {
+	for _, s := range sentences(text) {
+		if !questionCue.MatchString(s) || !dueCue.MatchString(s) {
+			continue
+		}
+		y, m, d, rest, ok := findDate(s)
+		if !ok {
+			continue
+		}
+		hour, minute, ok := findClock(rest)
+		if !ok {
+			return Deadline{At: time.Date(y, m, d, 0, 0, 0, 0, time.UTC), AllDay: true}, true
+		}
+		loc, err := time.LoadLocation(findZone(s))
+		if err != nil {
+			loc = time.UTC
+		}
+		return Deadline{At: time.Date(y, m, d, hour, minute, 0, 0, loc)}, true
+	}
+	return Deadline{}, false
+}
+
+func sentences(text string) []string {
+	text = sentenceEnd.ReplaceAllString(text, ".\n$1")
+	return strings.FieldsFunc(text, func(r rune) bool { return r == '\n' || r == ';' })
+}
+
+// findDate returns the first date in s and s without it, where a time may be found.
+func findDate(s string) (y int, m time.Month, d int, rest string, ok bool) {
+	if loc := monthDate.FindStringSubmatchIndex(s); loc != nil {
+		m = months[strings.ToLower(s[loc[2]:loc[2]+3])]
+		d, _ = strconv.Atoi(s[loc[4]:loc[5]])
+		y, _ = strconv.Atoi(s[loc[6]:loc[7]])
+		return y, m, d, s[:loc[0]] + " " + s[loc[1]:], validDate(y, m, d)
+	}
+	if loc := slashDate.FindStringSubmatchIndex(s); loc != nil {
+		mm, _ := strconv.Atoi(s[loc[2]:loc[3]])
+		d, _ = strconv.Atoi(s[loc[4]:loc[5]])
+		y, _ = strconv.Atoi(s[loc[6]:loc[7]])
+		return y, time.Month(mm), d, s[:loc[0]] + " " + s[loc[1]:], validDate(y, time.Month(mm), d)
+	}
+	if loc := isoDate.FindStringSubmatchIndex(s); loc != nil {
+		y, _ = strconv.Atoi(s[loc[2]:loc[3]])
+		mm, _ := strconv.Atoi(s[loc[4]:loc[5]])
+		d, _ = strconv.Atoi(s[loc[6]:loc[7]])
+		return y, time.Month(mm), d, s[:loc[0]] + " " + s[loc[1]:], validDate(y, time.Month(mm), d)
+	}
+	return 0, 0, 0, s, false
+}
+
+func validDate(y int, m time.Month, d int) bool {
+	if m < time.January || m > time.December || d < 1 {
+		return false
+	}
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Day() == d
+}
+
+// findClock returns the first time of day in s, in 12-hour (2 PM, 2:30 p.m.) or 24-hour
+// (14:00) form.
+func findClock(s string) (hour, minute int, ok bool) {
+	m := clockTime.FindStringSubmatch(s)
+	if m == nil {
+		return 0, 0, false
+	}
+	if m[1] != "" {
+		hour, _ = strconv.Atoi(m[1])
+		if m[2] != "" {
+			minute, _ = strconv.Atoi(m[2])
+		}
+		if hour < 1 || hour > 12 {
+			return 0, 0, false
+		}
+		hour %= 12
+		if strings.EqualFold(m[3], "p") {
+			hour += 12
+		}
+		return hour, minute, true
+	}
+	hour, _ = strconv.Atoi(m[4])
+	minute, _ = strconv.Atoi(m[5])
+	return hour, minute, true
+}
+
+// findZone returns the location of the first US time zone named in s.
+func findZone(s string) string {
+	m := zoneName.FindStringSubmatch(s)
+	if m == nil {
+		return defaultZone
+	}
+	return zoneLocations[strings.ToLower(m[1]+m[2])]
+}
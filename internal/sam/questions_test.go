@@ -0,0 +1,31 @@
+package sam
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuestionsDeadline(t *testing.T) {
+	eastern, _ := time.LoadLocation("America/New_York")
+	pacific, _ := time.LoadLocation("America/Los_Angeles")
+	for _, tc := range []struct {
+		text   string
+		want   time.Time
+		allDay bool
+		found  bool
+	}{
+		{"The Government will host a site visit. Questions are due no later than Mar. 5, 2026 at 2:00 p.m. EST via email.", time.Date(2026, 3, 5, 14, 0, 0, 0, eastern), false, true},
+		// PST in July still means Pacific time, which is then daylight time.
+		{"All questions must be submitted by 2:30 PM PST on July 14th, 2026.", time.Date(2026, 7, 14, 14, 30, 0, 0, pacific), false, true},
+		{"Offers are due 04/01/2026.\nQuestions due: 03/20/2026 14:00", time.Date(2026, 3, 20, 14, 0, 0, 0, eastern), false, true},
+		{"Inquiries deadline 2026-03-18; offers due 2026-04-01 at 5 PM ET", time.Date(2026, 3, 18, 0, 0, 0, 0, time.UTC), true, true},
+		{"Questions may be sent by email to the contracting officer.", time.Time{}, false, false},
+		{"Responses are due April 1, 2026 at 2 PM ET.", time.Time{}, false, false},
+		{"Questions are due February 30, 2026.", time.Time{}, false, false},
+	} {
+		got, ok := QuestionsDeadline(tc.text)
+		if ok != tc.found || !got.At.Equal(tc.want) || got.AllDay != tc.allDay {
+			t.Errorf("QuestionsDeadline(%q) = %v %v %v, want %v %v %v", tc.text, got.At, got.AllDay, ok, tc.want, tc.allDay, tc.found)
+		}
+	}
+}
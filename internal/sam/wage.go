@@ -0,0 +1,57 @@
+package sam
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Wage determination act types.
+const (
+	WDTypeSCA = "SCA" // Service Contract Act
+	WDTypeDBA = "DBA" // Davis-Bacon Act
+)
+
+var (
+	// scaNumber matches SCA wage determination numbers such as 2015-4281.
+	scaNumber = regexp.MustCompile(`^\d{2,4}-\d{4}$`)
+	// dbaNumber matches DBA wage determination numbers such as VA20240001.
+	dbaNumber = regexp.MustCompile(`^[A-Z]{2}\d{8}$`)
+)
+
+// WageDeterminationType reports whether number is an SCA or DBA wage determination number,
+// or returns "" when it is neither.
+func WageDeterminationType(number string) string {
+	switch number = strings.ToUpper(strings.TrimSpace(number)); {
+	case scaNumber.MatchString(number):
+		return WDTypeSCA
+	case dbaNumber.MatchString(number):
+		return WDTypeDBA
+	}
+	return ""
+}
+
+// WageDeterminationURL is the public SAM.gov page for a wage determination revision.
+func WageDeterminationURL(number string, revision int) string {
+	if number == "" {
+		return ""
+	}
+	u := "https://sam.gov/wage-determination/" + url.PathEscape(number)
+	if revision > 0 {
+		u += "/" + strconv.Itoa(revision)
+	}
+	return u
+}
+
+// WageDeterminationSearchURL links to the SAM.gov wage determination search for a place
+// of performance.
+func WageDeterminationSearchURL(state, county string) string {
+	q := url.Values{}
+	q.Set("index", "wd")
+	q.Set("wdStateCode", strings.ToUpper(state))
+	if county != "" {
+		q.Set("wdCounty", county)
+	}
+	return "https://sam.gov/search/?" + q.Encode()
+}
@@ -0,0 +1,38 @@
+package sam
+
+import "testing"
+
+func TestWageDeterminationLinks(t *testing.T) {
+	for number, want := range map[string]string{
+		"2015-4281":  WDTypeSCA,
+		"05-2103":    WDTypeSCA,
+		"va20240001": WDTypeDBA,
+		"VA2024001":  "",
+		"4281":       "",
+		"":           "",
+	} {
+		if got := WageDeterminationType(number); got != want {
+			t.Errorf("WageDeterminationType(%q) = %q, want %q", number, got, want)
+		}
+	}
+	if u := WageDeterminationURL("2015-4281", 25); u != "https://sam.gov/wage-determination/2015-4281/25" {
+		t.Fatalf("unexpected url %q", u)
+	}
+	if u := WageDeterminationURL("VA20240001", 0); u != "https://sam.gov/wage-determination/VA20240001" {
+		t.Fatalf("unexpected url %q", u)
+	}
+}
+
+func TestNormalizePlaceOfPerformance(t *testing.T) {
+	opps := normalize([]any{map[string]any{
+		"title":              "Janitorial",
+		"placeOfPerformance": map[string]any{"city": map[string]any{"code": "3000", "name": "Arlington"}, "state": map[string]any{"code": "VA"}, "country": map[string]any{"code": "USA"}},
+	}})
+	pop := opps[0].PlaceOfPerformance
+	if pop == nil || pop.City != "Arlington" || pop.State != "VA" {
+		t.Fatalf("unexpected place: %+v", pop)
+	}
+	if opps[0].WageDeterminationsURL == "" {
+		t.Fatal("expected wage determinations link")
+	}
+}
@@ -0,0 +1,306 @@
+// Package savedsearch persists saved searches: named SAM.gov searches with an owner, an
+// optional schedule and notification targets, together with the notice ids each search
+// has already returned so that later runs can report only what is new.
+package savedsearch
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"sam-mcp/internal/jsonfile"
+	"sam-mcp/internal/sam"
+	"sam-mcp/internal/scheduler"
+)
+
+// Notification target types.
+const (
+	TargetWebhook = "webhook"
+	TargetSlack   = "slack"
+	TargetTeams   = "teams"
+	TargetEmail   = "email"
+)
+
+// MaxIdle forgets notices a saved search has not returned for this long; one that
+// reappears afterwards is reported as new again.
+const MaxIdle = 180 * 24 * time.Hour
+
+var (
+	// ErrNotFound is returned for an unknown saved search id.
+	ErrNotFound = errors.New("saved search not found")
+	// ErrInvalid wraps validation failures of a saved search.
+	ErrInvalid = errors.New("invalid saved search")
+)
+
+// Target is where a saved search's new notices are sent.
+type Target struct {
+	Type string `json:"type" jsonschema:"required,enum=webhook|slack|teams|email"`
+	// Address is a URL for webhook, slack and teams targets and a mailbox for email.
+	Address string `json:"address" jsonschema:"required,minLength=1"`
+}
+
+// Search is a saved search.
+type Search struct {
+	ID       string           `json:"id"`
+	Name     string           `json:"name"`
+	Owner    string           `json:"owner,omitempty"`
+	Params   sam.SearchParams `json:"params"`
+	Schedule string           `json:"schedule,omitempty"`
+	Notify   []Target         `json:"notify,omitempty"`
+	Created  time.Time        `json:"created"`
+	Updated  time.Time        `json:"updated"`
+	LastRun  *time.Time       `json:"lastRun,omitempty"`
+	// SeenCount is the number of distinct notices the search has returned within MaxIdle.
+	SeenCount int `json:"seenCount"`
+}
+
+// Validate checks the fields a caller supplies. Failures wrap ErrInvalid.
+func (s *Search) Validate() error {
+	if err := s.validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	return nil
+}
+
+func (s *Search) validate() error {
+	if strings.TrimSpace(s.Name) == "" {
+		return errors.New("name is required")
+	}
+	if s.Params.Days <= 0 {
+		return errors.New("params.days must be positive")
+	}
+	if s.Schedule != "" {
+		if _, err := scheduler.Parse(s.Schedule); err != nil {
+			return err
+		}
+	}
+	return ValidateTargets(s.Notify)
+}
+
+// ValidateTargets checks notification targets: a known type, an http(s) URL for webhook,
+// slack and teams targets, and a mail address for email targets, which it reduces to the
+// bare address.
+func ValidateTargets(targets []Target) error {
+	for i, t := range targets {
+		switch t.Type {
+		case TargetWebhook, TargetSlack, TargetTeams, TargetEmail:
+		default:
+			return fmt.Errorf("notify[%d]: unknown target type %q", i, t.Type)
+		}
+		if strings.TrimSpace(t.Address) == "" {
+			return fmt.Errorf("notify[%d]: address is required", i)
+		}
+		if t.Type == TargetEmail {
+			addr, err := mail.ParseAddress(t.Address)
+			if err != nil {
+				return fmt.Errorf("notify[%d]: invalid email address %q: %v", i, t.Address, err)
+			}
+			targets[i].Address = addr.Address
+		} else if u, err := url.Parse(t.Address); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("notify[%d]: %s address must be an http(s) URL", i, t.Type)
+		}
+	}
+	return nil
+}
+
+// file is the on-disk representation of a Store.
+type file struct {
+	Searches []*Search `json:"searches"`
+	// Seen maps saved search id to notice id to when the notice was last returned.
+	Seen map[string]map[string]time.Time `json:"seen"`
+}
+
+// Store holds saved searches in memory, writing them to a JSON file after every change.
+type Store struct {
+	mu       sync.Mutex
+	path     string
+	searches map[string]*Search
+	seen     map[string]map[string]time.Time
+}
+
+// Open loads the store at path, which need not exist yet. An empty path keeps saved
+// searches in memory only.
+func Open(path string) (*Store, error) {
+	st := &Store{path: path, searches: make(map[string]*Search), seen: make(map[string]map[string]time.Time)}
+	if path == "" {
+		return st, nil
+	}
+	var f file
+	if _, err := jsonfile.Load(path, &f); err != nil {
+		return nil, err
+	}
+	for _, s := range f.Searches {
+		st.searches[s.ID] = s
+	}
+	for id, seen := range f.Seen {
+		if _, ok := st.searches[id]; ok {
+			st.seen[id] = seen
+		}
+	}
+	return st, nil
+}
+
+// Create validates and stores a new saved search, assigning its id and timestamps.
+func (st *Store) Create(s Search) (Search, error) {
+	if err := s.Validate(); err != nil {
+		return Search{}, err
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	s.ID = newID()
+	s.Created = time.Now().UTC()
+	s.Updated = s.Created
+	s.LastRun, s.SeenCount = nil, 0
+	st.searches[s.ID] = &s
+	if err := st.save(); err != nil {
+		delete(st.searches, s.ID)
+		return Search{}, err
+	}
+	return s.clone(), nil
+}
+
+// Get returns the saved search with the given id.
+func (st *Store) Get(id string) (Search, bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	s, ok := st.searches[id]
+	if !ok {
+		return Search{}, false
+	}
+	return s.clone(), true
+}
+
+// List returns the saved searches ordered by name, restricted to owner when non-empty.
+func (st *Store) List(owner string) []Search {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	out := make([]Search, 0, len(st.searches))
+	for _, s := range st.searches {
+		if owner == "" || strings.EqualFold(s.Owner, owner) {
+			out = append(out, s.clone())
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Name != out[j].Name {
+			return out[i].Name < out[j].Name
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// Update applies fn to a copy of the saved search and stores the result if it is valid.
+func (st *Store) Update(id string, fn func(*Search)) (Search, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	cur, ok := st.searches[id]
+	if !ok {
+		return Search{}, ErrNotFound
+	}
+	next := cur.clone()
+	fn(&next)
+	next.ID, next.Created, next.LastRun, next.SeenCount = cur.ID, cur.Created, cur.LastRun, cur.SeenCount
+	if err := next.Validate(); err != nil {
+		return Search{}, err
+	}
+	next.Updated = time.Now().UTC()
+	st.searches[id] = &next
+	if err := st.save(); err != nil {
+		st.searches[id] = cur
+		return Search{}, err
+	}
+	return next.clone(), nil
+}
+
+// Delete removes a saved search and its seen notices.
+func (st *Store) Delete(id string) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	cur, ok := st.searches[id]
+	if !ok {
+		return ErrNotFound
+	}
+	seen := st.seen[id]
+	delete(st.searches, id)
+	delete(st.seen, id)
+	if err := st.save(); err != nil {
+		st.searches[id], st.seen[id] = cur, seen
+		return err
+	}
+	return nil
+}
+
+// RecordRun marks the notices returned by a run at the given time as seen and returns
+// those the search had not returned before, in the order given. Notices not returned
+// within MaxIdle of at are forgotten.
+func (st *Store) RecordRun(id string, noticeIDs []string, at time.Time) ([]string, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	s, ok := st.searches[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	seen := st.seen[id]
+	if seen == nil {
+		seen = make(map[string]time.Time)
+		st.seen[id] = seen
+	}
+	at = at.UTC()
+	for n, last := range seen {
+		if at.Sub(last) > MaxIdle {
+			delete(seen, n)
+		}
+	}
+	fresh := make([]string, 0)
+	for _, n := range noticeIDs {
+		if n == "" {
+			continue
+		}
+		if _, dup := seen[n]; !dup {
+			fresh = append(fresh, n)
+		}
+		if seen[n].Before(at) {
+			seen[n] = at
+		}
+	}
+	s.LastRun = &at
+	s.SeenCount = len(seen)
+	return fresh, st.save()
+}
+
+// save writes the store atomically. Callers hold st.mu.
+func (st *Store) save() error {
+	if st.path == "" {
+		return nil
+	}
+	f := file{Searches: make([]*Search, 0, len(st.searches)), Seen: st.seen}
+	for _, s := range st.searches {
+		f.Searches = append(f.Searches, s)
+	}
+	sort.Slice(f.Searches, func(i, j int) bool { return f.Searches[i].ID < f.Searches[j].ID })
+	return jsonfile.Save(st.path, f)
+}
+
+func (s *Search) clone() Search {
+	out := *s
+	out.Params.NAICS = append([]string(nil), s.Params.NAICS...)
+	out.Notify = append([]Target(nil), s.Notify...)
+	if s.LastRun != nil {
+		t := *s.LastRun
+		out.LastRun = &t
+	}
+	return out
+}
+
+func newID() string {
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,103 @@
+package savedsearch
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sam-mcp/internal/sam"
+)
+
+func TestStorePersistsSearchesAndSeenNotices(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "saved.json")
+	st, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss, err := st.Create(Search{Name: "Cyber", Owner: "ana", Params: sam.SearchParams{Q: "cyber", Days: 7}, Schedule: "0 7 * * 1-5",
+		Notify: []Target{{Type: TargetEmail, Address: "bd@example.com"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ss.ID == "" || ss.Created.IsZero() {
+		t.Fatalf("id and timestamps not assigned: %+v", ss)
+	}
+	at := time.Date(2026, 3, 2, 7, 0, 0, 0, time.UTC)
+	if fresh, err := st.RecordRun(ss.ID, []string{"A", "B"}, at); err != nil || len(fresh) != 2 {
+		t.Fatalf("first run: %v %v", fresh, err)
+	}
+	if fresh, err := st.RecordRun(ss.ID, []string{"B", "C", ""}, at.Add(24*time.Hour)); err != nil || len(fresh) != 1 || fresh[0] != "C" {
+		t.Fatalf("second run: %v %v", fresh, err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := reopened.Get(ss.ID)
+	if !ok || got.Name != "Cyber" || got.SeenCount != 3 || got.LastRun == nil || !got.LastRun.Equal(at.Add(24*time.Hour)) || len(got.Notify) != 1 {
+		t.Fatalf("unexpected reloaded search: %+v", got)
+	}
+	if fresh, _ := reopened.RecordRun(ss.ID, []string{"A", "D"}, at); len(fresh) != 1 || fresh[0] != "D" {
+		t.Fatalf("seen notices not persisted: %v", fresh)
+	}
+	// A and D were last returned at the first run's time, B and C a day later.
+	later := at.Add(MaxIdle + 12*time.Hour)
+	if fresh, _ := reopened.RecordRun(ss.ID, []string{"A", "C"}, later); len(fresh) != 1 || fresh[0] != "A" {
+		t.Fatalf("idle notice not forgotten: %v", fresh)
+	}
+	if got, _ := reopened.Get(ss.ID); got.SeenCount != 3 {
+		t.Fatalf("idle notices not pruned: %+v", got)
+	}
+	if got := reopened.List("ANA"); len(got) != 1 {
+		t.Fatalf("owner filter: %+v", got)
+	}
+	if got := reopened.List("bob"); len(got) != 0 {
+		t.Fatalf("owner filter: %+v", got)
+	}
+}
+
+func TestStoreUpdateAndDelete(t *testing.T) {
+	st, _ := Open("")
+	ss, err := st.Create(Search{Name: "Facilities", Params: sam.SearchParams{Days: 7}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	up, err := st.Update(ss.ID, func(s *Search) { s.Name, s.ID = "Facilities (SE)", "hijack" })
+	if err != nil || up.Name != "Facilities (SE)" || up.ID != ss.ID {
+		t.Fatalf("update: %+v %v", up, err)
+	}
+	if _, err := st.Update(ss.ID, func(s *Search) { s.Schedule = "whenever" }); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected ErrInvalid, got %v", err)
+	}
+	if got, _ := st.Get(ss.ID); got.Schedule != "" {
+		t.Fatalf("invalid update was stored: %+v", got)
+	}
+	if err := st.Delete(ss.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.Delete(ss.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, s := range []Search{
+		{Params: sam.SearchParams{Days: 7}},
+		{Name: "x"},
+		{Name: "x", Params: sam.SearchParams{Days: 7}, Schedule: "61 * * * *"},
+		{Name: "x", Params: sam.SearchParams{Days: 7}, Notify: []Target{{Type: "pager", Address: "x"}}},
+		{Name: "x", Params: sam.SearchParams{Days: 7}, Notify: []Target{{Type: TargetWebhook}}},
+		{Name: "x", Params: sam.SearchParams{Days: 7}, Notify: []Target{{Type: TargetEmail, Address: "bids@example.com\r\nBcc: x@example.com"}}},
+	} {
+		if err := s.Validate(); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%+v: expected ErrInvalid, got %v", s, err)
+		}
+	}
+
+	s := Search{Name: "x", Params: sam.SearchParams{Days: 7}, Notify: []Target{{Type: TargetEmail, Address: "Bids Team <bids@example.com>"}}}
+	if err := s.Validate(); err != nil || s.Notify[0].Address != "bids@example.com" {
+		t.Fatalf("email target not reduced to its address: %v %+v", err, s.Notify)
+	}
+}
@@ -0,0 +1,252 @@
+// Package scheduler runs background jobs on cron-style schedules inside the server
+// process, with random jitter, overlap prevention and a bounded run history per job.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Run triggers.
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+// DefaultHistory is the number of runs kept per job.
+const DefaultHistory = 20
+
+var (
+	// ErrRunning is returned by RunNow while the job's previous run is still in progress.
+	ErrRunning = errors.New("job is already running")
+	// ErrUnknownJob is returned for a job name that was never added.
+	ErrUnknownJob = errors.New("unknown job")
+)
+
+// Job is a named unit of background work. An empty Spec registers a job that only runs
+// when triggered with RunNow.
+type Job struct {
+	Name string
+	Spec string
+	// Jitter delays each scheduled run by a random duration in [0, Jitter) so that
+	// replicas do not hit upstream APIs at the same instant.
+	Jitter time.Duration
+	Run    func(ctx context.Context) error
+}
+
+// Run records one execution, or one skipped activation, of a job.
+type Run struct {
+	Job      string    `json:"job"`
+	Trigger  string    `json:"trigger"`
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"` // zero for skipped runs
+	Error    string    `json:"error,omitempty"`
+	// Skipped marks a scheduled activation dropped because the previous run was still going.
+	Skipped bool `json:"skipped,omitempty"`
+}
+
+// JobStatus summarizes a job for status endpoints.
+type JobStatus struct {
+	Name     string     `json:"name"`
+	Schedule string     `json:"schedule,omitempty"`
+	Next     *time.Time `json:"next,omitempty"` // nil until Start schedules the job
+	Running  bool       `json:"running"`
+	History  []Run      `json:"history"`
+}
+
+type job struct {
+	Job
+	sched   Schedule
+	next    time.Time
+	running bool
+	history []Run
+	stop    context.CancelFunc
+}
+
+// Scheduler owns a set of jobs. Jobs may be added and removed at any time; those added
+// after Start are scheduled immediately. RunNow may be called at any time.
+type Scheduler struct {
+	mu    sync.Mutex
+	jobs  map[string]*job
+	order []string
+	keep  int
+	loc   *time.Location
+	// ctx is the context passed to Start; nil before Start.
+	ctx context.Context
+}
+
+// New returns a Scheduler that evaluates schedules in loc (UTC when nil).
+func New(loc *time.Location) *Scheduler {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &Scheduler{jobs: make(map[string]*job), keep: DefaultHistory, loc: loc}
+}
+
+// Add registers a job, parsing its schedule.
+func (s *Scheduler) Add(j Job) error {
+	if j.Name == "" || j.Run == nil {
+		return errors.New("scheduler: job name and Run are required")
+	}
+	var sched Schedule
+	if j.Spec != "" {
+		var err error
+		if sched, err = Parse(j.Spec); err != nil {
+			return fmt.Errorf("job %s: %w", j.Name, err)
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, dup := s.jobs[j.Name]; dup {
+		return fmt.Errorf("scheduler: job %q already added", j.Name)
+	}
+	jb := &job{Job: j, sched: sched}
+	s.jobs[j.Name] = jb
+	s.order = append(s.order, j.Name)
+	if s.ctx != nil {
+		s.schedule(jb)
+	}
+	return nil
+}
+
+// Remove stops scheduling a job and forgets it and its history, cancelling a scheduled run
+// in progress. Removing an unknown job is a no-op.
+func (s *Scheduler) Remove(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[name]
+	if !ok {
+		return
+	}
+	if j.stop != nil {
+		j.stop()
+	}
+	delete(s.jobs, name)
+	for i, n := range s.order {
+		if n == name {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// Start runs each scheduled job in its own goroutine until ctx is cancelled.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ctx = ctx
+	for _, name := range s.order {
+		s.schedule(s.jobs[name])
+	}
+}
+
+// schedule starts the loop of a job that has a schedule. Callers hold s.mu.
+func (s *Scheduler) schedule(j *job) {
+	if j.sched == nil {
+		return
+	}
+	ctx, stop := context.WithCancel(s.ctx)
+	j.stop = stop
+	go s.loop(ctx, j)
+}
+
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	for {
+		next := j.sched.Next(time.Now().In(s.loc))
+		if next.IsZero() {
+			log.Printf("WARN: job %s: schedule %q never fires", j.Name, j.Spec)
+			return
+		}
+		s.mu.Lock()
+		j.next = next
+		s.mu.Unlock()
+		delay := time.Until(next)
+		if j.Jitter > 0 {
+			delay += rand.N(j.Jitter)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if _, err := s.run(ctx, j, TriggerSchedule); err != nil && !errors.Is(err, ErrRunning) {
+			log.Printf("WARN: job %s failed: %v", j.Name, err)
+		}
+	}
+}
+
+// RunNow runs a job immediately and waits for it, unless a run is already in progress.
+func (s *Scheduler) RunNow(ctx context.Context, name string) (Run, error) {
+	s.mu.Lock()
+	j, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return Run{}, ErrUnknownJob
+	}
+	return s.run(ctx, j, TriggerManual)
+}
+
+// run executes j unless it is already running, recording the outcome in its history.
+func (s *Scheduler) run(ctx context.Context, j *job, trigger string) (Run, error) {
+	r := Run{Job: j.Name, Trigger: trigger, Started: time.Now().In(s.loc)}
+	s.mu.Lock()
+	if j.running {
+		if trigger == TriggerSchedule {
+			r.Skipped = true
+			r.Error = ErrRunning.Error()
+			s.record(j, r)
+		}
+		s.mu.Unlock()
+		return r, ErrRunning
+	}
+	j.running = true
+	s.mu.Unlock()
+
+	err := j.Run(ctx)
+
+	r.Finished = time.Now().In(s.loc)
+	if err != nil {
+		r.Error = err.Error()
+	}
+	s.mu.Lock()
+	j.running = false
+	s.record(j, r)
+	s.mu.Unlock()
+	return r, err
+}
+
+// record appends r to the job's history, dropping the oldest runs. Callers hold s.mu.
+func (s *Scheduler) record(j *job, r Run) {
+	j.history = append(j.history, r)
+	if len(j.history) > s.keep {
+		j.history = append([]Run(nil), j.history[len(j.history)-s.keep:]...)
+	}
+}
+
+// Status reports every job in the order added, with its most recent runs first.
+func (s *Scheduler) Status() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]JobStatus, 0, len(s.order))
+	for _, name := range s.order {
+		j := s.jobs[name]
+		hist := make([]Run, len(j.history))
+		for i, r := range j.history {
+			hist[len(hist)-1-i] = r
+		}
+		st := JobStatus{Name: name, Schedule: j.Spec, Running: j.running, History: hist}
+		if !j.next.IsZero() {
+			next := j.next
+			st.Next = &next
+		}
+		out = append(out, st)
+	}
+	return out
+}
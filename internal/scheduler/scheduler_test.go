@@ -0,0 +1,149 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseNext(t *testing.T) {
+	base := time.Date(2026, 3, 14, 7, 30, 0, 0, time.UTC) // a Saturday
+	cases := []struct {
+		spec string
+		want time.Time
+	}{
+		{"0 6,18 * * *", time.Date(2026, 3, 14, 18, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 3, 14, 7, 45, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2026, 3, 16, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"30 7 * * 7", time.Date(2026, 3, 15, 7, 30, 0, 0, time.UTC)},
+		{"0 12 13 * 5", time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"@every 90m", time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range cases {
+		s, err := Parse(tc.spec)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.spec, err)
+		}
+		if got := s.Next(base); !got.Equal(tc.want) {
+			t.Errorf("%s: next = %s, want %s", tc.spec, got, tc.want)
+		}
+	}
+	for _, bad := range []string{"", "* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *", "@every 10ms", "@often"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+	if s, _ := Parse("0 0 30 2 *"); !s.Next(base).IsZero() {
+		t.Error("expected no activation for 30 February")
+	}
+}
+
+func TestRunNowPreventsOverlapAndRecordsHistory(t *testing.T) {
+	s := New(nil)
+	release := make(chan struct{})
+	started := make(chan struct{})
+	fail := errors.New("upstream down")
+	var calls atomic.Int32
+	err := s.Add(Job{Name: "slow", Run: func(context.Context) error {
+		if calls.Add(1) == 1 {
+			close(started)
+			<-release
+			return nil
+		}
+		return fail
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add(Job{Name: "slow", Run: func(context.Context) error { return nil }}); err == nil {
+		t.Fatal("expected duplicate job error")
+	}
+
+	done := make(chan error)
+	go func() {
+		_, err := s.RunNow(context.Background(), "slow")
+		done <- err
+	}()
+	<-started
+	if _, err := s.RunNow(context.Background(), "slow"); !errors.Is(err, ErrRunning) {
+		t.Fatalf("expected ErrRunning, got %v", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RunNow(context.Background(), "slow"); !errors.Is(err, fail) {
+		t.Fatalf("expected job error, got %v", err)
+	}
+	if _, err := s.RunNow(context.Background(), "missing"); !errors.Is(err, ErrUnknownJob) {
+		t.Fatalf("expected ErrUnknownJob, got %v", err)
+	}
+
+	st := s.Status()
+	if len(st) != 1 || len(st[0].History) != 2 || st[0].History[0].Error != "upstream down" || st[0].History[1].Trigger != TriggerManual {
+		t.Fatalf("unexpected status: %+v", st)
+	}
+}
+
+func TestStartRunsScheduledJobs(t *testing.T) {
+	s := New(nil)
+	ran := make(chan struct{}, 10)
+	if err := s.Add(Job{Name: "tick", Spec: "@every 1s", Run: func(context.Context) error {
+		ran <- struct{}{}
+		return nil
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.Start(ctx)
+	select {
+	case <-ran:
+	case <-time.After(3 * time.Second):
+		t.Fatal("scheduled job did not run")
+	}
+	if st := s.Status(); st[0].Next == nil || st[0].Schedule != "@every 1s" {
+		t.Fatalf("unexpected status: %+v", st)
+	}
+}
+
+func TestAddAfterStartAndRemove(t *testing.T) {
+	s := New(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.Start(ctx)
+
+	ran := make(chan struct{}, 10)
+	if err := s.Add(Job{Name: "late", Spec: "@every 1s", Run: func(context.Context) error {
+		ran <- struct{}{}
+		return nil
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-ran:
+	case <-time.After(3 * time.Second):
+		t.Fatal("job added after Start did not run")
+	}
+
+	s.Remove("late")
+	if len(s.Status()) != 0 {
+		t.Fatalf("removed job still listed: %+v", s.Status())
+	}
+	if _, err := s.RunNow(ctx, "late"); !errors.Is(err, ErrUnknownJob) {
+		t.Fatalf("expected ErrUnknownJob, got %v", err)
+	}
+	time.Sleep(100 * time.Millisecond) // let an activation racing Remove finish
+	for len(ran) > 0 {
+		<-ran
+	}
+	select {
+	case <-ran:
+		t.Fatal("removed job kept running")
+	case <-time.After(1500 * time.Millisecond):
+	}
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"sam-mcp/internal/mcp"
+	"sam-mcp/internal/sam"
+)
+
+// assistanceArgs are the sam_assistance_search arguments.
+type assistanceArgs struct {
+	Keyword       string   `json:"keyword"`
+	Agency        string   `json:"agency" description:"Agency name (resolved via the Federal Hierarchy) or code"`
+	ProgramNumber string   `json:"programNumber" description:"Assistance listing number, e.g. 10.752"`
+	Eligibility   []string `json:"eligibility" description:"Applicant types, e.g. State, Tribal, Nonprofit"`
+	Limit         int      `json:"limit" jsonschema:"min=1,max=100,default=25"`
+	Offset        int      `json:"offset" jsonschema:"min=0,default=0"`
+}
+
+// assistanceResult is the sam_assistance_search response.
+type assistanceResult struct {
+	TotalRecords         int                     `json:"totalRecords"`
+	Limit                int                     `json:"limit"`
+	Offset               int                     `json:"offset"`
+	Listings             []sam.AssistanceListing `json:"listings"`
+	SamEnv               string                  `json:"samEnv"`
+	ResolvedOrganization *sam.OrgMatch           `json:"resolvedOrganization,omitempty"`
+}
+
+// toolAssistanceSearch serves the sam_assistance_search tool over Assistance Listings
+// (federal financial assistance programs, formerly CFDA).
+func (s *Server) toolAssistanceSearch(ctx context.Context, a assistanceArgs) (*assistanceResult, error) {
+	if a.Keyword == "" && a.Agency == "" && a.ProgramNumber == "" && len(a.Eligibility) == 0 {
+		return nil, mcp.InvalidParams("one of keyword, agency, programNumber or eligibility is required", nil)
+	}
+
+	params := sam.AssistanceParams{
+		Keyword:       a.Keyword,
+		Agency:        a.Agency,
+		ProgramNumber: a.ProgramNumber,
+		Eligibility:   a.Eligibility,
+		Limit:         a.Limit,
+		Offset:        a.Offset,
+	}
+	cacheKey := fmt.Sprintf("sam_assistance:%s:%s:%s:%s:%d:%d", strings.ToLower(a.Keyword), strings.ToLower(a.Agency), a.ProgramNumber, strings.Join(a.Eligibility, ","), a.Limit, a.Offset)
+	res, err := cached(s, cacheKey, 12*time.Hour, func() (*assistanceResult, error) {
+		// Agency names are resolved the same way as sam_search's organization filter.
+		orgParams := sam.SearchParams{Org: params.Agency}
+		resolved := s.resolveSearchOrg(ctx, &orgParams)
+		params.AgencyCode = orgParams.OrgCode
+		page, err := s.sam.SearchAssistance(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		return &assistanceResult{
+			TotalRecords:         page.TotalRecords,
+			Limit:                page.Limit,
+			Offset:               page.Offset,
+			Listings:             page.Listings,
+			SamEnv:               s.samEnv,
+			ResolvedOrganization: resolved,
+		}, nil
+	})
+	if err != nil {
+		return nil, mcp.Upstream("sam api error: %v", err)
+	}
+	return res, nil
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"sam-mcp/internal/mcp"
+	"sam-mcp/internal/sam"
+)
+
+// awardArgs are the sam_contract_awards arguments.
+type awardArgs struct {
+	PIID               string `json:"piid"`
+	SolicitationNumber string `json:"solicitationNumber"`
+	AwardeeUEI         string `json:"awardeeUei"`
+	Agency             string `json:"agency" description:"Contracting department code, e.g. 9700"`
+	NAICS              string `json:"naics"`
+	Limit              int    `json:"limit" jsonschema:"min=1,max=100,default=25"`
+	Offset             int    `json:"offset" jsonschema:"min=0,default=0"`
+}
+
+// awardsResult is the sam_contract_awards response.
+type awardsResult struct {
+	TotalRecords int                 `json:"totalRecords"`
+	Limit        int                 `json:"limit"`
+	Offset       int                 `json:"offset"`
+	Awards       []sam.ContractAward `json:"awards"`
+	SamEnv       string              `json:"samEnv"`
+}
+
+// toolContractAwards serves the sam_contract_awards tool used for incumbent research.
+func (s *Server) toolContractAwards(ctx context.Context, a awardArgs) (*awardsResult, error) {
+	if a.PIID == "" && a.SolicitationNumber == "" && a.AwardeeUEI == "" && a.Agency == "" && a.NAICS == "" {
+		return nil, mcp.InvalidParams("one of piid, solicitationNumber, awardeeUei, agency or naics is required", nil)
+	}
+
+	params := sam.AwardParams{
+		PIID:               a.PIID,
+		SolicitationNumber: a.SolicitationNumber,
+		AwardeeUEI:         a.AwardeeUEI,
+		Agency:             a.Agency,
+		NAICS:              a.NAICS,
+		Limit:              a.Limit,
+		Offset:             a.Offset,
+	}
+	cacheKey := "sam_awards:" + strings.Join([]string{a.PIID, a.SolicitationNumber, a.AwardeeUEI, a.Agency, a.NAICS, strconv.Itoa(a.Limit), strconv.Itoa(a.Offset)}, ":")
+	res, err := cached(s, cacheKey, 12*time.Hour, func() (*awardsResult, error) {
+		page, err := s.sam.SearchContractAwards(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		return &awardsResult{
+			TotalRecords: page.TotalRecords,
+			Limit:        page.Limit,
+			Offset:       page.Offset,
+			Awards:       page.Awards,
+			SamEnv:       s.samEnv,
+		}, nil
+	})
+	if err != nil {
+		return nil, mcp.Upstream("sam api error: %v", err)
+	}
+	return res, nil
+}
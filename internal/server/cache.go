@@ -1,6 +1,7 @@
 package server
 
 import (
+    "strings"
     "sync"
     "time"
 )
@@ -42,3 +43,17 @@ func (c *Cache) Get(key string) (interface{}, bool) {
     }
     return it.value, true
 }
+
+// Values returns the non-expired values whose keys start with prefix, keyed by cache key.
+func (c *Cache) Values(prefix string) map[string]interface{} {
+    now := time.Now()
+    c.mu.RLock()
+    defer c.mu.RUnlock()
+    out := make(map[string]interface{})
+    for k, it := range c.items {
+        if strings.HasPrefix(k, prefix) && now.Before(it.expiration) {
+            out[k] = it.value
+        }
+    }
+    return out
+}
@@ -0,0 +1,162 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"sam-mcp/internal/ical"
+	"sam-mcp/internal/sam"
+)
+
+// Deadline calendar settings.
+const (
+	calendarRefresh = time.Hour
+	maxAlarms       = 5
+)
+
+// defaultAlarms remind a week, a day and two hours before each deadline.
+var defaultAlarms = []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, 2 * time.Hour}
+
+// handleCalendar serves GET /mcp/feeds/{id}.ics: the response deadlines, and the
+// questions deadlines found in descriptions, of a prefetch profile's or saved search's
+// latest results as an iCalendar subscription. ?alarms= overrides the reminders with a
+// comma-separated list of lead times (7d, 1d, 2h, 30m) or "none".
+func (s *Server) handleCalendar(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	alarms, err := parseAlarms(r.URL.Query().Get("alarms"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	title, ok := s.feedTitle(id)
+	if !ok {
+		http.Error(w, "unknown saved search or profile: "+id, http.StatusNotFound)
+		return
+	}
+	res, err := s.savedSearchResults(r.Context(), id)
+	if err != nil {
+		http.Error(w, "sam api error: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	if res == nil {
+		// The saved search was deleted since feedTitle found it.
+		http.Error(w, "unknown saved search or profile: "+id, http.StatusNotFound)
+		return
+	}
+	c := ical.Calendar{
+		Name:        strings.Replace(title, "SAM.gov: ", "SAM.gov deadlines: ", 1),
+		Description: "Response and questions deadlines of " + id + " (SAM.gov " + s.samEnv + ")",
+		Refresh:     calendarRefresh,
+		Events:      s.deadlineEvents(res.Results, alarms, time.Now()),
+	}
+	w.Header().Set("Content-Type", ical.ContentType)
+	_, _ = w.Write(ical.Render(c))
+}
+
+// deadlineEvents returns an event for each opportunity's response deadline and questions
+// deadline. Event UIDs derive from the notice id, and the sequence counts the notice's
+// stored versions, so an amended deadline moves the subscriber's existing event.
+func (s *Server) deadlineEvents(opps []sam.Opportunity, alarms []time.Duration, now time.Time) []ical.Event {
+	var out []ical.Event
+	for _, o := range opps {
+		title := firstNonEmpty(o.Title, o.NoticeID)
+		base := ical.Event{
+			Description:  deadlineDescription(o),
+			URL:          o.URL,
+			Categories:   []string{"SAM.gov"},
+			Stamp:        now,
+			LastModified: o.Modified,
+			Alarms:       alarms,
+		}
+		if o.Type != "" {
+			base.Categories = append(base.Categories, o.Type)
+		}
+		if p := o.PlaceOfPerformance; p != nil {
+			base.Location = strings.Join(nonEmpty(p.City, p.State, p.Country), ", ")
+		}
+		versions := s.versions.List(o.NoticeID)
+		if len(versions) > 1 {
+			base.Sequence = len(versions) - 1
+		}
+		if d := o.ResponseDeadline; d != nil && !d.IsZero() {
+			e := base
+			e.UID = o.NoticeID + "-response@sam-mcp"
+			e.Summary = "Response due: " + title
+			e.Start, e.AllDay = *d, o.ResponseDeadlineDateOnly
+			out = append(out, e)
+		}
+		text := o.Description
+		for i := len(versions) - 1; text == "" && i >= 0; i-- {
+			text = versions[i].Opportunity.Description
+		}
+		if qa, ok := sam.QuestionsDeadline(text); ok {
+			e := base
+			e.UID = o.NoticeID + "-questions@sam-mcp"
+			e.Summary = "Questions due: " + title
+			e.Start, e.AllDay = qa.At, qa.AllDay
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// deadlineDescription is the event description: the notice's key facts and its link.
+func deadlineDescription(o sam.Opportunity) string {
+	var lines []string
+	for _, f := range [][2]string{{"Agency", o.Agency}, {"Solicitation", o.SolicitationNumber}, {"Notice", o.NoticeID}, {"Type", o.Type}, {"NAICS", strings.TrimSpace(o.NAICS + " " + o.NAICSTitle)}} {
+		if f[1] != "" {
+			lines = append(lines, f[0]+": "+f[1])
+		}
+	}
+	if o.URL != "" {
+		lines = append(lines, o.URL)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseAlarms parses the alarms query parameter: empty for the defaults, "none", or lead
+// times such as 7d, 1d, 2h and 30m.
+func parseAlarms(v string) ([]time.Duration, error) {
+	switch strings.TrimSpace(v) {
+	case "":
+		return defaultAlarms, nil
+	case "none":
+		return nil, nil
+	}
+	var out []time.Duration
+	for _, part := range strings.Split(v, ",") {
+		part = strings.TrimSpace(part)
+		var d time.Duration
+		var err error
+		if days, ok := strings.CutSuffix(part, "d"); ok {
+			var n int
+			n, err = strconv.Atoi(days)
+			d = time.Duration(n) * 24 * time.Hour
+		} else {
+			d, err = time.ParseDuration(part)
+		}
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid alarm %q: use lead times such as 7d, 2h or 30m", part)
+		}
+		out = append(out, d)
+	}
+	if len(out) > maxAlarms {
+		return nil, fmt.Errorf("at most %d alarms", maxAlarms)
+	}
+	return out, nil
+}
+
+func nonEmpty(vals ...string) []string {
+	var out []string
+	for _, v := range vals {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	"sam-mcp/internal/catalog"
+	"sam-mcp/internal/mcp"
+	"sam-mcp/internal/sam"
+)
+
+// naicsCode is a NAICS catalog entry as returned by naics_lookup.
+type naicsCode struct {
+	Code         string `json:"code"`
+	Title        string `json:"title"`
+	Level        string `json:"level"`
+	SizeStandard string `json:"sizeStandard,omitempty"`
+}
+
+// pscCode is a PSC catalog entry as returned by psc_lookup.
+type pscCode struct {
+	Code  string `json:"code"`
+	Title string `json:"title"`
+	Kind  string `json:"kind"`
+}
+
+// naicsIssue reports an unusable naics argument with catalog suggestions.
+type naicsIssue struct {
+	Code        string      `json:"code"`
+	Reason      string      `json:"reason"`
+	Suggestions []naicsCode `json:"suggestions,omitempty"`
+}
+
+func toNAICS(codes []catalog.Code) []naicsCode {
+	out := make([]naicsCode, 0, len(codes))
+	for _, c := range codes {
+		level := "industry"
+		switch {
+		case len(c.Code) == 2 || strings.Contains(c.Code, "-"):
+			level = "sector"
+		case len(c.Code) == 3:
+			level = "subsector"
+		}
+		out = append(out, naicsCode{Code: c.Code, Title: c.Title, Level: level, SizeStandard: c.Extra})
+	}
+	return out
+}
+
+func toPSC(codes []catalog.Code) []pscCode {
+	out := make([]pscCode, 0, len(codes))
+	for _, c := range codes {
+		out = append(out, pscCode{Code: c.Code, Title: c.Title, Kind: c.Extra})
+	}
+	return out
+}
+
+// catalogArgs are the naics_lookup and psc_lookup arguments.
+type catalogArgs struct {
+	Query string `json:"query" jsonschema:"required"`
+	Limit int    `json:"limit" jsonschema:"min=1,max=50,default=10"`
+}
+
+// naicsResult is the naics_lookup response.
+type naicsResult struct {
+	Codes []naicsCode `json:"codes"`
+}
+
+// pscResult is the psc_lookup response.
+type pscResult struct {
+	Codes []pscCode `json:"codes"`
+}
+
+// toolNAICSLookup serves the naics_lookup tool: code-prefix or keyword search of the
+// bundled NAICS 2022 catalog with SBA size standards.
+func (s *Server) toolNAICSLookup(_ context.Context, a catalogArgs) (*naicsResult, error) {
+	if strings.TrimSpace(a.Query) == "" {
+		return nil, mcp.InvalidParams("query is required", nil)
+	}
+	return &naicsResult{Codes: toNAICS(catalog.NAICS().Search(a.Query, a.Limit))}, nil
+}
+
+// toolPSCLookup serves the psc_lookup tool: code-prefix or keyword search of the bundled
+// Product and Service Code catalog.
+func (s *Server) toolPSCLookup(_ context.Context, a catalogArgs) (*pscResult, error) {
+	if strings.TrimSpace(a.Query) == "" {
+		return nil, mcp.InvalidParams("query is required", nil)
+	}
+	return &pscResult{Codes: toPSC(catalog.PSC().Search(a.Query, a.Limit))}, nil
+}
+
+// validateNAICS checks sam_search naics arguments against the catalog. Six-digit codes in a
+// known subsector but not in NAICS 2022, such as codes from earlier editions that older
+// notices still carry, are accepted with a warning.
+func validateNAICS(codes []string) (issues []naicsIssue, warnings []string) {
+	cat := catalog.NAICS()
+	for _, code := range codes {
+		code = strings.TrimSpace(code)
+		if c, ok := cat.Lookup(code); ok && len(c.Code) == 6 {
+			continue
+		}
+		if len(code) == 6 && isDigits(code) {
+			if _, ok := cat.Lookup(code[:3]); ok {
+				warnings = append(warnings, "naics "+code+" is not a NAICS 2022 code; passed through unvalidated")
+				continue
+			}
+		}
+		reason := "unknown NAICS code"
+		if len(code) != 6 || !isDigits(code) {
+			reason = "NAICS codes must be six digits"
+		}
+		issues = append(issues, naicsIssue{Code: code, Reason: reason, Suggestions: toNAICS(cat.Suggest(code, 5))})
+	}
+	return issues, warnings
+}
+
+// enrichCodes fills NAICS and PSC titles on opportunities from the bundled catalogs.
+func enrichCodes(opps []sam.Opportunity) {
+	for i := range opps {
+		if c, ok := catalog.NAICS().Lookup(opps[i].NAICS); ok {
+			opps[i].NAICSTitle = c.Title
+		}
+		if c, ok := catalog.PSC().Lookup(opps[i].PSC); ok {
+			opps[i].PSCTitle = c.Title
+		}
+	}
+}
+
+// isDigits reports whether s is a non-empty string of ASCII digits.
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
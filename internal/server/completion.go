@@ -0,0 +1,248 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"sam-mcp/internal/catalog"
+	"sam-mcp/internal/mcp"
+	"sam-mcp/internal/sam"
+)
+
+// completer returns ranked candidates for a partially typed argument value.
+type completer func(ctx context.Context, value string) ([]mcp.Candidate, error)
+
+// argumentCompleters complete arguments by name wherever they appear: in sam_search and
+// the other tools, in prompt arguments and in resource template variables.
+func (s *Server) argumentCompleters() map[string]completer {
+	return map[string]completer{
+		"naics":         completeNAICS,
+		"noticeType":    completeNoticeType,
+		"organization":  s.completeOrganization,
+		"agency":        s.completeOrganization,
+		"noticeId":      s.completeNoticeID,
+		"savedSearchId": s.completeSavedSearchID,
+	}
+}
+
+func (s *Server) handleComplete(w http.ResponseWriter, r *http.Request) {
+	var req mcp.CompleteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	complete, err := s.completerFor(req)
+	if err != nil {
+		writeMCPError(w, err)
+		return
+	}
+	var cands []mcp.Candidate
+	if complete != nil {
+		cands, err = complete(r.Context(), req.Argument.Value)
+		if err != nil {
+			writeMCPError(w, mcp.Upstream("sam api error: %v", err))
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"completion": mcp.NewCompletion(cands)})
+}
+
+// completerFor validates the reference and picks the completer for its argument. Arguments
+// without a completer yield no suggestions rather than an error.
+func (s *Server) completerFor(req mcp.CompleteRequest) (completer, error) {
+	arg := req.Argument.Name
+	if arg == "" {
+		return nil, mcp.InvalidParams("argument.name is required", nil)
+	}
+	switch req.Ref.Type {
+	case mcp.RefTool:
+		h, ok := s.tools.Lookup(req.Ref.Name)
+		if !ok {
+			return nil, mcp.InvalidParams("unknown tool: "+req.Ref.Name, nil)
+		}
+		if req.Ref.Name == "sam_resolve_organization" && arg == "name" {
+			return s.completeOrganization, nil
+		}
+		if strings.HasPrefix(req.Ref.Name, "saved_search_") && arg == "id" {
+			return s.completeSavedSearch, nil
+		}
+		if c, ok := s.argumentCompleters()[arg]; ok {
+			return c, nil
+		}
+		return enumCompleter(h.Info().InputSchema, arg), nil
+	case mcp.RefPrompt:
+		if _, ok := s.prompts.Get(req.Ref.Name); !ok {
+			return nil, mcp.InvalidParams("unknown prompt: "+req.Ref.Name, nil)
+		}
+	case mcp.RefResource:
+		known := false
+		for _, t := range resourceTemplates {
+			known = known || t.URITemplate == req.Ref.URI
+		}
+		if !known {
+			return nil, mcp.InvalidParams("unknown resource template: "+req.Ref.URI, nil)
+		}
+	default:
+		return nil, mcp.InvalidParams(fmt.Sprintf("unsupported ref type %q", req.Ref.Type), nil)
+	}
+	return s.argumentCompleters()[arg], nil
+}
+
+// enumCompleter completes a tool argument declared with an enum in its input schema.
+func enumCompleter(schema map[string]interface{}, arg string) completer {
+	props, _ := schema["properties"].(map[string]interface{})
+	ps, _ := props[arg].(map[string]interface{})
+	enum, _ := ps["enum"].([]string)
+	if len(enum) == 0 {
+		return nil
+	}
+	return func(_ context.Context, value string) ([]mcp.Candidate, error) {
+		var out []mcp.Candidate
+		for _, e := range enum {
+			if strings.HasPrefix(strings.ToLower(e), strings.ToLower(value)) {
+				out = append(out, mcp.Candidate{Value: e})
+			}
+		}
+		return out, nil
+	}
+}
+
+// completeNAICS suggests six-digit NAICS codes by code prefix or title keywords.
+func completeNAICS(_ context.Context, value string) ([]mcp.Candidate, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	var out []mcp.Candidate
+	for _, c := range catalog.NAICS().Search(value, 0) {
+		if len(c.Code) == 6 {
+			out = append(out, mcp.Candidate{Value: c.Code, Description: c.Title})
+		}
+	}
+	return out, nil
+}
+
+// completeNoticeType suggests notice type codes: an exact code first, then codes and names
+// starting with the value, then names containing it.
+func completeNoticeType(_ context.Context, value string) ([]mcp.Candidate, error) {
+	v := strings.ToLower(strings.TrimSpace(value))
+	type ranked struct {
+		mcp.Candidate
+		rank int
+	}
+	var rs []ranked
+	for _, t := range sam.NoticeTypes {
+		name := strings.ToLower(t.Name)
+		rank := -1
+		switch {
+		case v == "" || v == t.Code:
+			rank = 0
+		case strings.HasPrefix(name, v):
+			rank = 1
+		case strings.Contains(name, v):
+			rank = 2
+		}
+		if rank >= 0 {
+			rs = append(rs, ranked{mcp.Candidate{Value: t.Code, Description: t.Name}, rank})
+		}
+	}
+	sort.SliceStable(rs, func(i, j int) bool { return rs[i].rank < rs[j].rank })
+	out := make([]mcp.Candidate, len(rs))
+	for i, r := range rs {
+		out[i] = r.Candidate
+	}
+	return out, nil
+}
+
+// completeOrganization suggests department and sub-tier names from the cached Federal
+// Hierarchy: fuzzy matches first, then names with a word starting with the typed value.
+// Completion must stay fast, so a cold cache yields no candidates and loads in the background.
+func (s *Server) completeOrganization(ctx context.Context, value string) ([]mcp.Candidate, error) {
+	value = strings.TrimSpace(value)
+	v, ok := s.cache.Get(orgIndexKey)
+	if !ok {
+		s.startOrgIndexLoad()
+		return nil, nil
+	}
+	orgs := v.([]sam.Organization)
+	seen := map[string]bool{}
+	var out []mcp.Candidate
+	add := func(o sam.Organization) {
+		if seen[o.ID] {
+			return
+		}
+		seen[o.ID] = true
+		out = append(out, mcp.Candidate{Value: o.Name, Description: o.Path() + " (" + o.ID + ")"})
+	}
+	if value == "" {
+		for _, o := range orgs {
+			if o.Level == 1 {
+				add(o)
+			}
+		}
+		sort.SliceStable(out, func(i, j int) bool { return out[i].Value < out[j].Value })
+		return out, nil
+	}
+	for _, m := range sam.MatchOrganizations(value, orgs, 0) {
+		add(m.Organization)
+	}
+	prefix := strings.ToUpper(value)
+	for _, o := range orgs {
+		for _, word := range strings.Fields(o.Name) {
+			if strings.HasPrefix(word, prefix) {
+				add(o)
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+// completeNoticeID suggests cached notices whose id starts with, or whose title contains,
+// the typed value, most recently modified first.
+func (s *Server) completeNoticeID(_ context.Context, value string) ([]mcp.Candidate, error) {
+	v := strings.ToLower(strings.TrimSpace(value))
+	var byID, byTitle []mcp.Candidate
+	for _, o := range s.cachedOpportunities() {
+		c := mcp.Candidate{Value: o.NoticeID, Description: joinNonEmpty(o.Title, o.Agency)}
+		switch {
+		case strings.HasPrefix(strings.ToLower(o.NoticeID), v):
+			byID = append(byID, c)
+		case strings.Contains(strings.ToLower(o.Title), v):
+			byTitle = append(byTitle, c)
+		}
+	}
+	return append(byID, byTitle...), nil
+}
+
+// completeSavedSearchID suggests the ids accepted by sam://search/{savedSearchId}: prefetch
+// profile names, then saved searches.
+func (s *Server) completeSavedSearchID(ctx context.Context, value string) ([]mcp.Candidate, error) {
+	v := strings.ToLower(value)
+	var out []mcp.Candidate
+	for _, p := range s.prefetchProfiles() {
+		if strings.HasPrefix(p.Name, v) {
+			out = append(out, mcp.Candidate{Value: p.Name, Description: p.Description})
+		}
+	}
+	saved, _ := s.completeSavedSearch(ctx, value)
+	return append(out, saved...), nil
+}
+
+// completeSavedSearch suggests saved searches whose id starts with, or whose name contains,
+// the typed value.
+func (s *Server) completeSavedSearch(_ context.Context, value string) ([]mcp.Candidate, error) {
+	v := strings.ToLower(value)
+	var out []mcp.Candidate
+	for _, ss := range s.savedSearches.List("") {
+		if strings.HasPrefix(ss.ID, v) || strings.Contains(strings.ToLower(ss.Name), v) {
+			out = append(out, mcp.Candidate{Value: ss.ID, Description: joinNonEmpty(ss.Name, ss.Owner)})
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"sam-mcp/internal/notify"
+	"sam-mcp/internal/savedsearch"
+)
+
+// loadDigestTemplates loads the digest templates, falling back to the built-ins when dir
+// has a broken template; cmd/sam-mcp-http validates the directory at startup.
+func loadDigestTemplates(dir string) *notify.Templates {
+	t, err := notify.LoadTemplates(dir)
+	if err == nil {
+		return t
+	}
+	log.Printf("WARN: %v; using built-in digest templates", err)
+	t, err = notify.LoadTemplates("")
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// digestRecipients returns the mailboxes that get a source's events in their digest: its
+// email targets and Config.DigestTo. It is empty while email is not configured, leaving
+// email targets to fail in the queue as undeliverable.
+func (s *Server) digestRecipients(targets []savedsearch.Target) []string {
+	if !s.deliveries.Handles(savedsearch.TargetEmail) {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var out []string
+	add := func(addr string) {
+		addr = strings.ToLower(strings.TrimSpace(addr))
+		if addr != "" && !seen[addr] {
+			seen[addr] = true
+			out = append(out, addr)
+		}
+	}
+	for _, t := range targets {
+		if t.Type == savedsearch.TargetEmail {
+			add(t.Address)
+		}
+	}
+	for _, addr := range s.cfg.DigestTo {
+		add(addr)
+	}
+	return out
+}
+
+// sendDigests is the email digest job: it queues one digest per recipient with the
+// events held since the last run. A recipient's events are held again when their digest
+// cannot be queued.
+func (s *Server) sendDigests(context.Context) error {
+	pending, err := s.digests.Drain()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	var errs []error
+	for rcpt, events := range pending {
+		ev := notify.Compose(rcpt, events, now)
+		if len(ev.Notices) == 0 {
+			continue
+		}
+		if _, err := s.deliveries.Enqueue(notify.Target{Type: savedsearch.TargetEmail, Address: rcpt}, ev); err != nil {
+			errs = append(errs, err)
+			for _, e := range events {
+				_ = s.digests.Add(rcpt, e)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"sam-mcp/internal/mcp"
+	"sam-mcp/internal/sam"
+)
+
+// entityArgs are the sam_entity_lookup arguments.
+type entityArgs struct {
+	UEI  string `json:"uei"`
+	CAGE string `json:"cage"`
+	Name string `json:"name"`
+	Page int    `json:"page" jsonschema:"min=0,default=0"`
+	Size int    `json:"size" jsonschema:"min=1,max=10,default=10"`
+	// CheckExclusions annotates each entity with an exclusion flag.
+	CheckExclusions bool `json:"checkExclusions" description:"Flag entities with active exclusions"`
+}
+
+// entityResult is the sam_entity_lookup response.
+type entityResult struct {
+	TotalRecords int          `json:"totalRecords"`
+	Page         int          `json:"page"`
+	Size         int          `json:"size"`
+	Entities     []sam.Entity `json:"entities"`
+	SamEnv       string       `json:"samEnv"`
+}
+
+// toolEntityLookup serves the sam_entity_lookup tool: registration details for a UEI,
+// CAGE code or legal business name, cached like search results.
+func (s *Server) toolEntityLookup(ctx context.Context, a entityArgs) (*entityResult, error) {
+	if a.UEI == "" && a.CAGE == "" && a.Name == "" {
+		return nil, mcp.InvalidParams("one of uei, cage or name is required", nil)
+	}
+
+	params := sam.EntityParams{UEI: a.UEI, CAGE: a.CAGE, Name: a.Name, Page: a.Page, Size: a.Size}
+	cacheKey := fmt.Sprintf("sam_entity:%s:%s:%s:%d:%d", a.UEI, a.CAGE, strings.ToLower(a.Name), a.Page, a.Size)
+	res, err := cached(s, cacheKey, 12*time.Hour, func() (*entityResult, error) {
+		page, err := s.sam.LookupEntity(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		return &entityResult{
+			TotalRecords: page.TotalRecords,
+			Page:         page.Page,
+			Size:         page.Size,
+			Entities:     page.Entities,
+			SamEnv:       s.samEnv,
+		}, nil
+	})
+	if err != nil {
+		return nil, mcp.Upstream("sam api error: %v", err)
+	}
+	if !a.CheckExclusions {
+		return res, nil
+	}
+	// Annotate a copy so the flag does not leak into the cached response.
+	out := *res
+	out.Entities, err = s.annotateEntities(ctx, res.Entities)
+	if err != nil {
+		return nil, mcp.Upstream("sam api error during exclusion check: %v", err)
+	}
+	return &out, nil
+}
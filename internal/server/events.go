@@ -0,0 +1,140 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"sam-mcp/internal/mcp"
+)
+
+// sessionBuffer bounds the notifications queued for a slow event stream; extra
+// notifications are dropped rather than blocking the notifier.
+const sessionBuffer = 64
+
+// eventsKeepAlive is how often an idle event stream sends an SSE comment.
+const eventsKeepAlive = 30 * time.Second
+
+// eventSession is one open /mcp/events stream and the resource URIs it subscribed to.
+type eventSession struct {
+	events chan mcp.Notification
+	uris   map[string]bool
+}
+
+// eventHub tracks event stream sessions and delivers resource notifications to them.
+type eventHub struct {
+	mu       sync.Mutex
+	sessions map[string]*eventSession
+	// done is closed on shutdown to end open streams, which would otherwise hold
+	// http.Server.Shutdown until its timeout.
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{sessions: make(map[string]*eventSession), done: make(chan struct{})}
+}
+
+// shutdown ends every open event stream.
+func (h *eventHub) shutdown() { h.stopOnce.Do(func() { close(h.done) }) }
+
+func (h *eventHub) open() (string, *eventSession) {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	id := hex.EncodeToString(b)
+	sess := &eventSession{events: make(chan mcp.Notification, sessionBuffer), uris: make(map[string]bool)}
+	h.mu.Lock()
+	h.sessions[id] = sess
+	h.mu.Unlock()
+	return id, sess
+}
+
+func (h *eventHub) close(id string) {
+	h.mu.Lock()
+	delete(h.sessions, id)
+	h.mu.Unlock()
+}
+
+// subscribe adds or removes uri for a session, reporting false for an unknown session.
+func (h *eventHub) subscribe(id, uri string, on bool) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	sess, ok := h.sessions[id]
+	if !ok {
+		return false
+	}
+	if on {
+		sess.uris[uri] = true
+	} else {
+		delete(sess.uris, uri)
+	}
+	return true
+}
+
+// resourceUpdated notifies the sessions subscribed to uri.
+func (h *eventHub) resourceUpdated(uri string) {
+	n := mcp.NewNotification(mcp.MethodResourceUpdated, map[string]string{"uri": uri})
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, sess := range h.sessions {
+		if sess.uris[uri] {
+			sess.send(n)
+		}
+	}
+}
+
+// resourceListChanged notifies every session that resources/list would now differ.
+func (h *eventHub) resourceListChanged() {
+	n := mcp.NewNotification(mcp.MethodResourceListChanged, nil)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, sess := range h.sessions {
+		sess.send(n)
+	}
+}
+
+func (sess *eventSession) send(n mcp.Notification) {
+	select {
+	case sess.events <- n:
+	default:
+	}
+}
+
+// handleEvents streams server notifications as Server-Sent Events. The first event
+// carries the session id that resources/subscribe requests pass in Mcp-Session-Id.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	id, sess := s.events.open()
+	defer s.events.close(id)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Mcp-Session-Id", id)
+	fmt.Fprintf(w, "event: session\ndata: {\"sessionId\":%q}\n\n", id)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(eventsKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.events.done:
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case n := <-sess.events:
+			b, _ := json.Marshal(n)
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", b)
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,159 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"sam-mcp/internal/mcp"
+	"sam-mcp/internal/sam"
+)
+
+// maxExclusionChecks bounds the number of entities checked in one sam_check_exclusions call.
+const maxExclusionChecks = 100
+
+// exclusionArgs are the sam_check_exclusions arguments.
+type exclusionArgs struct {
+	UEIs  []string `json:"ueis"`
+	Names []string `json:"names"`
+}
+
+// exclusionsResult is the sam_check_exclusions response.
+type exclusionsResult struct {
+	Results []sam.ExclusionResult `json:"results"`
+	SamEnv  string                `json:"samEnv"`
+}
+
+// toolCheckExclusions serves the sam_check_exclusions tool: active exclusion records for
+// each UEI and name supplied.
+func (s *Server) toolCheckExclusions(ctx context.Context, a exclusionArgs) (*exclusionsResult, error) {
+	var queries []sam.ExclusionQuery
+	for _, u := range a.UEIs {
+		if u = strings.TrimSpace(u); u != "" {
+			queries = append(queries, sam.ExclusionQuery{UEI: u})
+		}
+	}
+	for _, n := range a.Names {
+		if n = strings.TrimSpace(n); n != "" {
+			queries = append(queries, sam.ExclusionQuery{Name: n})
+		}
+	}
+	if len(queries) == 0 {
+		return nil, mcp.InvalidParams("at least one uei or name is required", nil)
+	}
+	if len(queries) > maxExclusionChecks {
+		return nil, mcp.InvalidParams("too many entities; at most 100 per call", nil)
+	}
+
+	results, err := s.checkExclusions(ctx, queries)
+	if err != nil {
+		return nil, mcp.Upstream("sam api error: %v", err)
+	}
+	return &exclusionsResult{Results: results, SamEnv: s.samEnv}, nil
+}
+
+// checkExclusions resolves each query from the cache where possible and asks the SAM
+// client for the rest, caching each per-entity result like other SAM responses.
+func (s *Server) checkExclusions(ctx context.Context, queries []sam.ExclusionQuery) ([]sam.ExclusionResult, error) {
+	out := make([]sam.ExclusionResult, len(queries))
+	var missIdx []int
+	var misses []sam.ExclusionQuery
+	for i, q := range queries {
+		if v, ok := s.cache.Get(exclusionCacheKey(q)); ok {
+			out[i] = v.(sam.ExclusionResult)
+			continue
+		}
+		missIdx = append(missIdx, i)
+		misses = append(misses, q)
+	}
+	if len(misses) == 0 {
+		return out, nil
+	}
+	fetched, err := s.sam.CheckExclusions(ctx, misses)
+	if err != nil {
+		return nil, err
+	}
+	for j, res := range fetched {
+		if j >= len(missIdx) {
+			break
+		}
+		out[missIdx[j]] = res
+		s.cache.Set(exclusionCacheKey(misses[j]), res, 12*time.Hour)
+	}
+	return out, nil
+}
+
+func exclusionCacheKey(q sam.ExclusionQuery) string {
+	return "sam_exclusions:" + strings.ToUpper(q.UEI) + ":" + strings.ToLower(q.Name)
+}
+
+// exclusionQueryFor prefers the UEI and falls back to the name, matching how SAM indexes exclusions.
+func exclusionQueryFor(uei, name string) (sam.ExclusionQuery, bool) {
+	switch {
+	case uei != "":
+		return sam.ExclusionQuery{UEI: uei}, true
+	case name != "":
+		return sam.ExclusionQuery{Name: name}, true
+	}
+	return sam.ExclusionQuery{}, false
+}
+
+// annotateEntities returns copies of entities with Excluded set from an exclusion check.
+func (s *Server) annotateEntities(ctx context.Context, entities []sam.Entity) ([]sam.Entity, error) {
+	out := make([]sam.Entity, len(entities))
+	copy(out, entities)
+	var idx []int
+	var queries []sam.ExclusionQuery
+	for i, e := range out {
+		if q, ok := exclusionQueryFor(e.UEI, e.LegalName); ok {
+			idx = append(idx, i)
+			queries = append(queries, q)
+		}
+	}
+	if len(queries) == 0 {
+		return out, nil
+	}
+	results, err := s.checkExclusions(ctx, queries)
+	if err != nil {
+		return nil, err
+	}
+	for j, res := range results {
+		excluded := res.Excluded
+		out[idx[j]].Excluded = &excluded
+	}
+	return out, nil
+}
+
+// annotateAwardees returns copies of opps with Award.Awardee.Excluded set for award notices.
+func (s *Server) annotateAwardees(ctx context.Context, opps []sam.Opportunity) ([]sam.Opportunity, error) {
+	out := make([]sam.Opportunity, len(opps))
+	copy(out, opps)
+	var idx []int
+	var queries []sam.ExclusionQuery
+	for i, o := range out {
+		if o.Award == nil || o.Award.Awardee == nil {
+			continue
+		}
+		if q, ok := exclusionQueryFor(o.Award.Awardee.UEI, o.Award.Awardee.Name); ok {
+			idx = append(idx, i)
+			queries = append(queries, q)
+		}
+	}
+	if len(queries) == 0 {
+		return out, nil
+	}
+	results, err := s.checkExclusions(ctx, queries)
+	if err != nil {
+		return nil, err
+	}
+	for j, res := range results {
+		o := &out[idx[j]]
+		award := *o.Award
+		awardee := *award.Awardee
+		excluded := res.Excluded
+		awardee.Excluded = &excluded
+		award.Awardee = &awardee
+		o.Award = &award
+	}
+	return out, nil
+}
@@ -0,0 +1,168 @@
+package server
+
+import (
+	"html"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"sam-mcp/internal/feed"
+	"sam-mcp/internal/sam"
+)
+
+// Feed URL prefix and id scheme. Entry ids depend only on the notice id, so readers show
+// an amended notice as an update of the same entry.
+const (
+	feedPathPrefix = "/mcp/feeds/"
+	feedIDPrefix   = "urn:sam-mcp:feed:"
+	noticeIDPrefix = "urn:sam-mcp:notice:"
+)
+
+// handleFeed serves GET /mcp/feeds/{id}.atom and /mcp/feeds/{id}.rss: the latest results
+// of a prefetch profile or saved search as an Atom or RSS feed.
+func (s *Server) handleFeed(format string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		title, ok := s.feedTitle(id)
+		if !ok {
+			http.Error(w, "unknown saved search or profile: "+id, http.StatusNotFound)
+			return
+		}
+		res, err := s.savedSearchResults(r.Context(), id)
+		if err != nil {
+			http.Error(w, "sam api error: "+err.Error(), http.StatusBadGateway)
+			return
+		}
+		if res == nil {
+			// The saved search was deleted since feedTitle found it.
+			http.Error(w, "unknown saved search or profile: "+id, http.StatusNotFound)
+			return
+		}
+		f := s.searchFeed(id, title, res.Results, time.Now())
+		f.SelfLink = requestURL(r)
+		render, contentType := feed.Atom, feed.AtomContentType
+		if format == "rss" {
+			render, contentType = feed.RSS, feed.RSSContentType
+		}
+		body, err := render(f)
+		if err != nil {
+			log.Printf("ERROR: feed %s: %v", id, err)
+			http.Error(w, "feed rendering failed", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Last-Modified", f.Updated.UTC().Format(http.TimeFormat))
+		_, _ = w.Write(body)
+	}
+}
+
+// feedTitle names the feed of a prefetch profile or saved search.
+func (s *Server) feedTitle(id string) (string, bool) {
+	if p, ok := s.profile(id); ok {
+		return "SAM.gov: " + firstNonEmpty(p.Description, p.Name), true
+	}
+	if ss, ok := s.savedSearches.Get(id); ok {
+		return "SAM.gov: " + ss.Name, true
+	}
+	return "", false
+}
+
+// searchFeed builds the feed of a search's results, most recently modified first. The
+// feed is as recent as its newest entry, or now when it has none.
+func (s *Server) searchFeed(id, title string, opps []sam.Opportunity, now time.Time) feed.Feed {
+	f := feed.Feed{ID: feedIDPrefix + id, Title: title, Subtitle: "Latest results of " + id + " (SAM.gov " + s.samEnv + ")", Author: "SAM.gov"}
+	for _, o := range opps {
+		updated := o.Modified
+		if updated.IsZero() {
+			updated = o.Posted
+		}
+		if updated.IsZero() {
+			updated = now
+		}
+		e := feed.Entry{
+			ID:        noticeIDPrefix + o.NoticeID,
+			Title:     firstNonEmpty(o.Title, o.NoticeID),
+			Link:      o.URL,
+			Author:    o.Agency,
+			Published: o.Posted,
+			Updated:   updated,
+			Content:   feedContent(o),
+		}
+		for _, c := range []string{o.Type, o.NAICS, o.PSC} {
+			if c != "" {
+				e.Categories = append(e.Categories, c)
+			}
+		}
+		f.Entries = append(f.Entries, e)
+		if updated.After(f.Updated) {
+			f.Updated = updated
+		}
+	}
+	sort.SliceStable(f.Entries, func(i, j int) bool { return f.Entries[i].Updated.After(f.Entries[j].Updated) })
+	if f.Updated.IsZero() {
+		f.Updated = now
+	}
+	return f
+}
+
+// feedContent renders an opportunity's details as the HTML content of its entry.
+func feedContent(o sam.Opportunity) string {
+	var rows []string
+	add := func(label, value string) {
+		if value != "" {
+			rows = append(rows, "<b>"+label+":</b> "+html.EscapeString(value))
+		}
+	}
+	add("Agency", o.Agency)
+	add("Solicitation", o.SolicitationNumber)
+	add("Type", o.Type)
+	add("NAICS", strings.TrimSpace(o.NAICS+" "+o.NAICSTitle))
+	add("PSC", strings.TrimSpace(o.PSC+" "+o.PSCTitle))
+	if !o.Posted.IsZero() {
+		add("Posted", o.Posted.UTC().Format("Jan 2, 2006"))
+	}
+	if d := o.ResponseDeadline; d != nil && !d.IsZero() {
+		add("Response deadline", d.UTC().Format("Jan 2, 2006 15:04 MST"))
+	}
+	if p := o.PlaceOfPerformance; p != nil {
+		var parts []string
+		for _, v := range []string{p.City, p.State, p.Country} {
+			if v != "" {
+				parts = append(parts, v)
+			}
+		}
+		add("Place of performance", strings.Join(parts, ", "))
+	}
+	if !o.Modified.IsZero() {
+		add("Last modified", o.Modified.UTC().Format("Jan 2, 2006"))
+	}
+	out := "<p>" + strings.Join(rows, "<br>") + "</p>"
+	// Search results carry the description only when SAM.gov returns it inline, not as a link.
+	if o.Description != "" {
+		out += "<p>" + html.EscapeString(truncateText(o.Description, 1000)) + "</p>"
+	}
+	return out
+}
+
+// requestURL reconstructs the URL a request was made to, without its query, which may
+// hold a feed token.
+func requestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + r.URL.Path
+}
+
+// truncateText shortens s to at most n runes, marking the cut with an ellipsis.
+func truncateText(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
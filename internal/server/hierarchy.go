@@ -0,0 +1,186 @@
+package server
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"sam-mcp/internal/mcp"
+	"sam-mcp/internal/sam"
+)
+
+const (
+	// orgIndexKey caches the department and sub-tier levels of the Federal Hierarchy.
+	orgIndexKey = "sam_hierarchy:index"
+	// orgIndexTTL is how long the hierarchy index is reused; it changes rarely.
+	orgIndexTTL = 24 * time.Hour
+	// orgIndexMaxPages bounds how many pages are fetched per hierarchy level.
+	orgIndexMaxPages = 20
+	// orgResolveThreshold is the minimum score for sam_search to substitute an org code.
+	orgResolveThreshold = 0.6
+	// orgIndexLoadTimeout bounds a hierarchy index load, which outlives the request that
+	// started it.
+	orgIndexLoadTimeout = 2 * time.Minute
+)
+
+// orgIndexLoad coalesces concurrent hierarchy index loads into one upstream fetch.
+type orgIndexLoad struct {
+	mu       sync.Mutex
+	inFlight *orgIndexCall
+}
+
+// orgIndexCall is one index load; done is closed once orgs and err are set.
+type orgIndexCall struct {
+	done chan struct{}
+	orgs []sam.Organization
+	err  error
+}
+
+// resolveOrgArgs are the sam_resolve_organization arguments.
+type resolveOrgArgs struct {
+	Name           string `json:"name" jsonschema:"required"`
+	Limit          int    `json:"limit" jsonschema:"min=1,max=25,default=5"`
+	IncludeOffices bool   `json:"includeOffices" description:"Also search offices below the sub-tier level"`
+}
+
+// resolveOrgResult is the sam_resolve_organization response.
+type resolveOrgResult struct {
+	Matches []sam.OrgMatch `json:"matches"`
+	SamEnv  string         `json:"samEnv"`
+}
+
+// toolResolveOrganization serves the sam_resolve_organization tool: fuzzy matches a
+// department, sub-tier or office name to Federal Hierarchy codes and parent path.
+func (s *Server) toolResolveOrganization(ctx context.Context, a resolveOrgArgs) (*resolveOrgResult, error) {
+	if strings.TrimSpace(a.Name) == "" {
+		return nil, mcp.InvalidParams("name is required", nil)
+	}
+	matches, err := s.resolveOrganization(ctx, a.Name, a.Limit, a.IncludeOffices)
+	if err != nil {
+		return nil, mcp.Upstream("sam api error: %v", err)
+	}
+	return &resolveOrgResult{Matches: matches, SamEnv: s.samEnv}, nil
+}
+
+// orgIndex returns the cached department and sub-tier organizations, loading them on first use.
+func (s *Server) orgIndex(ctx context.Context) ([]sam.Organization, error) {
+	if v, ok := s.cache.Get(orgIndexKey); ok {
+		return v.([]sam.Organization), nil
+	}
+	return s.loadOrgIndex(ctx)
+}
+
+// loadOrgIndex refreshes the cached hierarchy index, joining a load already in flight.
+// The hierarchy_refresh job calls it directly so lookups never wait on a cold index.
+func (s *Server) loadOrgIndex(ctx context.Context) ([]sam.Organization, error) {
+	c := s.startOrgIndexLoad()
+	select {
+	case <-c.done:
+		return c.orgs, c.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// startOrgIndexLoad returns the index load in flight, starting one if there is none. The
+// load runs detached from any request so a cancelled caller doesn't abort it for the others.
+func (s *Server) startOrgIndexLoad() *orgIndexCall {
+	s.orgLoad.mu.Lock()
+	defer s.orgLoad.mu.Unlock()
+	if c := s.orgLoad.inFlight; c != nil {
+		return c
+	}
+	c := &orgIndexCall{done: make(chan struct{})}
+	s.orgLoad.inFlight = c
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), orgIndexLoadTimeout)
+		defer cancel()
+		c.orgs, c.err = s.fetchOrgIndex(ctx)
+		if c.err != nil {
+			log.Printf("WARN: hierarchy index load failed: %v", c.err)
+		}
+		s.orgLoad.mu.Lock()
+		s.orgLoad.inFlight = nil
+		s.orgLoad.mu.Unlock()
+		close(c.done)
+	}()
+	return c
+}
+
+// fetchOrgIndex fetches the department and sub-tier levels of the hierarchy and caches them.
+func (s *Server) fetchOrgIndex(ctx context.Context) ([]sam.Organization, error) {
+	var orgs []sam.Organization
+	for level := 1; level <= 2; level++ {
+		seen := 0
+		for page := 0; page < orgIndexMaxPages; page++ {
+			res, err := s.sam.SearchOrganizations(ctx, sam.OrgParams{Level: level, Offset: seen})
+			if err != nil {
+				return nil, err
+			}
+			orgs = append(orgs, res.Organizations...)
+			seen += len(res.Organizations)
+			if len(res.Organizations) == 0 || seen >= res.TotalRecords {
+				break
+			}
+		}
+	}
+	s.cache.Set(orgIndexKey, orgs, orgIndexTTL)
+	return orgs, nil
+}
+
+// resolveOrganization ranks the cached hierarchy against name. Offices are only searched
+// (by name, through the API) when requested or when no department or sub-tier matches.
+func (s *Server) resolveOrganization(ctx context.Context, name string, limit int, includeOffices bool) ([]sam.OrgMatch, error) {
+	orgs, err := s.orgIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	matches := sam.MatchOrganizations(name, orgs, limit)
+	if !includeOffices && len(matches) > 0 {
+		return matches, nil
+	}
+	cacheKey := "sam_hierarchy:name:" + strings.ToLower(name)
+	var found []sam.Organization
+	if v, ok := s.cache.Get(cacheKey); ok {
+		found = v.([]sam.Organization)
+	} else {
+		res, err := s.sam.SearchOrganizations(ctx, sam.OrgParams{Name: name})
+		if err != nil {
+			return nil, err
+		}
+		found = res.Organizations
+		s.cache.Set(cacheKey, found, orgIndexTTL)
+	}
+	return sam.MatchOrganizations(name, append(append([]sam.Organization{}, orgs...), found...), limit), nil
+}
+
+// resolveSearchOrg replaces a human organization name in p with its Federal Hierarchy id.
+// Codes pass through untouched; when nothing matches well the name is sent as-is.
+func (s *Server) resolveSearchOrg(ctx context.Context, p *sam.SearchParams) *sam.OrgMatch {
+	if p.Org == "" || p.OrgCode != "" {
+		return nil
+	}
+	if isOrgCode(p.Org) {
+		p.OrgCode = p.Org
+		return nil
+	}
+	matches, err := s.resolveOrganization(ctx, p.Org, 1, false)
+	if err != nil {
+		log.Printf("WARN: organization %q not resolved: %v", p.Org, err)
+		return nil
+	}
+	if len(matches) == 0 || matches[0].Score < orgResolveThreshold {
+		return nil
+	}
+	p.OrgCode = matches[0].Organization.ID
+	return &matches[0]
+}
+
+// isOrgCode reports whether s looks like a Federal Hierarchy organization id: nine digits
+// from 100000000 up. Shorter all-digit values such as the agency code "9700" or the CGAC
+// "097" are left for resolveOrganization, which matches them against the index.
+func isOrgCode(s string) bool {
+	return len(s) == 9 && s[0] != '0' && isDigits(s)
+}
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"sam-mcp/internal/notify"
+	"sam-mcp/internal/savedsearch"
+	"sam-mcp/internal/scheduler"
+)
+
+// Background job names, usable as the job parameter of POST /mcp/scheduled. Each prefetch
+// profile runs as its own job, named by profileJob.
+const (
+	jobPrefetch         = "prefetch"
+	jobHierarchyRefresh = "hierarchy_refresh"
+	jobEmailDigest      = "email_digest"
+)
+
+// newScheduler registers the background jobs. A job whose schedule does not parse is kept
+// as manual-only; cmd/sam-mcp-http validates schedules at startup.
+func (s *Server) newScheduler() *scheduler.Scheduler {
+	sched := scheduler.New(nil)
+	var jobs []scheduler.Job
+	for _, p := range s.prefetchProfiles() {
+		jobs = append(jobs, scheduler.Job{Name: profileJob(p.Name), Spec: p.Schedule, Jitter: s.cfg.SchedulerJitter, Run: s.prefetchJob(p)})
+	}
+	jobs = append(jobs, scheduler.Job{Name: jobHierarchyRefresh, Spec: s.cfg.HierarchySchedule, Jitter: s.cfg.SchedulerJitter, Run: s.refreshHierarchy})
+	if s.deliveries.Handles(savedsearch.TargetEmail) {
+		jobs = append(jobs, scheduler.Job{Name: jobEmailDigest, Spec: s.cfg.DigestSchedule, Run: s.sendDigests})
+	}
+	for _, j := range jobs {
+		if err := sched.Add(j); err != nil {
+			log.Printf("WARN: %v; %s runs only when triggered", err, j.Name)
+			j.Spec = ""
+			_ = sched.Add(j)
+		}
+	}
+	return sched
+}
+
+// Start runs the scheduled background jobs and the notification queue until ctx is
+// cancelled, and then ends open event streams so the HTTP server can shut down.
+func (s *Server) Start(ctx context.Context) {
+	s.scheduler.Start(ctx)
+	go s.deliveries.Run(ctx)
+	go s.runDescriptions(ctx)
+	go func() {
+		<-ctx.Done()
+		s.events.shutdown()
+	}()
+}
+
+// prefetchJob warms the cache for a profile's search using the same cache key scheme as
+// sam_search, records the results for sam_whats_new and the profile's notification
+// targets, and notifies subscribers of sam://search/{profile} when the results change.
+func (s *Server) prefetchJob(p PrefetchProfile) func(context.Context) error {
+	return func(ctx context.Context) error {
+		cacheKey := searchCacheKey(p.Search)
+		prev, _ := s.cache.Get(cacheKey)
+		res, err := s.fetchAndCacheSamData(ctx, cacheKey, p.Search, p.TTL)
+		if err != nil {
+			return err
+		}
+		s.recordRun(profileJob(p.Name), notify.Source{Kind: notify.SourceProfile, ID: p.Name, Name: p.Description}, p.Notify, res.Results)
+		if prevRes, ok := prev.(*searchResult); !ok || resultsChanged(prevRes.Results, res.Results) {
+			s.events.resourceUpdated(searchURIPrefix + p.Name)
+		}
+		return nil
+	}
+}
+
+// refreshHierarchy reloads the cached Federal Hierarchy index used to resolve organizations.
+func (s *Server) refreshHierarchy(ctx context.Context) error {
+	_, err := s.loadOrgIndex(ctx)
+	return err
+}
+
+// handleScheduled runs a background job immediately: every prefetch profile by default,
+// one profile with ?profile=, or another job with ?job=. The in-process scheduler runs the
+// same jobs on their schedules; this is the manual trigger.
+func (s *Server) handleScheduled(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("job")
+	if name == "" || name == jobPrefetch {
+		s.runProfiles(w, r, r.URL.Query().Get("profile"))
+		return
+	}
+	run, err := s.scheduler.RunNow(r.Context(), name)
+	switch {
+	case errors.Is(err, scheduler.ErrUnknownJob):
+		http.Error(w, "unknown job: "+name, http.StatusNotFound)
+		return
+	case errors.Is(err, scheduler.ErrRunning):
+		http.Error(w, name+" is already running", http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, "sam api error during "+name+": "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": s.completed(name), "run": run})
+}
+
+// profileRun reports one profile's manual prefetch.
+type profileRun struct {
+	Profile string        `json:"profile"`
+	Results int           `json:"results"`
+	Error   string        `json:"error,omitempty"`
+	Run     scheduler.Run `json:"run"`
+}
+
+// runProfiles prefetches one profile, or all of them when name is empty, one at a time.
+// A profile that is already running is reported and skipped; any SAM.gov failure makes
+// the response a 502 that still carries every profile's outcome.
+func (s *Server) runProfiles(w http.ResponseWriter, r *http.Request, name string) {
+	profiles := s.prefetchProfiles()
+	if name != "" {
+		p, ok := findProfile(profiles, name)
+		if !ok {
+			http.Error(w, "unknown profile: "+name, http.StatusNotFound)
+			return
+		}
+		profiles = []PrefetchProfile{p}
+	}
+
+	status, failed := http.StatusOK, 0
+	runs := make([]profileRun, 0, len(profiles))
+	for _, p := range profiles {
+		run, err := s.scheduler.RunNow(r.Context(), profileJob(p.Name))
+		pr := profileRun{Profile: p.Name, Run: run}
+		switch {
+		case errors.Is(err, scheduler.ErrRunning):
+			pr.Error = "already running"
+			if name != "" {
+				status = http.StatusConflict
+			}
+		case err != nil:
+			pr.Error = "sam api error: " + err.Error()
+			failed++
+			status = http.StatusBadGateway
+		default:
+			if v, ok := s.cache.Get(searchCacheKey(p.Search)); ok {
+				pr.Results = len(v.(*searchResult).Results)
+			}
+		}
+		runs = append(runs, pr)
+	}
+
+	statusMsg := s.completed(jobPrefetch)
+	if failed > 0 {
+		statusMsg = fmt.Sprintf("%s failed for %d of %d profiles", jobPrefetch, failed, len(profiles))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": statusMsg, "profiles": runs})
+}
+
+// completed is the status message for a successful manual run.
+func (s *Server) completed(job string) string {
+	if s.cfg.SamAPIKey == "" {
+		return job + " completed (mock)"
+	}
+	return job + " completed"
+}
+
+// handleScheduleStatus reports each background job's schedule, next run and run history.
+func (s *Server) handleScheduleStatus(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"jobs": s.scheduler.Status()})
+}
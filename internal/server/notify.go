@@ -0,0 +1,148 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"sam-mcp/internal/notify"
+	"sam-mcp/internal/sam"
+	"sam-mcp/internal/savedsearch"
+	"sam-mcp/internal/snapshot"
+)
+
+// Delivery history listing limits for GET /mcp/deliveries.
+const (
+	defaultDeliveryLimit = 50
+	maxDeliveryLimit     = 500
+)
+
+// recordRun snapshots a profile or saved search run for sam_whats_new and queues an event
+// with the notices it added or amended for each notification target. Email recipients get
+// the event in their next digest instead. A source's first run only sets the baseline, so
+// that a restart without DATA_DIR does not resend every notice.
+func (s *Server) recordRun(key string, src notify.Source, targets []savedsearch.Target, opps []sam.Opportunity) {
+	baseline := s.snapshots.LastRun(key).IsZero()
+	now := time.Now()
+	changes, err := s.snapshots.Record(key, opps, now)
+	if err != nil {
+		log.Printf("WARN: snapshot of %s not saved: %v", key, err)
+	}
+	recipients := s.digestRecipients(targets)
+	if baseline || (len(targets) == 0 && len(recipients) == 0) {
+		return
+	}
+	notices := changedNotices(changes, opps)
+	if len(notices) == 0 {
+		return
+	}
+	ev := notify.NewEvent(src, now, notices)
+	ev.SamEnv = s.samEnv
+	for _, t := range targets {
+		if t.Type == savedsearch.TargetEmail && len(recipients) > 0 {
+			continue
+		}
+		if _, err := s.deliveries.Enqueue(notify.Target(t), ev); err != nil {
+			log.Printf("WARN: %s notification of %s not queued: %v", t.Type, key, err)
+		}
+	}
+	for _, rcpt := range recipients {
+		if err := s.digests.Add(rcpt, ev); err != nil {
+			log.Printf("WARN: digest for %s of %s not saved: %v", rcpt, key, err)
+		}
+	}
+}
+
+// changedNotices pairs the added and amended changes of a run with the opportunities the
+// run returned.
+func changedNotices(changes []snapshot.Change, opps []sam.Opportunity) []notify.Notice {
+	byID := make(map[string]sam.Opportunity, len(opps))
+	for _, o := range opps {
+		o.Raw = nil
+		byID[o.NoticeID] = o
+	}
+	out := make([]notify.Notice, 0, len(changes))
+	for _, c := range changes {
+		o, ok := byID[c.NoticeID]
+		if !ok || (c.Kind != snapshot.Added && c.Kind != snapshot.Amended) {
+			continue
+		}
+		out = append(out, notify.Notice{Change: c.Kind, Fields: c.Fields, Opportunity: o})
+	}
+	return out
+}
+
+// deliverySummary is a delivery in the history listing, without its payload.
+type deliverySummary struct {
+	ID          string           `json:"id"`
+	Target      notify.Target    `json:"target"`
+	Status      string           `json:"status"`
+	EventID     string           `json:"eventId"`
+	Source      notify.Source    `json:"source"`
+	Notices     int              `json:"notices"`
+	Created     time.Time        `json:"created"`
+	NextAttempt *time.Time       `json:"nextAttempt,omitempty"`
+	Tries       int              `json:"tries"`
+	Attempts    []notify.Attempt `json:"attempts"`
+}
+
+// handleListDeliveries serves GET /mcp/deliveries: the delivery history, newest first,
+// optionally filtered by ?status= (pending, delivered, dead) and capped by ?limit=.
+func (s *Server) handleListDeliveries(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", notify.StatusPending, notify.StatusDelivered, notify.StatusDead:
+	default:
+		http.Error(w, "unknown status: "+status, http.StatusBadRequest)
+		return
+	}
+	limit := defaultDeliveryLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "invalid limit: "+v, http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxDeliveryLimit)
+	}
+	out := make([]deliverySummary, 0)
+	for _, d := range s.deliveries.List(status, limit) {
+		out = append(out, deliverySummary{
+			ID: d.ID, Target: d.Target, Status: d.Status, EventID: d.Event.ID, Source: d.Event.Source, Notices: len(d.Event.Notices),
+			Created: d.Created, NextAttempt: d.NextAttempt, Tries: d.Tries, Attempts: d.Attempts,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"counts": s.deliveries.Counts(), "deliveries": out})
+}
+
+// handleGetDelivery serves GET /mcp/deliveries/{id}, including the payload.
+func (s *Server) handleGetDelivery(w http.ResponseWriter, r *http.Request) {
+	d, ok := s.deliveries.Get(chi.URLParam(r, "id"))
+	if !ok {
+		http.Error(w, "unknown delivery", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(d)
+}
+
+// handleRetryDelivery serves POST /mcp/deliveries/{id}/retry, requeueing a dead letter.
+func (s *Server) handleRetryDelivery(w http.ResponseWriter, r *http.Request) {
+	d, err := s.deliveries.Retry(chi.URLParam(r, "id"))
+	switch {
+	case errors.Is(err, notify.ErrNotFound):
+		http.Error(w, "unknown delivery", http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(d)
+}
@@ -0,0 +1,150 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"sam-mcp/internal/sam"
+	"sam-mcp/internal/savedsearch"
+	"sam-mcp/internal/scheduler"
+)
+
+// PrefetchProfile is a named search kept warm in the cache by the scheduler. Its results
+// are readable as sam://search/{name}.
+type PrefetchProfile struct {
+	Name        string
+	Description string
+	Search      sam.SearchParams
+	// Schedule is a cron spec (see internal/scheduler); empty means manual runs only.
+	Schedule string
+	// TTL is how long the profile's results stay cached.
+	TTL time.Duration
+	// Notify lists where new and amended notices found by the profile's runs are sent.
+	Notify []savedsearch.Target
+}
+
+// profileFile is the on-disk shape of PREFETCH_PROFILES_FILE.
+type profileFile struct {
+	Profiles []struct {
+		Name        string               `json:"name"`
+		Description string               `json:"description"`
+		Search      sam.SearchParams     `json:"search"`
+		Schedule    *string              `json:"schedule"`
+		TTL         string               `json:"ttl"`
+		Notify      []savedsearch.Target `json:"notify"`
+	} `json:"profiles"`
+}
+
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// LoadProfiles reads prefetch profiles from a JSON file, or a YAML one when the name ends in
+// .yaml or .yml. A profile without a schedule uses defaultSchedule ("off" disables
+// scheduling), and one without a ttl is cached for 12h. An empty path yields no profiles.
+func LoadProfiles(path, defaultSchedule string) ([]PrefetchProfile, error) {
+	if path == "" {
+		return nil, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".yaml" || ext == ".yml" {
+		if raw, err = yamlToJSON(raw); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	var file profileFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	seen := make(map[string]bool)
+	out := make([]PrefetchProfile, 0, len(file.Profiles))
+	for i, fp := range file.Profiles {
+		if !profileNamePattern.MatchString(fp.Name) {
+			return nil, fmt.Errorf("%s: profile %d: name %q must be lowercase letters, digits, '-' or '_'", path, i+1, fp.Name)
+		}
+		if seen[fp.Name] {
+			return nil, fmt.Errorf("%s: duplicate profile %q", path, fp.Name)
+		}
+		seen[fp.Name] = true
+		p := PrefetchProfile{Name: fp.Name, Description: fp.Description, Search: fp.Search, Schedule: defaultSchedule, TTL: searchTTL, Notify: fp.Notify}
+		if fp.Schedule != nil {
+			p.Schedule = *fp.Schedule
+		}
+		if strings.EqualFold(p.Schedule, "off") {
+			p.Schedule = ""
+		}
+		if p.Schedule != "" {
+			if _, err := scheduler.Parse(p.Schedule); err != nil {
+				return nil, fmt.Errorf("%s: profile %s: %w", path, p.Name, err)
+			}
+		}
+		if fp.TTL != "" {
+			if p.TTL, err = time.ParseDuration(fp.TTL); err != nil || p.TTL <= 0 {
+				return nil, fmt.Errorf("%s: profile %s: invalid ttl %q", path, p.Name, fp.TTL)
+			}
+		}
+		if p.Search.Days <= 0 {
+			return nil, fmt.Errorf("%s: profile %s: search.days must be positive", path, p.Name)
+		}
+		if issues, _ := validateNAICS(p.Search.NAICS); len(issues) > 0 {
+			return nil, fmt.Errorf("%s: profile %s: naics %s: %s", path, p.Name, issues[0].Code, issues[0].Reason)
+		}
+		if err := savedsearch.ValidateTargets(p.Notify); err != nil {
+			return nil, fmt.Errorf("%s: profile %s: %w", path, p.Name, err)
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// yamlToJSON re-encodes a YAML document as JSON, so YAML profile files decode through the
+// same json tags and checks as JSON ones.
+func yamlToJSON(raw []byte) ([]byte, error) {
+	var v interface{}
+	if err := yaml.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// prefetchProfiles combines the PREFETCH_* search, exposed as the "prefetch" profile, with
+// the configured profiles; a configured profile named "prefetch" replaces it.
+func (s *Server) prefetchProfiles() []PrefetchProfile {
+	out := make([]PrefetchProfile, 0, len(s.cfg.PrefetchProfiles)+1)
+	if _, ok := findProfile(s.cfg.PrefetchProfiles, prefetchSearchID); !ok {
+		out = append(out, PrefetchProfile{
+			Name:        prefetchSearchID,
+			Description: "Configured PREFETCH_* search",
+			Search:      s.prefetchParams(),
+			Schedule:    s.cfg.PrefetchSchedule,
+			TTL:         searchTTL,
+		})
+	}
+	return append(out, s.cfg.PrefetchProfiles...)
+}
+
+// profile returns the prefetch profile with the given name.
+func (s *Server) profile(name string) (PrefetchProfile, bool) {
+	return findProfile(s.prefetchProfiles(), name)
+}
+
+func findProfile(profiles []PrefetchProfile, name string) (PrefetchProfile, bool) {
+	for _, p := range profiles {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return PrefetchProfile{}, false
+}
+
+// profileJob is the scheduler job name for a prefetch profile.
+func profileJob(name string) string {
+	return jobPrefetch + ":" + name
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"sam-mcp/internal/mcp"
+	"sam-mcp/internal/prompts"
+	"sam-mcp/internal/sam"
+)
+
+// loadPrompts loads the built-in prompts and those in dir. A bad directory is logged and
+// only the built-ins are served; cmd/sam-mcp-http validates PROMPTS_DIR at startup.
+func loadPrompts(dir string) *prompts.Set {
+	set, err := prompts.Load(dir)
+	if err == nil {
+		return set
+	}
+	log.Printf("WARN: %v; serving built-in prompts only", err)
+	set, err = prompts.Load("")
+	if err != nil {
+		panic(err)
+	}
+	return set
+}
+
+// promptSource serves template data requests for one prompts/get call.
+type promptSource struct {
+	ctx context.Context
+	s   *Server
+}
+
+func (p promptSource) Opportunity(noticeID string) (*sam.Opportunity, error) {
+	o, err := p.s.opportunity(p.ctx, noticeID)
+	if err != nil {
+		return nil, mcp.Upstream("sam api error: %v", err)
+	}
+	if o == nil {
+		return nil, mcp.InvalidParams("opportunity "+noticeID+" not found", nil)
+	}
+	return o, nil
+}
+
+func (p promptSource) Search(id string) ([]sam.Opportunity, error) {
+	res, err := p.s.savedSearchResults(p.ctx, id)
+	if err != nil {
+		return nil, mcp.Upstream("sam api error: %v", err)
+	}
+	if res == nil {
+		return nil, mcp.InvalidParams("saved search "+id+" not found", nil)
+	}
+	return res.Results, nil
+}
+
+func (s *Server) handleListPrompts(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"prompts": s.prompts.List()})
+}
+
+// getPromptRequest is the prompts/get body.
+type getPromptRequest struct {
+	Name string            `json:"name"`
+	Args map[string]string `json:"arguments"`
+}
+
+func (s *Server) handleGetPrompt(w http.ResponseWriter, r *http.Request) {
+	var req getPromptRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	p, ok := s.prompts.Get(req.Name)
+	if !ok {
+		writeMCPError(w, mcp.InvalidParams("unknown prompt: "+req.Name, nil))
+		return
+	}
+	if missing := p.MissingArguments(req.Args); len(missing) > 0 {
+		writeMCPError(w, mcp.InvalidParams("missing required arguments: "+strings.Join(missing, ", "), map[string]interface{}{"missing": missing}))
+		return
+	}
+	text, err := p.Render(req.Args, promptSource{ctx: r.Context(), s: s})
+	if err != nil {
+		writeMCPError(w, promptError(err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(mcp.GetPromptResult{
+		Description: p.Description,
+		Messages:    []mcp.PromptMessage{{Role: "user", Content: mcp.Content{Type: "text", Text: text}}},
+	})
+}
+
+// promptError keeps the code of an *mcp.Error raised by a template function; other
+// failures are reported as template errors.
+func promptError(err error) error {
+	var me *mcp.Error
+	if errors.As(err, &me) {
+		return me
+	}
+	return &mcp.Error{Code: mcp.CodeInternal, Message: fmt.Sprintf("prompt template: %v", err)}
+}
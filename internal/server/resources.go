@@ -0,0 +1,249 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"sam-mcp/internal/mcp"
+	"sam-mcp/internal/sam"
+)
+
+const (
+	opportunityURIPrefix = "sam://opportunity/"
+	searchURIPrefix      = "sam://search/"
+	// opportunityKeyPrefix caches each opportunity seen in search results by notice id.
+	opportunityKeyPrefix = "sam_opportunity:"
+	// opportunityTTL matches the search result cache.
+	opportunityTTL = 12 * time.Hour
+	// prefetchSearchID names the configured PREFETCH_* search as a search resource and
+	// prefetch profile.
+	prefetchSearchID = "prefetch"
+	// noticeLookbackDays is the posted-date window used when reading a single notice.
+	noticeLookbackDays = 365
+	jsonMimeType       = "application/json"
+)
+
+var resourceTemplates = []mcp.ResourceTemplate{
+	{
+		URITemplate: opportunityURIPrefix + "{noticeId}",
+		Name:        "opportunity",
+		Title:       "SAM.gov opportunity",
+		Description: "A contract opportunity by notice id, as returned by sam_search",
+		MimeType:    jsonMimeType,
+	},
+	{
+		URITemplate: searchURIPrefix + "{savedSearchId}",
+		Name:        "search",
+		Title:       "Saved search results",
+		Description: "The latest results of a prefetch profile (\"prefetch\" is the configured PREFETCH_* search) or saved search",
+		MimeType:    jsonMimeType,
+	},
+}
+
+// rememberOpportunities caches each opportunity by notice id so it can be read as a resource,
+// notifying subscribers when a known notice was amended and everyone when new notices appear.
+func (s *Server) rememberOpportunities(opps []sam.Opportunity) {
+	added := false
+	for _, o := range opps {
+		if o.NoticeID == "" {
+			continue
+		}
+		key := opportunityKeyPrefix + o.NoticeID
+		if v, ok := s.cache.Get(key); ok {
+			if prev := v.(sam.Opportunity); !prev.Modified.Equal(o.Modified) {
+				s.events.resourceUpdated(opportunityURIPrefix + o.NoticeID)
+			}
+		} else {
+			added = true
+		}
+		s.cache.Set(key, o, opportunityTTL)
+	}
+	if added {
+		s.events.resourceListChanged()
+	}
+}
+
+// cachedOpportunities returns the opportunities currently cached, most recently modified first.
+func (s *Server) cachedOpportunities() []sam.Opportunity {
+	vals := s.cache.Values(opportunityKeyPrefix)
+	out := make([]sam.Opportunity, 0, len(vals))
+	for _, v := range vals {
+		out = append(out, v.(sam.Opportunity))
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].Modified.Equal(out[j].Modified) {
+			return out[i].Modified.After(out[j].Modified)
+		}
+		return out[i].NoticeID < out[j].NoticeID
+	})
+	return out
+}
+
+// opportunity returns a notice from the cache, falling back to a SAM search by notice id.
+func (s *Server) opportunity(ctx context.Context, noticeID string) (*sam.Opportunity, error) {
+	if v, ok := s.cache.Get(opportunityKeyPrefix + noticeID); ok {
+		o := v.(sam.Opportunity)
+		return &o, nil
+	}
+	res, err := s.sam.Search(ctx, sam.SearchParams{NoticeID: noticeID, Days: noticeLookbackDays, Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	enrichCodes(res)
+	s.rememberOpportunities(res)
+	s.recordVersions(res)
+	for i := range res {
+		if res[i].NoticeID == noticeID {
+			return &res[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// savedSearchResults returns the cached results of a prefetch profile or saved search,
+// running its search if needed. It returns nil for an unknown id.
+func (s *Server) savedSearchResults(ctx context.Context, id string) (*searchResult, error) {
+	p, ok := s.profile(id)
+	if !ok {
+		ss, found := s.savedSearches.Get(id)
+		if !found {
+			return nil, nil
+		}
+		p = PrefetchProfile{Name: ss.ID, Search: ss.Params, TTL: searchTTL}
+	}
+	cacheKey := searchCacheKey(p.Search)
+	return cached(s, cacheKey, p.TTL, func() (*searchResult, error) {
+		return s.fetchAndCacheSamData(ctx, cacheKey, p.Search, p.TTL)
+	})
+}
+
+func (s *Server) handleListResources(w http.ResponseWriter, _ *http.Request) {
+	var resources []mcp.Resource
+	for _, p := range s.prefetchProfiles() {
+		resources = append(resources, mcp.Resource{
+			URI:         searchURIPrefix + p.Name,
+			Name:        p.Name,
+			Title:       "Prefetch profile " + p.Name,
+			Description: firstNonEmpty(p.Description, "Latest results of the "+p.Name+" prefetch profile"),
+			MimeType:    jsonMimeType,
+		})
+	}
+	for _, ss := range s.savedSearches.List("") {
+		resources = append(resources, mcp.Resource{
+			URI:         searchURIPrefix + ss.ID,
+			Name:        ss.ID,
+			Title:       ss.Name,
+			Description: joinNonEmpty("Saved search", prefixed("owned by", ss.Owner)),
+			MimeType:    jsonMimeType,
+		})
+	}
+	for _, o := range s.cachedOpportunities() {
+		resources = append(resources, mcp.Resource{
+			URI:         opportunityURIPrefix + o.NoticeID,
+			Name:        o.NoticeID,
+			Title:       o.Title,
+			Description: joinNonEmpty(o.Agency, prefixed("modified", dateText(o.Modified))),
+			MimeType:    jsonMimeType,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"resources": resources})
+}
+
+func (s *Server) handleListResourceTemplates(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"resourceTemplates": resourceTemplates})
+}
+
+// resourceRequest is the body of resources/read, resources/subscribe and resources/unsubscribe.
+type resourceRequest struct {
+	URI       string `json:"uri"`
+	SessionID string `json:"sessionId"`
+}
+
+func (s *Server) handleReadResource(w http.ResponseWriter, r *http.Request) {
+	var req resourceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	v, err := s.readResource(r.Context(), req.URI)
+	if err != nil {
+		writeMCPError(w, err)
+		return
+	}
+	text, err := json.Marshal(v)
+	if err != nil {
+		writeMCPError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"contents": []mcp.ResourceContents{{URI: req.URI, MimeType: jsonMimeType, Text: string(text)}},
+	})
+}
+
+// readResource resolves a sam:// URI to the value served as its contents.
+func (s *Server) readResource(ctx context.Context, uri string) (interface{}, error) {
+	notFound := &mcp.Error{Code: mcp.CodeResourceNotFound, Message: "resource not found", Data: map[string]string{"uri": uri}}
+	switch {
+	case strings.HasPrefix(uri, opportunityURIPrefix):
+		id := strings.TrimPrefix(uri, opportunityURIPrefix)
+		if id == "" {
+			return nil, notFound
+		}
+		o, err := s.opportunity(ctx, id)
+		if err != nil {
+			return nil, mcp.Upstream("sam api error: %v", err)
+		}
+		if o == nil {
+			return nil, notFound
+		}
+		return o, nil
+	case strings.HasPrefix(uri, searchURIPrefix):
+		res, err := s.savedSearchResults(ctx, strings.TrimPrefix(uri, searchURIPrefix))
+		if err != nil {
+			return nil, mcp.Upstream("sam api error: %v", err)
+		}
+		if res == nil {
+			return nil, notFound
+		}
+		return res, nil
+	}
+	return nil, notFound
+}
+
+func (s *Server) handleSubscribeResource(w http.ResponseWriter, r *http.Request) {
+	s.setSubscription(w, r, true)
+}
+
+func (s *Server) handleUnsubscribeResource(w http.ResponseWriter, r *http.Request) {
+	s.setSubscription(w, r, false)
+}
+
+// setSubscription (un)subscribes the /mcp/events session named by the Mcp-Session-Id header
+// or the sessionId body field to a resource URI.
+func (s *Server) setSubscription(w http.ResponseWriter, r *http.Request, on bool) {
+	var req resourceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if id := r.Header.Get("Mcp-Session-Id"); id != "" {
+		req.SessionID = id
+	}
+	if !strings.HasPrefix(req.URI, opportunityURIPrefix) && !strings.HasPrefix(req.URI, searchURIPrefix) {
+		writeMCPError(w, mcp.InvalidParams("uri must be a sam://opportunity/ or sam://search/ resource", nil))
+		return
+	}
+	if !s.events.subscribe(req.SessionID, req.URI, on) {
+		writeMCPError(w, mcp.InvalidParams("unknown session; open GET /mcp/events first", nil))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{})
+}
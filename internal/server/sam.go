@@ -1,64 +1,158 @@
 package server
 
 import (
-	"encoding/json"
-	"fmt"
-	"net/http"
-	"net/url"
-)
+	"context"
+	"strings"
+	"time"
 
-const samAPIBaseURL = "https://api.sam.gov/prod/opportunities/v2/search"
+	"sam-mcp/internal/sam"
+)
 
-// Opportunity represents a single SAM.gov opportunity entry returned by the API.
-type Opportunity struct {
-	Title    string `json:"title"`
-	Agency   string `json:"agency"`
-	Modified string `json:"modifiedDate"`
-	URL      string `json:"url"`
+// SamClient is the subset of the SAM.gov client used by the server's handlers.
+// *sam.Client satisfies it; tests inject fakes through WithSamClient.
+type SamClient interface {
+	Search(ctx context.Context, p sam.SearchParams) ([]sam.Opportunity, error)
+	LookupEntity(ctx context.Context, p sam.EntityParams) (*sam.EntityPage, error)
+	CheckExclusions(ctx context.Context, queries []sam.ExclusionQuery) ([]sam.ExclusionResult, error)
+	SearchContractAwards(ctx context.Context, p sam.AwardParams) (*sam.AwardPage, error)
+	SearchOrganizations(ctx context.Context, p sam.OrgParams) (*sam.OrgPage, error)
+	SearchAssistance(ctx context.Context, p sam.AssistanceParams) (*sam.AssistancePage, error)
+	NoticeDescription(ctx context.Context, descriptionURL string) (string, error)
 }
 
-// SamSearchResults is the top-level shape from SAM.gov for the opportunities search.
-type SamSearchResults struct {
-	Results []Opportunity `json:"opportunitiesData"`
-}
+// samEnvMock is reported as the SAM environment when canned data is served.
+const samEnvMock = "mock"
 
-// SearchOpportunities queries the public SAM.gov opportunities API with the provided params.
-func SearchOpportunities(apiKey string, params map[string]interface{}) (*SamSearchResults, error) {
-	if apiKey == "" {
-		return nil, fmt.Errorf("SAM_API_KEY not set")
+// resolveSamEnv returns the environment name and API host for cfg. main validates SAM_ENV
+// at startup, so an unknown name here is reported as-is against the production host.
+func resolveSamEnv(cfg Config) (string, string) {
+	env, baseURL, err := sam.ResolveEnvironment(cfg.SamEnv, cfg.SamBaseURL)
+	if err != nil {
+		return cfg.SamEnv, sam.DefaultBaseURL
 	}
+	return env, baseURL
+}
 
-	q := url.Values{}
-	q.Set("api_key", apiKey)
+// mockSamClient serves canned data when SAM_API_KEY is not configured.
+type mockSamClient struct{}
 
-	if val, ok := params["q"].(string); ok {
-		q.Set("q", val)
+func (mockSamClient) Search(_ context.Context, p sam.SearchParams) ([]sam.Opportunity, error) {
+	opp := sam.Opportunity{NoticeID: "mock-0001", Title: "Example Opportunity", Agency: "GSA", Modified: time.Now().UTC().Truncate(24 * time.Hour), URL: "https://sam.gov/opp/example", NAICS: "541511", PSC: "DA01"}
+	if p.NoticeID != "" && p.NoticeID != opp.NoticeID {
+		return []sam.Opportunity{}, nil
 	}
-	if val, ok := params["limit"].(float64); ok {
-		q.Set("limit", fmt.Sprintf("%.0f", val))
+	return []sam.Opportunity{opp}, nil
+}
+
+func (mockSamClient) LookupEntity(_ context.Context, p sam.EntityParams) (*sam.EntityPage, error) {
+	e := sam.Entity{
+		UEI:                "EXAMPLEUEI12",
+		CAGE:               "1ABC2",
+		LegalName:          "Example Federal Services LLC",
+		RegistrationStatus: "Active",
+		RegistrationDate:   time.Date(2020, 1, 15, 0, 0, 0, 0, time.UTC),
+		ExpirationDate:     time.Now().UTC().AddDate(0, 6, 0).Truncate(24 * time.Hour),
+		PrimaryNAICS:       "541511",
+		NAICS:              []sam.EntityNAICS{{Code: "541511", Description: "Custom Computer Programming Services", SmallBusiness: true}},
+		BusinessTypes:      []sam.BusinessType{{Code: "2X", Description: "For Profit Organization"}},
+		PointsOfContact:    []sam.PointOfContact{{Role: "government_business", FirstName: "Jane", LastName: "Doe"}},
+	}
+	if p.UEI != "" {
+		e.UEI = p.UEI
 	}
-	// Add other parameters as needed
+	return &sam.EntityPage{TotalRecords: 1, Page: p.Page, Size: 1, Entities: []sam.Entity{e}}, nil
+}
 
-	req, err := http.NewRequest("GET", samAPIBaseURL+"?"+q.Encode(), nil)
-	if err != nil {
-		return nil, err
+// CheckExclusions reports every entity as clear except names containing "excluded",
+// which lets the annotation paths be exercised without live data.
+func (mockSamClient) CheckExclusions(_ context.Context, queries []sam.ExclusionQuery) ([]sam.ExclusionResult, error) {
+	out := make([]sam.ExclusionResult, 0, len(queries))
+	for _, q := range queries {
+		res := sam.ExclusionResult{Query: q, Exclusions: []sam.Exclusion{}}
+		if strings.Contains(strings.ToLower(q.Name), "excluded") {
+			res.Excluded = true
+			res.Exclusions = append(res.Exclusions, sam.Exclusion{
+				Name: q.Name, Type: "Ineligible (Proceedings Completed)", AgencyCode: "GSA", AgencyName: "General Services Administration",
+				ActiveDate: time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC), TerminationType: "Indefinite", RecordStatus: "Active",
+			})
+		}
+		out = append(out, res)
 	}
+	return out, nil
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
+func (mockSamClient) SearchContractAwards(_ context.Context, p sam.AwardParams) (*sam.AwardPage, error) {
+	a := sam.ContractAward{
+		PIID:               "47QTCA20D0001",
+		SolicitationNumber: "47QTCA19R0001",
+		AgencyCode:         "4700",
+		AgencyName:         "General Services Administration",
+		NAICS:              "541511",
+		NAICSDescription:   "Custom Computer Programming Services",
+		Vendor:             sam.Vendor{Name: "Example Federal Services LLC", UEI: "EXAMPLEUEI12", CAGE: "1ABC2"},
+		ActionObligation:   250000,
+		TotalObligated:     1750000,
+		BaseAndAllOptions:  4800000,
+		DateSigned:         time.Date(2020, 9, 28, 0, 0, 0, 0, time.UTC),
+		PeriodStart:        time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:          time.Date(2025, 9, 30, 0, 0, 0, 0, time.UTC),
+		PeriodUltimateEnd:  time.Date(2030, 9, 30, 0, 0, 0, 0, time.UTC),
+	}
+	if p.PIID != "" {
+		a.PIID = p.PIID
+	}
+	if p.SolicitationNumber != "" {
+		a.SolicitationNumber = p.SolicitationNumber
 	}
-	defer resp.Body.Close()
+	return &sam.AwardPage{TotalRecords: 1, Limit: p.Limit, Offset: p.Offset, Awards: []sam.ContractAward{a}}, nil
+}
+
+// mockOrganizations is a small slice of the Federal Hierarchy served without an API key.
+var mockOrganizations = []sam.Organization{
+	{ID: "100000000", Name: "DEPT OF DEFENSE", Type: "Department/Ind. Agency", Level: 1, AgencyCode: "9700", CGAC: "097"},
+	{ID: "100000135", Name: "VETERANS AFFAIRS, DEPARTMENT OF", Type: "Department/Ind. Agency", Level: 1, AgencyCode: "3600", CGAC: "036"},
+	{ID: "100000136", Name: "GENERAL SERVICES ADMINISTRATION", Type: "Department/Ind. Agency", Level: 1, AgencyCode: "4700", CGAC: "047"},
+	{ID: "100006688", Name: "HOMELAND SECURITY, DEPARTMENT OF", Type: "Department/Ind. Agency", Level: 1, AgencyCode: "7000", CGAC: "070"},
+	{ID: "100000001", Name: "DEPT OF THE ARMY", Type: "Sub-Tier", Level: 2, AgencyCode: "2100", ParentPath: []string{"DEPT OF DEFENSE", "DEPT OF THE ARMY"}, ParentIDs: []string{"100000000", "100000001"}},
+	{ID: "100000002", Name: "DEPT OF THE NAVY", Type: "Sub-Tier", Level: 2, AgencyCode: "1700", ParentPath: []string{"DEPT OF DEFENSE", "DEPT OF THE NAVY"}, ParentIDs: []string{"100000000", "100000002"}},
+	{ID: "100000003", Name: "DEPT OF THE AIR FORCE", Type: "Sub-Tier", Level: 2, AgencyCode: "5700", ParentPath: []string{"DEPT OF DEFENSE", "DEPT OF THE AIR FORCE"}, ParentIDs: []string{"100000000", "100000003"}},
+	{ID: "100000137", Name: "FEDERAL ACQUISITION SERVICE", Type: "Sub-Tier", Level: 2, AgencyCode: "4732", ParentPath: []string{"GENERAL SERVICES ADMINISTRATION", "FEDERAL ACQUISITION SERVICE"}, ParentIDs: []string{"100000136", "100000137"}},
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("SAM API request failed with status: %s", resp.Status)
+func (mockSamClient) SearchOrganizations(_ context.Context, p sam.OrgParams) (*sam.OrgPage, error) {
+	out := []sam.Organization{}
+	for _, o := range mockOrganizations {
+		if p.Level > 0 && o.Level != p.Level {
+			continue
+		}
+		if p.Name != "" && !strings.Contains(o.Name, strings.ToUpper(p.Name)) {
+			continue
+		}
+		out = append(out, o)
+	}
+	if p.Offset >= len(out) {
+		return &sam.OrgPage{TotalRecords: len(out), Organizations: []sam.Organization{}}, nil
 	}
+	return &sam.OrgPage{TotalRecords: len(out), Organizations: out[p.Offset:]}, nil
+}
 
-	var results SamSearchResults
-	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
-		return nil, err
+func (mockSamClient) SearchAssistance(_ context.Context, p sam.AssistanceParams) (*sam.AssistancePage, error) {
+	l := sam.AssistanceListing{
+		Number:          "47.070",
+		Title:           "Example Computer and Information Science and Engineering Program",
+		Agency:          "NATIONAL SCIENCE FOUNDATION",
+		Objectives:      "Example objectives for an assistance listing.",
+		AssistanceTypes: []string{"Project Grants"},
+		Eligibility:     []string{"State", "Public nonprofit institution/organization"},
+		Modified:        time.Now().UTC().Truncate(24 * time.Hour),
+		URL:             "https://sam.gov/fal/47.070/view",
 	}
+	if p.ProgramNumber != "" {
+		l.Number = p.ProgramNumber
+	}
+	return &sam.AssistancePage{TotalRecords: 1, Limit: p.Limit, Offset: p.Offset, Listings: []sam.AssistanceListing{l}}, nil
+}
 
-	return &results, nil
+func (mockSamClient) NoticeDescription(_ context.Context, _ string) (string, error) {
+	return "Example description of the requirement.", nil
 }
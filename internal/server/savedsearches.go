@@ -0,0 +1,257 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"sam-mcp/internal/mcp"
+	"sam-mcp/internal/notify"
+	"sam-mcp/internal/sam"
+	"sam-mcp/internal/savedsearch"
+	"sam-mcp/internal/scheduler"
+)
+
+// savedSearchJob is the scheduler job name for a saved search.
+func savedSearchJob(id string) string {
+	return "saved_search:" + id
+}
+
+// scheduleSavedSearch (re)registers the job of a saved search; one without a schedule can
+// still be run with saved_search_run.
+func (s *Server) scheduleSavedSearch(ss savedsearch.Search) {
+	name := savedSearchJob(ss.ID)
+	s.scheduler.Remove(name)
+	if ss.Schedule == "" {
+		return
+	}
+	id := ss.ID
+	err := s.scheduler.Add(scheduler.Job{Name: name, Spec: ss.Schedule, Jitter: s.cfg.SchedulerJitter, Run: func(ctx context.Context) error {
+		_, err := s.runSavedSearch(ctx, id)
+		return err
+	}})
+	if err != nil {
+		log.Printf("WARN: saved search %s not scheduled: %v", id, err)
+	}
+}
+
+// savedSearchRun is the saved_search_run response.
+type savedSearchRun struct {
+	SavedSearch savedsearch.Search `json:"savedSearch"`
+	Results     []sam.Opportunity  `json:"results"`
+	// New lists the notice ids this run returned for the first time.
+	New    []string `json:"new"`
+	SamEnv string   `json:"samEnv"`
+}
+
+// runSavedSearch runs a saved search against SAM.gov, refreshing its cached results,
+// recording which notices it has now seen, snapshotting them for sam_whats_new and
+// queueing notifications of new and amended notices.
+func (s *Server) runSavedSearch(ctx context.Context, id string) (*savedSearchRun, error) {
+	ss, ok := s.savedSearches.Get(id)
+	if !ok {
+		return nil, savedsearch.ErrNotFound
+	}
+	res, err := s.fetchAndCacheSamData(ctx, searchCacheKey(ss.Params), ss.Params, searchTTL)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(res.Results))
+	for _, o := range res.Results {
+		ids = append(ids, o.NoticeID)
+	}
+	now := time.Now()
+	fresh, err := s.savedSearches.RecordRun(id, ids, now)
+	if err != nil {
+		return nil, err
+	}
+	s.recordRun(savedSearchJob(id), notify.Source{Kind: notify.SourceSavedSearch, ID: id, Name: ss.Name}, ss.Notify, res.Results)
+	if len(fresh) > 0 {
+		s.events.resourceUpdated(searchURIPrefix + id)
+	}
+	ss, _ = s.savedSearches.Get(id)
+	return &savedSearchRun{SavedSearch: ss, Results: res.Results, New: fresh, SamEnv: s.samEnv}, nil
+}
+
+// savedSearchParams are the search parameters of a saved search: the sam_search query
+// arguments, plus a Federal Hierarchy organization code or a single notice id.
+type savedSearchParams struct {
+	searchQuery
+	OrgCode  string `json:"organizationCode" description:"Federal Hierarchy organization id; takes precedence over organization"`
+	NoticeID string `json:"noticeId" description:"Restricts the search to one notice"`
+}
+
+// params returns the SAM.gov search parameters for p.
+func (p savedSearchParams) params() sam.SearchParams {
+	sp := p.searchQuery.params()
+	sp.OrgCode, sp.NoticeID = p.OrgCode, p.NoticeID
+	return sp
+}
+
+// savedSearchArgs are the saved_search_create arguments.
+type savedSearchArgs struct {
+	Name     string               `json:"name" jsonschema:"required,minLength=1"`
+	Owner    string               `json:"owner" description:"Who the search belongs to; saved_search_list can filter by owner"`
+	Params   savedSearchParams    `json:"params" jsonschema:"required" description:"sam_search arguments (q, naics, days, limit, noticeType, organization); days is required"`
+	Schedule string               `json:"schedule" description:"Cron schedule for automatic runs, e.g. \"0 7 * * 1-5\"; empty runs only on demand"`
+	Notify   []savedsearch.Target `json:"notify" description:"Where new notices are sent"`
+}
+
+// savedSearchUpdateArgs are the saved_search_update arguments; omitted fields are unchanged.
+type savedSearchUpdateArgs struct {
+	ID       string                `json:"id" jsonschema:"required"`
+	Name     *string               `json:"name"`
+	Owner    *string               `json:"owner"`
+	Params   *savedSearchParams    `json:"params" description:"Replaces the search parameters"`
+	Schedule *string               `json:"schedule" description:"Cron schedule; an empty string stops automatic runs"`
+	Notify   *[]savedsearch.Target `json:"notify" description:"Replaces the notification targets"`
+}
+
+// savedSearchListArgs are the saved_search_list arguments.
+type savedSearchListArgs struct {
+	Owner string `json:"owner" description:"Only list searches with this owner"`
+}
+
+// savedSearchIDArgs identify a saved search.
+type savedSearchIDArgs struct {
+	ID string `json:"id" jsonschema:"required"`
+}
+
+// savedSearchRunArgs are the saved_search_run arguments.
+type savedSearchRunArgs struct {
+	ID      string `json:"id" jsonschema:"required"`
+	OnlyNew bool   `json:"onlyNew" description:"Return only notices this search has not returned before"`
+}
+
+// savedSearchList is the saved_search_list response.
+type savedSearchList struct {
+	SavedSearches []savedsearch.Search `json:"savedSearches"`
+}
+
+// savedSearchDeleted is the saved_search_delete response.
+type savedSearchDeleted struct {
+	Deleted string `json:"deleted"`
+}
+
+// toolSavedSearchCreate serves the saved_search_create tool.
+func (s *Server) toolSavedSearchCreate(_ context.Context, a savedSearchArgs) (*savedsearch.Search, error) {
+	params := a.Params.params()
+	if err := checkSearchNAICS(params); err != nil {
+		return nil, err
+	}
+	ss, err := s.savedSearches.Create(savedsearch.Search{Name: a.Name, Owner: a.Owner, Params: params, Schedule: a.Schedule, Notify: a.Notify})
+	if err != nil {
+		return nil, savedSearchError("", err)
+	}
+	s.scheduleSavedSearch(ss)
+	s.events.resourceListChanged()
+	return &ss, nil
+}
+
+// toolSavedSearchList serves the saved_search_list tool.
+func (s *Server) toolSavedSearchList(_ context.Context, a savedSearchListArgs) (*savedSearchList, error) {
+	return &savedSearchList{SavedSearches: s.savedSearches.List(a.Owner)}, nil
+}
+
+// toolSavedSearchUpdate serves the saved_search_update tool.
+func (s *Server) toolSavedSearchUpdate(_ context.Context, a savedSearchUpdateArgs) (*savedsearch.Search, error) {
+	var params *sam.SearchParams
+	if a.Params != nil {
+		p := a.Params.params()
+		if err := checkSearchNAICS(p); err != nil {
+			return nil, err
+		}
+		params = &p
+	}
+	renamed := false
+	ss, err := s.savedSearches.Update(a.ID, func(ss *savedsearch.Search) {
+		if a.Name != nil {
+			renamed = *a.Name != ss.Name
+			ss.Name = *a.Name
+		}
+		if a.Owner != nil {
+			ss.Owner = *a.Owner
+		}
+		if params != nil {
+			ss.Params = *params
+		}
+		if a.Schedule != nil {
+			ss.Schedule = *a.Schedule
+		}
+		if a.Notify != nil {
+			ss.Notify = *a.Notify
+		}
+	})
+	if err != nil {
+		return nil, savedSearchError(a.ID, err)
+	}
+	s.scheduleSavedSearch(ss)
+	if renamed {
+		// resources/list shows saved searches by name.
+		s.events.resourceListChanged()
+	}
+	return &ss, nil
+}
+
+// toolSavedSearchDelete serves the saved_search_delete tool.
+func (s *Server) toolSavedSearchDelete(_ context.Context, a savedSearchIDArgs) (*savedSearchDeleted, error) {
+	if err := s.savedSearches.Delete(a.ID); err != nil {
+		return nil, savedSearchError(a.ID, err)
+	}
+	s.scheduler.Remove(savedSearchJob(a.ID))
+	if err := s.snapshots.Forget(savedSearchJob(a.ID)); err != nil {
+		log.Printf("WARN: snapshot of saved search %s not removed: %v", a.ID, err)
+	}
+	s.events.resourceListChanged()
+	return &savedSearchDeleted{Deleted: a.ID}, nil
+}
+
+// toolSavedSearchRun serves the saved_search_run tool.
+func (s *Server) toolSavedSearchRun(ctx context.Context, a savedSearchRunArgs) (*savedSearchRun, error) {
+	run, err := s.runSavedSearch(ctx, a.ID)
+	if errors.Is(err, savedsearch.ErrNotFound) {
+		return nil, savedSearchError(a.ID, err)
+	}
+	if err != nil {
+		return nil, mcp.Upstream("sam api error: %v", err)
+	}
+	if a.OnlyNew {
+		run.Results = onlyNotices(run.Results, run.New)
+	}
+	return run, nil
+}
+
+// checkSearchNAICS rejects saved search parameters with NAICS codes sam_search would reject.
+func checkSearchNAICS(p sam.SearchParams) error {
+	if issues, _ := validateNAICS(p.NAICS); len(issues) > 0 {
+		return mcp.InvalidParams("invalid naics", map[string]interface{}{"invalid": issues})
+	}
+	return nil
+}
+
+// savedSearchError maps store errors to tool errors; storage failures stay internal errors.
+func savedSearchError(id string, err error) error {
+	switch {
+	case errors.Is(err, savedsearch.ErrNotFound):
+		return mcp.InvalidParams("unknown saved search: "+id, nil)
+	case errors.Is(err, savedsearch.ErrInvalid):
+		return mcp.InvalidParams(err.Error(), nil)
+	}
+	return err
+}
+
+// onlyNotices filters opportunities to the given notice ids, keeping their order.
+func onlyNotices(opps []sam.Opportunity, ids []string) []sam.Opportunity {
+	keep := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		keep[id] = true
+	}
+	out := make([]sam.Opportunity, 0, len(ids))
+	for _, o := range opps {
+		if keep[o.NoticeID] {
+			out = append(out, o)
+		}
+	}
+	return out
+}
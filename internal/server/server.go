@@ -3,15 +3,28 @@ package server
 
 import (
 	"context"
-	"bytes"
+	"crypto/subtle"
 	"encoding/json"
-	"io"
+	"errors"
+	"log"
 	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"sam-mcp/internal/mcp"
+	"sam-mcp/internal/notify"
+	"sam-mcp/internal/prompts"
 	"sam-mcp/internal/sam"
+	"sam-mcp/internal/savedsearch"
+	"sam-mcp/internal/scheduler"
+	"sam-mcp/internal/snapshot"
+	"sam-mcp/internal/versions"
 )
 
 // Config contains server configuration values such as port, auth token, and API keys.
@@ -26,51 +39,160 @@ type Config struct {
 	PrefetchLimit int
 	PrefetchType  string
 	PrefetchOrg   string
+	SamEnv        string
+	SamBaseURL    string
+	SamAPIVersion string
+	// PromptsDir holds additional or overriding prompt templates (see internal/prompts).
+	PromptsDir string
+	// PrefetchSchedule and HierarchySchedule are cron specs for the background jobs (see
+	// internal/scheduler); empty disables the schedule but keeps the manual trigger.
+	PrefetchSchedule  string
+	HierarchySchedule string
+	SchedulerJitter   time.Duration
+	// PrefetchProfiles are additional named searches warmed on their own schedules (see LoadProfiles).
+	PrefetchProfiles []PrefetchProfile
+	// DataDir holds persisted state (saved searches, search snapshots, opportunity versions,
+	// notification deliveries); empty keeps it in memory.
+	DataDir string
+	// WebhookSecret signs webhook notifications (see internal/notify); empty sends them unsigned.
+	WebhookSecret string
+	// NotifyAllowedHosts are webhook, Slack and Teams hosts that may resolve to private or
+	// loopback addresses; deliveries to any other non-public address are refused.
+	NotifyAllowedHosts []string
+	// FeedToken authorizes the Atom and RSS feeds under /mcp/feeds/ only, in the
+	// Authorization header or a token query parameter for readers that cannot send headers.
+	FeedToken string
+	// SMTP is the mail server for email digests; email targets are not delivered while its
+	// Host is empty.
+	SMTP notify.SMTPConfig
+	// DigestTo receives the email digest of every profile and saved search, in addition to
+	// their own email targets.
+	DigestTo []string
+	// DigestSchedule is the cron spec of the email digest job; DigestTemplatesDir holds
+	// templates overriding the built-in digest templates (see notify.LoadTemplates).
+	DigestSchedule     string
+	DigestTemplatesDir string
 }
 
 // Server contains the configured router, cache, HTTP client, and config for the MCP server.
 type Server struct {
-	cfg         Config
-	router      *chi.Mux
-	cache       *Cache
-	httpClient  *http.Client
-	toolHandlers map[string]http.HandlerFunc
+	cfg           Config
+	router        *chi.Mux
+	cache         *Cache
+	httpClient    *http.Client
+	sam           SamClient
+	samEnv        string
+	samBaseURL    string
+	tools         *mcp.Registry
+	extraTools    []mcp.Handler
+	events        *eventHub
+	prompts       *prompts.Set
+	scheduler     *scheduler.Scheduler
+	savedSearches *savedsearch.Store
+	snapshots     *snapshot.Store
+	versions      *versions.Store
+	describe      chan struct{}
+	deliveries    *notify.Queue
+	digests       *notify.Digest
+	orgLoad       orgIndexLoad
+}
+
+// Option customizes a Server during construction.
+type Option func(*Server)
+
+// WithSamClient overrides the SAM.gov client used by tool handlers.
+func WithSamClient(c SamClient) Option {
+	return func(s *Server) { s.sam = c }
+}
+
+// WithTools registers additional tools alongside the built-in ones. Names must not
+// collide with a built-in tool.
+func WithTools(tools ...mcp.Handler) Option {
+	return func(s *Server) { s.extraTools = append(s.extraTools, tools...) }
 }
 
 // New constructs a Server with middleware and routes configured.
-func New(cfg Config) *Server {
+// Without WithSamClient, a live client is used when SamAPIKey is set and mock data otherwise.
+func New(cfg Config, opts ...Option) *Server {
 	s := &Server{
 		cfg:        cfg,
 		router:     chi.NewRouter(),
 		cache:      NewCache(),
 		httpClient: &http.Client{Timeout: 10 * time.Second},
+		events:     newEventHub(),
+		describe:   make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.samEnv, s.samBaseURL = resolveSamEnv(cfg)
+	if s.sam == nil {
+		if cfg.SamAPIKey != "" {
+			s.sam = sam.New(s.samBaseURL, cfg.SamAPIVersion, cfg.SamAPIKey, s.httpClient)
+		} else {
+			s.sam = mockSamClient{}
+			s.samEnv = samEnvMock
+		}
+	}
+	s.prompts = loadPrompts(cfg.PromptsDir)
+	s.savedSearches = openSavedSearches(cfg.DataDir)
+	s.snapshots = openSnapshots(cfg.DataDir)
+	s.versions = openVersions(cfg.DataDir)
+	s.deliveries = openDeliveries(cfg.DataDir)
+	notifyClient := notify.NewHTTPClient(10*time.Second, cfg.NotifyAllowedHosts)
+	s.deliveries.Register(savedsearch.TargetWebhook, &notify.Webhook{Secret: cfg.WebhookSecret, Client: notifyClient})
+	s.deliveries.Register(savedsearch.TargetSlack, &notify.Slack{Client: notifyClient})
+	s.deliveries.Register(savedsearch.TargetTeams, &notify.Teams{Client: notifyClient})
+	if cfg.SMTP.Host != "" {
+		s.deliveries.Register(savedsearch.TargetEmail, &notify.Email{SMTP: cfg.SMTP, Templates: loadDigestTemplates(cfg.DigestTemplatesDir)})
+	}
+	s.digests = openDigests(cfg.DataDir)
+	s.scheduler = s.newScheduler()
+	for _, ss := range s.savedSearches.List("") {
+		s.scheduleSavedSearch(ss)
 	}
 	s.router.Use(middleware.RequestID)
 	s.router.Use(middleware.RealIP)
-	s.router.Use(middleware.Logger)
+	s.router.Use(accessLog)
 	s.router.Use(middleware.Recoverer)
-	s.router.Use(middleware.Timeout(60 * time.Second))
+	timeout := middleware.Timeout(60 * time.Second)
 
-	s.router.Get("/health", s.handleHealth)
+	s.router.With(timeout).Get("/health", s.handleHealth)
 
 	s.router.Route("/mcp", func(r chi.Router) {
 		r.Use(s.auth)
-		r.Get("/tools", s.handleListTools)
-		r.Post("/call", s.handleCall)
-		r.Post("/scheduled", s.handleScheduled)
+		r.Group(func(r chi.Router) {
+			r.Use(timeout)
+			r.Get("/tools", s.handleListTools)
+			r.Post("/call", s.handleCall)
+			r.Post("/scheduled", s.handleScheduled)
+			r.Get("/scheduled", s.handleScheduleStatus)
+			r.Get("/resources", s.handleListResources)
+			r.Get("/resources/templates", s.handleListResourceTemplates)
+			r.Post("/resources/read", s.handleReadResource)
+			r.Post("/resources/subscribe", s.handleSubscribeResource)
+			r.Post("/resources/unsubscribe", s.handleUnsubscribeResource)
+			r.Get("/prompts", s.handleListPrompts)
+			r.Post("/prompts/get", s.handleGetPrompt)
+			r.Post("/completion/complete", s.handleComplete)
+			r.Get("/deliveries", s.handleListDeliveries)
+			r.Get("/deliveries/{id}", s.handleGetDelivery)
+			r.Post("/deliveries/{id}/retry", s.handleRetryDelivery)
+			r.Get("/feeds/{id}.atom", s.handleFeed("atom"))
+			r.Get("/feeds/{id}.rss", s.handleFeed("rss"))
+			r.Get("/feeds/{id}.ics", s.handleCalendar)
+		})
+		// The event stream is long-lived, so it is exempt from the request timeout.
+		r.Get("/events", s.handleEvents)
 	})
 
-	s.registerToolHandlers()
+	s.tools = mcp.NewRegistry()
+	s.tools.MustRegister(s.builtinTools()...)
+	s.tools.MustRegister(s.extraTools...)
 
 	return s
 }
 
-func (s *Server) registerToolHandlers() {
-	s.toolHandlers = map[string]http.HandlerFunc{
-		"sam_search": s.handleSamSearch,
-	}
-}
-
 // Router exposes the root HTTP handler for the server.
 func (s *Server) Router() http.Handler { return s.router }
 
@@ -82,12 +204,18 @@ func (s *Server) auth(next http.Handler) http.Handler {
 		}
 		authz := r.Header.Get("Authorization")
 		// Allow main MCP token for all endpoints
-		if authz == "Bearer "+s.cfg.Token {
+		if tokenMatches(authz, "Bearer ", s.cfg.Token) {
 			next.ServeHTTP(w, r)
 			return
 		}
 		// Allow schedule token only for the scheduled endpoint
-		if r.URL != nil && r.URL.Path == "/mcp/scheduled" && s.cfg.ScheduleToken != "" && authz == "Bearer "+s.cfg.ScheduleToken {
+		if r.URL != nil && r.URL.Path == "/mcp/scheduled" && tokenMatches(authz, "Bearer ", s.cfg.ScheduleToken) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		// Allow feed token, in the header or the URL, only for feeds
+		if r.URL != nil && strings.HasPrefix(r.URL.Path, feedPathPrefix) &&
+			(tokenMatches(authz, "Bearer ", s.cfg.FeedToken) || tokenMatches(r.URL.Query().Get("token"), "", s.cfg.FeedToken)) {
 			next.ServeHTTP(w, r)
 			return
 		}
@@ -97,35 +225,37 @@ func (s *Server) auth(next http.Handler) http.Handler {
 	})
 }
 
+// tokenMatches reports whether got is prefix followed by a non-empty token, compared in
+// constant time.
+func tokenMatches(got, prefix, token string) bool {
+	return token != "" && subtle.ConstantTimeCompare([]byte(got), []byte(prefix+token)) == 1
+}
+
+// accessLog logs each request like middleware.Logger, with the token query parameter feed
+// readers may send redacted.
+var accessLog = middleware.RequestLogger(redactingLogFormatter{
+	&middleware.DefaultLogFormatter{Logger: log.New(os.Stdout, "", log.LstdFlags), NoColor: true},
+})
+
+type redactingLogFormatter struct{ middleware.LogFormatter }
+
+func (f redactingLogFormatter) NewLogEntry(r *http.Request) middleware.LogEntry {
+	if q := r.URL.Query(); q.Has("token") {
+		q.Set("token", "REDACTED")
+		r = r.WithContext(r.Context())
+		r.RequestURI = r.URL.EscapedPath() + "?" + q.Encode()
+	}
+	return f.LogFormatter.NewLogEntry(r)
+}
+
 func (s *Server) handleHealth(w http.ResponseWriter, _ *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok", "samEnv": s.samEnv, "samBaseURL": s.samBaseURL})
 }
 
-// Tool describes an MCP tool and its input schema.
-// Note: Tool and CallRequest types are defined in types.go
-
 func (s *Server) handleListTools(w http.ResponseWriter, _ *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	tools := []Tool{
-		{
-			Name:        "sam_search",
-			Description: "Search SAM.gov opportunities",
-			InputSchema: map[string]interface{}{
-				"type": "object",
-				"properties": map[string]interface{}{
-					"q":            map[string]interface{}{"type": "string"},
-					"naics":        map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
-					"days":         map[string]interface{}{"type": "integer", "minimum": 0},
-					"limit":        map[string]interface{}{"type": "integer", "minimum": 1, "maximum": 100},
-					"noticeType":   map[string]interface{}{"type": "string"},
-					"organization": map[string]interface{}{"type": "string"},
-				},
-				"required": []string{"days"},
-			},
-		},
-	}
-	_ = json.NewEncoder(w).Encode(map[string]interface{}{"tools": tools})
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"tools": s.tools.List()})
 }
 
 func (s *Server) handleCall(w http.ResponseWriter, r *http.Request) {
@@ -135,89 +265,149 @@ func (s *Server) handleCall(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if handler, ok := s.toolHandlers[req.Name]; ok {
-		// We need to marshal the args back into the request body
-		// so the handler can decode it.
-		jsonArgs, err := json.Marshal(req.Args)
-		if err != nil {
-			http.Error(w, "invalid arguments", http.StatusBadRequest)
-			return
-		}
-		r.Body = http.NoBody
-		newReq := r.WithContext(r.Context())
-		newReq.Body = io.NopCloser(bytes.NewReader(jsonArgs))
-		handler.ServeHTTP(w, newReq)
+	// Tool failures, including invalid arguments, come back as results with isError set;
+	// only an unknown tool is a protocol error.
+	res, err := s.tools.Call(r.Context(), req.Name, req.Args)
+	if err != nil {
+		writeMCPError(w, err)
 		return
 	}
-
-	http.Error(w, "unknown tool", http.StatusNotFound)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(res)
 }
 
-// fetchAndCacheSamData handles the logic of fetching data from SAM.gov or using mock data,
-// and then caching the result. It's used by both handleSamSearch and handleScheduled.
-func (s *Server) fetchAndCacheSamData(ctx context.Context, cacheKey string, params sam.SearchParams) (map[string]interface{}, error) {
-	// If a valid SAM API key is configured, fetch live data; otherwise use mock data.
-	if s.cfg.SamAPIKey != "" {
-		client := sam.New("https://api.sam.gov/opportunities/v2/search", s.cfg.SamAPIKey, s.httpClient)
-		res, err := client.Search(ctx, params)
-		if err != nil {
-			return nil, err
-		}
-		resp := map[string]interface{}{"results": res}
-		s.cache.Set(cacheKey, resp, 12*time.Hour)
-		return resp, nil
+// writeMCPError writes err as an MCP error object with an HTTP status matching its code.
+// Errors that are not *mcp.Error are reported as internal errors.
+func writeMCPError(w http.ResponseWriter, err error) {
+	var me *mcp.Error
+	if !errors.As(err, &me) {
+		me = &mcp.Error{Code: mcp.CodeInternal, Message: err.Error()}
+	}
+	status := http.StatusInternalServerError
+	switch me.Code {
+	case mcp.CodeInvalidParams:
+		status = http.StatusBadRequest
+	case mcp.CodeMethodNotFound, mcp.CodeResourceNotFound:
+		status = http.StatusNotFound
+	case mcp.CodeUpstream:
+		status = http.StatusBadGateway
 	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"error": me})
+}
+
+// searchTTL is how long search results are cached unless a prefetch profile says otherwise.
+const searchTTL = 12 * time.Hour
+
+// searchResult is the sam_search response.
+type searchResult struct {
+	Results              []sam.Opportunity `json:"results"`
+	SamEnv               string            `json:"samEnv"`
+	ResolvedOrganization *sam.OrgMatch     `json:"resolvedOrganization,omitempty"`
+	Warnings             []string          `json:"warnings,omitempty"`
+}
 
-	// Fallback mock when SAM_API_KEY is not configured
-	resp := map[string]interface{}{
-		"results": []map[string]string{
-			{"title": "Example Opportunity", "agency": "GSA", "modified": time.Now().UTC().Format(time.RFC3339), "url": "https://sam.gov/opp/example"},
-		},
+// fetchAndCacheSamData runs the search through the configured SAM client (live or mock)
+// and caches the result. It's used by both sam_search and handleScheduled.
+func (s *Server) fetchAndCacheSamData(ctx context.Context, cacheKey string, params sam.SearchParams, ttl time.Duration) (*searchResult, error) {
+	resolved := s.resolveSearchOrg(ctx, &params)
+	res, err := s.sam.Search(ctx, params)
+	if err != nil {
+		return nil, err
 	}
-	s.cache.Set(cacheKey, resp, 12*time.Hour)
+	enrichCodes(res)
+	s.rememberOpportunities(res)
+	s.recordVersions(res)
+	resp := &searchResult{Results: res, SamEnv: s.samEnv, ResolvedOrganization: resolved}
+	s.cache.Set(cacheKey, resp, ttl)
 	return resp, nil
 }
 
-func (s *Server) handleSamSearch(w http.ResponseWriter, r *http.Request) {
-	type args struct {
-		Q          string   `json:"q"`
-		NAICS      []string `json:"naics"`
-		Days       int      `json:"days"`
-		Limit      int      `json:"limit"`
-		NoticeType string   `json:"noticeType"`
-		Org        string   `json:"organization"`
+// cached returns the cached value for key, or calls fetch and caches its result for ttl.
+func cached[T any](s *Server, key string, ttl time.Duration, fetch func() (T, error)) (T, error) {
+	if v, ok := s.cache.Get(key); ok {
+		if t, ok := v.(T); ok {
+			return t, nil
+		}
 	}
-	var searchArgs args
-	if err := json.NewDecoder(r.Body).Decode(&searchArgs); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
-		return
+	v, err := fetch()
+	if err != nil {
+		return v, err
 	}
+	s.cache.Set(key, v, ttl)
+	return v, nil
+}
 
-	cacheKey := "sam_search:" + searchArgs.Q + ":" + time.Now().UTC().Format("2006-01-02")
-	if v, ok := s.cache.Get(cacheKey); ok {
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(v)
-		return
+// searchQuery holds the sam_search arguments that shape the SAM.gov query; saved searches
+// take the same ones with the same constraints.
+type searchQuery struct {
+	Q          string   `json:"q"`
+	NAICS      []string `json:"naics" description:"Six-digit NAICS codes; see naics_lookup"`
+	Days       int      `json:"days" jsonschema:"required,min=0,max=365"`
+	Limit      int      `json:"limit" jsonschema:"min=1,max=100,default=25"`
+	NoticeType string   `json:"noticeType" jsonschema:"enum=o|p|k|r|s|g|a|u|i" description:"Notice type code: o, p, k, r, s, g, a, u or i (see completion/complete)"`
+	Org        string   `json:"organization" description:"Organization name (resolved via the Federal Hierarchy) or code"`
+}
+
+// params returns the SAM.gov search parameters for q.
+func (q searchQuery) params() sam.SearchParams {
+	return sam.SearchParams{Q: q.Q, NAICS: q.NAICS, Days: q.Days, Limit: q.Limit, NoticeType: q.NoticeType, Org: q.Org}
+}
+
+// searchArgs are the sam_search arguments.
+type searchArgs struct {
+	searchQuery
+	CheckExclusions bool `json:"checkExclusions" description:"Flag excluded awardees on award notices"`
+}
+
+// toolSamSearch serves the sam_search tool.
+func (s *Server) toolSamSearch(ctx context.Context, a searchArgs) (*searchResult, error) {
+	issues, warnings := validateNAICS(a.NAICS)
+	if len(issues) > 0 {
+		return nil, mcp.InvalidParams("invalid naics", map[string]interface{}{"invalid": issues})
 	}
 
-	params := sam.SearchParams{Q: searchArgs.Q, NAICS: searchArgs.NAICS, Days: searchArgs.Days, Limit: searchArgs.Limit, NoticeType: searchArgs.NoticeType, Org: searchArgs.Org}
-	resp, err := s.fetchAndCacheSamData(r.Context(), cacheKey, params)
+	params := a.params()
+	cacheKey := searchCacheKey(params)
+	res, err := cached(s, cacheKey, searchTTL, func() (*searchResult, error) {
+		return s.fetchAndCacheSamData(ctx, cacheKey, params, searchTTL)
+	})
 	if err != nil {
-		http.Error(w, "sam api error: "+err.Error(), http.StatusBadGateway)
-		return
+		return nil, mcp.Upstream("sam api error: %v", err)
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(resp)
+	out := *res
+	if a.CheckExclusions {
+		out.Results, err = s.annotateAwardees(ctx, res.Results)
+		if err != nil {
+			return nil, mcp.Upstream("sam api error during exclusion check: %v", err)
+		}
+	}
+	out.Warnings = warnings
+	return &out, nil
 }
 
-// handleScheduled is intended to be called by a scheduler (e.g., GitHub Actions) to warm caches or trigger background work
-func (s *Server) handleScheduled(w http.ResponseWriter, r *http.Request) {
-	// Warm the cache for the default prefetch query using the same cache key scheme as handleSamSearch
-	todayKey := time.Now().UTC().Format("2006-01-02")
-	cacheKey := "sam_search:" + s.cfg.PrefetchQ + ":" + todayKey
+// searchCacheKey is the cache key for a search's results on the current day. Every
+// parameter is part of the key; NAICS order does not matter.
+func searchCacheKey(p sam.SearchParams) string {
+	naics := append([]string(nil), p.NAICS...)
+	sort.Strings(naics)
+	v := url.Values{}
+	v.Set("q", p.Q)
+	v.Set("naics", strings.Join(naics, ","))
+	v.Set("days", strconv.Itoa(p.Days))
+	v.Set("limit", strconv.Itoa(p.Limit))
+	v.Set("type", p.NoticeType)
+	v.Set("org", p.Org)
+	v.Set("orgCode", p.OrgCode)
+	v.Set("noticeId", p.NoticeID)
+	return "sam_search:" + time.Now().UTC().Format("2006-01-02") + ":" + v.Encode()
+}
 
-	params := sam.SearchParams{
+// prefetchParams is the search configured through the PREFETCH_* settings.
+func (s *Server) prefetchParams() sam.SearchParams {
+	return sam.SearchParams{
 		Q:          s.cfg.PrefetchQ,
 		NAICS:      s.cfg.PrefetchNAICS,
 		Days:       s.cfg.PrefetchDays,
@@ -225,16 +415,21 @@ func (s *Server) handleScheduled(w http.ResponseWriter, r *http.Request) {
 		NoticeType: s.cfg.PrefetchType,
 		Org:        s.cfg.PrefetchOrg,
 	}
-	_, err := s.fetchAndCacheSamData(r.Context(), cacheKey, params)
-	if err != nil {
-		http.Error(w, "sam api error during prefetch: "+err.Error(), http.StatusBadGateway)
-		return
-	}
+}
 
-	statusMsg := "prefetch completed"
-	if s.cfg.SamAPIKey == "" {
-		statusMsg = "prefetch completed (mock)"
+// resultsChanged reports whether two result sets differ in notices or modification times.
+func resultsChanged(prev, next []sam.Opportunity) bool {
+	if len(prev) != len(next) {
+		return true
 	}
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]string{"status": statusMsg})
+	seen := make(map[string]time.Time, len(prev))
+	for _, o := range prev {
+		seen[o.NoticeID] = o.Modified
+	}
+	for _, o := range next {
+		if m, ok := seen[o.NoticeID]; !ok || !m.Equal(o.Modified) {
+			return true
+		}
+	}
+	return false
 }
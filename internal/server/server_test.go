@@ -1,13 +1,47 @@
 package server
 
 import (
+    "bufio"
     "bytes"
+    "context"
     "encoding/json"
+    "encoding/xml"
+    "fmt"
+    "io"
+    "log"
     "net/http"
     "net/http/httptest"
+    "os"
+    "path/filepath"
+    "reflect"
+    "strings"
+    "sync"
+    "sync/atomic"
     "testing"
+    "time"
+
+    "github.com/go-chi/chi/v5/middleware"
+    "sam-mcp/internal/mcp"
+    "sam-mcp/internal/notify"
+    "sam-mcp/internal/sam"
 )
 
+type fakeSam struct {
+    mockSamClient
+    params      []sam.SearchParams
+    entityCalls int
+}
+
+func (f *fakeSam) LookupEntity(ctx context.Context, p sam.EntityParams) (*sam.EntityPage, error) {
+    f.entityCalls++
+    return f.mockSamClient.LookupEntity(ctx, p)
+}
+
+func (f *fakeSam) Search(_ context.Context, p sam.SearchParams) ([]sam.Opportunity, error) {
+    f.params = append(f.params, p)
+    return []sam.Opportunity{{Title: "Fake", Agency: "DOD"}}, nil
+}
+
 func TestHealth(t *testing.T) {
     s := New(Config{})
     req := httptest.NewRequest(http.MethodGet, "/health", nil)
@@ -60,21 +94,1353 @@ func TestScheduled(t *testing.T) {
     }
 }
 
+func TestScheduledJobsAndStatus(t *testing.T) {
+    s := New(Config{Token: "x", ScheduleToken: "sched", PrefetchSchedule: "0 6,18 * * *"})
+    do := func(method, target string) *httptest.ResponseRecorder {
+        req := httptest.NewRequest(method, target, nil)
+        req.Header.Set("Authorization", "Bearer sched")
+        rr := httptest.NewRecorder()
+        s.Router().ServeHTTP(rr, req)
+        return rr
+    }
+    if rr := do(http.MethodPost, "/mcp/scheduled?job=hierarchy_refresh"); rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+    }
+    if rr := do(http.MethodPost, "/mcp/scheduled?job=nope"); rr.Code != http.StatusNotFound {
+        t.Fatalf("expected 404 for unknown job, got %d", rr.Code)
+    }
+    rr := do(http.MethodGet, "/mcp/scheduled")
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d", rr.Code)
+    }
+    var out struct {
+        Jobs []struct {
+            Name     string
+            Schedule string
+            History  []struct{ Trigger string }
+        }
+    }
+    if err := json.Unmarshal(rr.Body.Bytes(), &out); err != nil {
+        t.Fatal(err)
+    }
+    jobs := map[string]int{}
+    for i, j := range out.Jobs {
+        jobs[j.Name] = i
+    }
+    pf, hr := out.Jobs[jobs["prefetch:prefetch"]], out.Jobs[jobs["hierarchy_refresh"]]
+    if len(out.Jobs) != 2 || pf.Schedule != "0 6,18 * * *" || len(hr.History) != 1 || hr.History[0].Trigger != "manual" {
+        t.Fatalf("unexpected status: %s", rr.Body.String())
+    }
+}
+
+func TestPrefetchProfiles(t *testing.T) {
+    dir := t.TempDir()
+    write := func(name, body string) string {
+        path := filepath.Join(dir, name)
+        if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+            t.Fatal(err)
+        }
+        return path
+    }
+    profiles, err := LoadProfiles(write("profiles.json", `{"profiles":[
+        {"name":"cyber","description":"Cyber portfolio","search":{"q":"cyber","naics":["541512"],"days":7,"limit":10},"ttl":"2h"},
+        {"name":"facilities","search":{"naics":["561210"],"days":14,"noticeType":"o"},"schedule":"off"}
+    ]}`), "0 6 * * *")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(profiles) != 2 || profiles[0].TTL != 2*time.Hour || profiles[0].Schedule != "0 6 * * *" || profiles[1].Schedule != "" || profiles[1].TTL != searchTTL {
+        t.Fatalf("unexpected profiles: %+v", profiles)
+    }
+    fromYAML, err := LoadProfiles(write("profiles.yaml", `profiles:
+  - name: cyber
+    description: >-
+      Cyber
+      portfolio
+    search: &cyber {q: cyber, naics: ["541512"], days: 7, limit: 10}
+    ttl: 2h
+  - name: facilities
+    search:
+      <<: *cyber
+      q: ""
+      naics: ["561210"]
+      days: 14
+      limit: 0
+      noticeType: o
+    schedule: "OFF"
+`), "0 6 * * *")
+    if err != nil || !reflect.DeepEqual(fromYAML, profiles) {
+        t.Fatalf("YAML profiles differ from JSON: %+v %v", fromYAML, err)
+    }
+    if _, err := LoadProfiles(write("bad.yml", "profiles:\n  - name: a\n    search: {days: seven}\n"), ""); err == nil {
+        t.Error("bad.yml: expected error")
+    }
+    for _, bad := range []string{
+        `{"profiles":[{"name":"Bad Name","search":{"days":7}}]}`,
+        `{"profiles":[{"name":"a","search":{"days":7}},{"name":"a","search":{"days":7}}]}`,
+        `{"profiles":[{"name":"a","search":{"days":7},"schedule":"every day"}]}`,
+        `{"profiles":[{"name":"a","search":{"days":7},"ttl":"soon"}]}`,
+        `{"profiles":[{"name":"a","search":{}}]}`,
+        `{"profiles":[{"name":"a","search":{"days":7,"naics":["12"]}}]}`,
+        `{"profiles":[{"name":"a","search":{"days":7},"notify":[{"type":"webhook","address":"not a url"}]}]}`,
+    } {
+        if _, err := LoadProfiles(write("bad.json", bad), ""); err == nil {
+            t.Errorf("%s: expected error", bad)
+        }
+    }
+
+    fake := &fakeSam{}
+    s := New(Config{PrefetchProfiles: profiles}, WithSamClient(fake))
+    post := func(target string) (*httptest.ResponseRecorder, []profileRun) {
+        rr := httptest.NewRecorder()
+        s.Router().ServeHTTP(rr, httptest.NewRequest(http.MethodPost, target, nil))
+        var out struct{ Profiles []profileRun `json:"profiles"` }
+        _ = json.Unmarshal(rr.Body.Bytes(), &out)
+        return rr, out.Profiles
+    }
+    rr, runs := post("/mcp/scheduled")
+    if rr.Code != http.StatusOK || len(runs) != 3 || runs[0].Profile != "prefetch" || runs[1].Profile != "cyber" || runs[1].Results != 1 {
+        t.Fatalf("unexpected runs (%d): %s", rr.Code, rr.Body.String())
+    }
+    if len(fake.params) != 3 || fake.params[1].Q != "cyber" || fake.params[2].NoticeType != "o" {
+        t.Fatalf("unexpected searches: %+v", fake.params)
+    }
+    if rr, runs = post("/mcp/scheduled?profile=facilities"); rr.Code != http.StatusOK || len(runs) != 1 || runs[0].Run.Trigger != "manual" {
+        t.Fatalf("unexpected single run (%d): %s", rr.Code, rr.Body.String())
+    }
+    if rr, _ = post("/mcp/scheduled?profile=nope"); rr.Code != http.StatusNotFound {
+        t.Fatalf("expected 404 for unknown profile, got %d", rr.Code)
+    }
+
+    // Profiles are search resources; results are served from the prefetched cache.
+    calls := len(fake.params)
+    body, _ := json.Marshal(map[string]string{"uri": "sam://search/cyber"})
+    rr = httptest.NewRecorder()
+    s.Router().ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/mcp/resources/read", bytes.NewReader(body)))
+    if rr.Code != http.StatusOK || len(fake.params) != calls {
+        t.Fatalf("expected cached profile results, got %d after %d searches", rr.Code, len(fake.params)-calls)
+    }
+}
+
+func TestSearchCacheKeyCoversAllParams(t *testing.T) {
+    base := sam.SearchParams{Q: "cloud", NAICS: []string{"541512", "541511"}, Days: 7, Limit: 25}
+    variants := []sam.SearchParams{
+        {Q: "cloud", NAICS: []string{"541511"}, Days: 7, Limit: 25},
+        {Q: "cloud", NAICS: base.NAICS, Days: 30, Limit: 25},
+        {Q: "cloud", NAICS: base.NAICS, Days: 7, Limit: 10},
+        {Q: "cloud", NAICS: base.NAICS, Days: 7, Limit: 25, NoticeType: "o"},
+        {Q: "cloud", NAICS: base.NAICS, Days: 7, Limit: 25, Org: "GSA"},
+    }
+    for _, v := range variants {
+        if searchCacheKey(v) == searchCacheKey(base) {
+            t.Errorf("%+v shares a cache key with %+v", v, base)
+        }
+    }
+    reordered := base
+    reordered.NAICS = []string{"541511", "541512"}
+    if searchCacheKey(reordered) != searchCacheKey(base) {
+        t.Error("NAICS order should not change the cache key")
+    }
+}
+
 func TestSamSearchMock(t *testing.T) {
     s := New(Config{})
-    body, _ := json.Marshal(map[string]interface{}{"days": 7})
-    req := httptest.NewRequest(http.MethodPost, "/mcp/call", bytes.NewReader(body))
-    rr := httptest.NewRecorder()
-    s.handleSamSearch(rr, req)
+    rr := callTool(t, s, "sam_search", map[string]interface{}{"days": 7})
     if rr.Code != http.StatusOK {
         t.Fatalf("expected 200, got %d", rr.Code)
     }
 
     var resp map[string]interface{}
-    if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+    if err := decodeResult(rr, &resp); err != nil {
         t.Fatalf("invalid json: %v", err)
     }
     if _, ok := resp["results"]; !ok {
         t.Fatal("expected results key in response")
     }
+
+    res, _ := readResult(callTool(t, s, "sam_search", map[string]interface{}{"days": 7}))
+    if len(res.Content) != 1 || res.Content[0].Type != "text" || !strings.Contains(res.Content[0].Text, "opportunit") {
+        t.Fatalf("unexpected text content: %+v", res.Content)
+    }
+}
+
+func TestSamSearchUsesInjectedClient(t *testing.T) {
+    fake := &fakeSam{}
+    s := New(Config{SamAPIKey: "live"}, WithSamClient(fake))
+    rr := callTool(t, s, "sam_search", map[string]interface{}{"q": "cloud", "days": 3, "naics": []string{"541511"}})
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d", rr.Code)
+    }
+    if len(fake.params) != 1 || fake.params[0].Q != "cloud" || fake.params[0].Days != 3 {
+        t.Fatalf("unexpected params passed to client: %+v", fake.params)
+    }
+    var resp struct{ Results []sam.Opportunity `json:"results"` }
+    if err := decodeResult(rr, &resp); err != nil {
+        t.Fatalf("invalid json: %v", err)
+    }
+    if len(resp.Results) != 1 || resp.Results[0].Title != "Fake" {
+        t.Fatalf("unexpected results: %+v", resp.Results)
+    }
+}
+
+func TestSavedSearchTools(t *testing.T) {
+    dir := t.TempDir()
+    s := New(Config{DataDir: dir})
+    rr := callTool(t, s, "saved_search_create", map[string]interface{}{
+        "name": "Cyber", "owner": "ana", "schedule": "0 7 * * 1-5",
+        "params": map[string]interface{}{"q": "cyber", "days": 7, "naics": []string{"541512"}},
+        "notify": []map[string]string{{"type": "email", "address": "bd@example.com"}},
+    })
+    var created struct{ ID string `json:"id"` }
+    if err := decodeResult(rr, &created); err != nil || created.ID == "" {
+        t.Fatalf("create: %v %s", err, rr.Body.String())
+    }
+    for _, bad := range []map[string]interface{}{
+        {"name": "x", "params": map[string]interface{}{"days": 7}, "schedule": "daily"},
+        {"name": "x", "params": map[string]interface{}{"days": 7, "naics": []string{"12"}}},
+        {"name": "x", "params": map[string]interface{}{"days": 7}, "notify": []map[string]string{{"type": "pager", "address": "x"}}},
+        {"name": "x", "params": map[string]interface{}{"days": 7, "limit": 10000}},
+        {"name": "x", "params": map[string]interface{}{"days": 7, "noticeType": "z"}},
+        {"name": "x", "params": map[string]interface{}{"days": 7, "naic": []string{"541512"}}},
+    } {
+        if rr := callTool(t, s, "saved_search_create", bad); !isToolError(rr) {
+            t.Errorf("%v: expected tool error, got %s", bad, rr.Body.String())
+        }
+    }
+    if st := s.scheduler.Status(); st[len(st)-1].Name != "saved_search:"+created.ID {
+        t.Fatalf("saved search not scheduled: %+v", st)
+    }
+
+    type run struct {
+        Results []sam.Opportunity `json:"results"`
+        New     []string          `json:"new"`
+    }
+    var first, second run
+    if err := decodeResult(callTool(t, s, "saved_search_run", map[string]interface{}{"id": created.ID}), &first); err != nil {
+        t.Fatal(err)
+    }
+    if len(first.New) != 1 || first.New[0] != "mock-0001" {
+        t.Fatalf("first run should report the notice as new: %+v", first)
+    }
+    if err := decodeResult(callTool(t, s, "saved_search_run", map[string]interface{}{"id": created.ID, "onlyNew": true}), &second); err != nil {
+        t.Fatal(err)
+    }
+    if len(second.New) != 0 || len(second.Results) != 0 {
+        t.Fatalf("second run should report nothing new: %+v", second)
+    }
+
+    // Saved searches persist across restarts, with what they have seen.
+    s = New(Config{DataDir: dir})
+    var list struct {
+        SavedSearches []struct {
+            ID        string `json:"id"`
+            Name      string `json:"name"`
+            SeenCount int    `json:"seenCount"`
+        } `json:"savedSearches"`
+    }
+    if err := decodeResult(callTool(t, s, "saved_search_list", map[string]interface{}{"owner": "ana"}), &list); err != nil {
+        t.Fatal(err)
+    }
+    if len(list.SavedSearches) != 1 || list.SavedSearches[0].SeenCount != 1 {
+        t.Fatalf("unexpected list after restart: %+v", list)
+    }
+    if rr := callTool(t, s, "saved_search_update", map[string]interface{}{"id": created.ID, "params": map[string]interface{}{"days": 400}}); !isToolError(rr) {
+        t.Fatalf("update with days 400: expected tool error, got %s", rr.Body.String())
+    }
+    _, sess := s.events.open()
+    if rr := callTool(t, s, "saved_search_update", map[string]interface{}{"id": created.ID, "name": "Cyber (all)", "schedule": ""}); isToolError(rr) {
+        t.Fatalf("update: %s", rr.Body.String())
+    }
+    select {
+    case n := <-sess.events:
+        if n.Method != mcp.MethodResourceListChanged {
+            t.Fatalf("unexpected notification after rename: %+v", n)
+        }
+    default:
+        t.Fatal("renaming a saved search should notify resources/list subscribers")
+    }
+    for _, j := range s.scheduler.Status() {
+        if j.Name == "saved_search:"+created.ID {
+            t.Fatal("clearing the schedule should unschedule the search")
+        }
+    }
+    body, _ := json.Marshal(map[string]string{"uri": "sam://search/" + created.ID})
+    rr = httptest.NewRecorder()
+    s.Router().ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/mcp/resources/read", bytes.NewReader(body)))
+    if rr.Code != http.StatusOK {
+        t.Fatalf("read saved search resource: %d", rr.Code)
+    }
+    if rr := callTool(t, s, "saved_search_delete", map[string]interface{}{"id": created.ID}); isToolError(rr) {
+        t.Fatalf("delete: %s", rr.Body.String())
+    }
+    if rr := callTool(t, s, "saved_search_run", map[string]interface{}{"id": created.ID}); !isToolError(rr) {
+        t.Fatalf("expected error running a deleted search, got %s", rr.Body.String())
+    }
+}
+
+// sequenceSam returns its result sets in turn, repeating the last one.
+type sequenceSam struct {
+    mockSamClient
+    runs  [][]sam.Opportunity
+    calls int
+}
+
+func (q *sequenceSam) Search(_ context.Context, _ sam.SearchParams) ([]sam.Opportunity, error) {
+    i := q.calls
+    if i >= len(q.runs) {
+        i = len(q.runs) - 1
+    }
+    q.calls++
+    return q.runs[i], nil
+}
+
+func TestWhatsNew(t *testing.T) {
+    mod := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+    q := &sequenceSam{runs: [][]sam.Opportunity{
+        {{NoticeID: "A", Title: "Guard services", Modified: mod}, {NoticeID: "B", Title: "Janitorial", Modified: mod}},
+        {{NoticeID: "A", Title: "Guard services", Modified: mod.Add(48 * time.Hour), PSC: "S206"}, {NoticeID: "C", Title: "Landscaping", Modified: mod}},
+    }}
+    s := New(Config{}, WithSamClient(q))
+    var created struct{ ID string `json:"id"` }
+    if err := decodeResult(callTool(t, s, "saved_search_create", map[string]interface{}{"name": "Facilities", "params": map[string]interface{}{"days": 7}}), &created); err != nil {
+        t.Fatal(err)
+    }
+
+    type change struct {
+        NoticeID string   `json:"noticeId"`
+        Fields   []string `json:"fields"`
+    }
+    var out struct {
+        LastRun *time.Time `json:"lastRun"`
+        Added   []change   `json:"added"`
+        Amended []change   `json:"amended"`
+        Removed []change   `json:"removed"`
+    }
+    whatsNew := func(args map[string]interface{}) {
+        t.Helper()
+        out.LastRun, out.Added, out.Amended, out.Removed = nil, nil, nil, nil
+        args["savedSearchId"] = created.ID
+        if err := decodeResult(callTool(t, s, "sam_whats_new", args), &out); err != nil {
+            t.Fatal(err)
+        }
+    }
+    whatsNew(map[string]interface{}{})
+    if out.LastRun != nil || len(out.Added) != 0 {
+        t.Fatalf("search has not run yet: %+v", out)
+    }
+    whatsNew(map[string]interface{}{"refresh": true})
+    if len(out.Added) != 2 || len(out.Amended) != 0 || out.LastRun == nil {
+        t.Fatalf("first run: %+v", out)
+    }
+    first := *out.LastRun
+    whatsNew(map[string]interface{}{"refresh": true})
+    if len(out.Added) != 1 || out.Added[0].NoticeID != "C" || len(out.Removed) != 1 || out.Removed[0].NoticeID != "B" ||
+        len(out.Amended) != 1 || strings.Join(out.Amended[0].Fields, ",") != "modified,psc" {
+        t.Fatalf("second run: %+v", out)
+    }
+    whatsNew(map[string]interface{}{"since": first.Add(-time.Second).Format(time.RFC3339Nano)})
+    if len(out.Added) != 2 || len(out.Amended) != 0 || len(out.Removed) != 0 {
+        t.Fatalf("since before the first run, A is added and B dropped: %+v", out)
+    }
+    if rr := callTool(t, s, "sam_whats_new", map[string]interface{}{"savedSearchId": "nope"}); !isToolError(rr) {
+        t.Fatalf("expected tool error for unknown search, got %s", rr.Body.String())
+    }
+}
+
+func TestWebhookNotifications(t *testing.T) {
+    var bodies [][]byte
+    var headers []http.Header
+    var slack []byte
+    receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        b, _ := io.ReadAll(r.Body)
+        if r.URL.Path == "/slack" {
+            slack = b
+            return
+        }
+        bodies, headers = append(bodies, b), append(headers, r.Header.Clone())
+    }))
+    defer receiver.Close()
+
+    mod := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+    q := &sequenceSam{runs: [][]sam.Opportunity{
+        {{NoticeID: "A", Title: "Guard services", Modified: mod}},
+        {{NoticeID: "A", Title: "Guard services", Modified: mod.Add(24 * time.Hour)}, {NoticeID: "B", Title: "Janitorial", Modified: mod}},
+    }}
+    s := New(Config{WebhookSecret: "k", NotifyAllowedHosts: []string{"127.0.0.1"}}, WithSamClient(q))
+    var created struct{ ID string `json:"id"` }
+    if err := decodeResult(callTool(t, s, "saved_search_create", map[string]interface{}{"name": "Facilities", "params": map[string]interface{}{"days": 7},
+        "notify": []map[string]string{{"type": "webhook", "address": receiver.URL}, {"type": "slack", "address": receiver.URL + "/slack"}}}), &created); err != nil {
+        t.Fatal(err)
+    }
+    if rr := callTool(t, s, "saved_search_create", map[string]interface{}{"name": "Bad", "params": map[string]interface{}{"days": 7},
+        "notify": []map[string]string{{"type": "webhook", "address": "ftp://example.com"}}}); !isToolError(rr) {
+        t.Fatalf("expected tool error for a non-http webhook, got %s", rr.Body.String())
+    }
+
+    // The first run is the baseline; the second finds B and the amended A.
+    callTool(t, s, "saved_search_run", map[string]interface{}{"id": created.ID})
+    if n := s.deliveries.Process(context.Background(), time.Now()); n != 0 {
+        t.Fatalf("baseline run queued %d deliveries", n)
+    }
+    callTool(t, s, "saved_search_run", map[string]interface{}{"id": created.ID})
+    if n := s.deliveries.Process(context.Background(), time.Now()); n != 2 || len(bodies) != 1 {
+        t.Fatalf("expected a webhook and a slack delivery, tried %d and received %d webhooks", n, len(bodies))
+    }
+    if !strings.Contains(string(slack), `"blocks"`) || !strings.Contains(string(slack), "Facilities: 1 new, 1 amended SAM.gov opportunities") {
+        t.Fatalf("unexpected slack message: %s", slack)
+    }
+    h := headers[0]
+    if !notify.Verify("k", h.Get(notify.HeaderTimestamp), h.Get(notify.HeaderSignature), bodies[0], time.Minute) {
+        t.Fatalf("payload signature does not verify: %v", h)
+    }
+    var ev notify.Event
+    if err := json.Unmarshal(bodies[0], &ev); err != nil {
+        t.Fatal(err)
+    }
+    if ev.Source.ID != created.ID || len(ev.Notices) != 2 || ev.Notices[0].Change != "added" || ev.Notices[0].Opportunity.NoticeID != "B" ||
+        ev.Notices[1].Change != "amended" || strings.Join(ev.Notices[1].Fields, ",") != "modified" {
+        t.Fatalf("unexpected event: %+v", ev)
+    }
+
+    rr := httptest.NewRecorder()
+    s.Router().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/mcp/deliveries?status=delivered", nil))
+    var history struct {
+        Counts     map[string]int `json:"counts"`
+        Deliveries []struct {
+            ID      string `json:"id"`
+            Notices int    `json:"notices"`
+        } `json:"deliveries"`
+    }
+    if err := json.Unmarshal(rr.Body.Bytes(), &history); err != nil || history.Counts["delivered"] != 2 || len(history.Deliveries) != 2 || history.Deliveries[0].Notices != 2 {
+        t.Fatalf("unexpected history (%v): %s", err, rr.Body.String())
+    }
+    for target, want := range map[string]int{
+        "/mcp/deliveries/" + history.Deliveries[0].ID + "/retry": http.StatusConflict,
+        "/mcp/deliveries/nope/retry":                            http.StatusNotFound,
+    } {
+        rr := httptest.NewRecorder()
+        s.Router().ServeHTTP(rr, httptest.NewRequest(http.MethodPost, target, nil))
+        if rr.Code != want {
+            t.Errorf("POST %s: status %d, want %d", target, rr.Code, want)
+        }
+    }
+}
+
+func TestEmailDigestJob(t *testing.T) {
+    mod := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+    q := &sequenceSam{runs: [][]sam.Opportunity{
+        {{NoticeID: "A", Title: "Guard services", Modified: mod}},
+        {{NoticeID: "A", Title: "Guard services", Modified: mod}, {NoticeID: "B", Title: "Janitorial", Modified: mod}},
+        {{NoticeID: "A", Title: "Guard services", Modified: mod}, {NoticeID: "B", Title: "Janitorial", Modified: mod.Add(time.Hour)}},
+    }}
+    smtp := notify.SMTPConfig{Host: "127.0.0.1", Port: 2525, From: "alerts@example.com", Security: notify.SecurityNone}
+    s := New(Config{SMTP: smtp, DigestTo: []string{"Lead@example.com"}}, WithSamClient(q))
+    var created struct{ ID string `json:"id"` }
+    if err := decodeResult(callTool(t, s, "saved_search_create", map[string]interface{}{"name": "Facilities", "params": map[string]interface{}{"days": 7},
+        "notify": []map[string]string{{"type": "email", "address": "bids@example.com"}, {"type": "email", "address": "lead@example.com"}}}), &created); err != nil {
+        t.Fatal(err)
+    }
+    for i := 0; i < 3; i++ {
+        callTool(t, s, "saved_search_run", map[string]interface{}{"id": created.ID})
+    }
+    if c := s.deliveries.Counts(); c[notify.StatusPending] != 0 {
+        t.Fatalf("email targets queued directly instead of held for the digest: %v", c)
+    }
+
+    if _, err := s.scheduler.RunNow(context.Background(), jobEmailDigest); err != nil {
+        t.Fatal(err)
+    }
+    ds := s.deliveries.List(notify.StatusPending, 10)
+    if len(ds) != 2 {
+        t.Fatalf("expected one digest per recipient, got %d", len(ds))
+    }
+    for _, d := range ds {
+        ev := d.Event
+        if d.Target.Type != "email" || ev.Type != notify.EventDigest || ev.Source.ID != d.Target.Address || len(ev.Notices) != 1 {
+            t.Fatalf("unexpected digest delivery: %+v", d)
+        }
+        if n := ev.Notices[0]; n.Opportunity.NoticeID != "B" || n.Change != "added" || n.Source == nil || n.Source.Name != "Facilities" {
+            t.Fatalf("unexpected digest notice: %+v", n)
+        }
+    }
+
+    // Nothing new since the last digest: the next run sends nothing.
+    if _, err := s.scheduler.RunNow(context.Background(), jobEmailDigest); err != nil {
+        t.Fatal(err)
+    }
+    if got := len(s.deliveries.List(notify.StatusPending, 10)); got != 2 {
+        t.Fatalf("empty digest run queued deliveries: %d pending", got)
+    }
+}
+
+func TestFeeds(t *testing.T) {
+    mod := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+    q := &sequenceSam{runs: [][]sam.Opportunity{
+        {{NoticeID: "A", Title: "Guard services", Agency: "GSA", Modified: mod, URL: "https://sam.gov/opp/A/view"}, {NoticeID: "B", Title: "Janitorial & grounds", Modified: mod.Add(time.Hour)}},
+        {{NoticeID: "A", Title: "Guard services", Agency: "GSA", Modified: mod.Add(48 * time.Hour), URL: "https://sam.gov/opp/A/view"}, {NoticeID: "B", Title: "Janitorial & grounds", Modified: mod.Add(time.Hour)}},
+    }}
+    profiles := []PrefetchProfile{{Name: "facilities", Description: "Facilities", Search: sam.SearchParams{Days: 7}, TTL: time.Hour}}
+    s := New(Config{Token: "main", FeedToken: "feed", PrefetchProfiles: profiles}, WithSamClient(q))
+    get := func(target, authz string) *httptest.ResponseRecorder {
+        req := httptest.NewRequest(http.MethodGet, target, nil)
+        if authz != "" {
+            req.Header.Set("Authorization", "Bearer "+authz)
+        }
+        rr := httptest.NewRecorder()
+        s.Router().ServeHTTP(rr, req)
+        return rr
+    }
+    for _, tc := range []struct {
+        target, authz string
+        want          int
+    }{
+        {"/mcp/feeds/facilities.atom", "", http.StatusUnauthorized},
+        {"/mcp/feeds/facilities.atom?token=main", "", http.StatusUnauthorized},
+        {"/mcp/tools?token=feed", "", http.StatusUnauthorized},
+        {"/mcp/tools", "feed", http.StatusUnauthorized},
+        {"/mcp/feeds/facilities.rss", "feed", http.StatusOK},
+        {"/mcp/feeds/facilities.atom", "main", http.StatusOK},
+        {"/mcp/feeds/nope.atom?token=feed", "", http.StatusNotFound},
+    } {
+        if rr := get(tc.target, tc.authz); rr.Code != tc.want {
+            t.Errorf("GET %s (%q): status %d, want %d", tc.target, tc.authz, rr.Code, tc.want)
+        }
+    }
+
+    type atomFeed struct {
+        ID      string `xml:"id"`
+        Updated string `xml:"updated"`
+        Entries []struct {
+            ID      string `xml:"id"`
+            Title   string `xml:"title"`
+            Updated string `xml:"updated"`
+        } `xml:"entry"`
+    }
+    read := func() atomFeed {
+        rr := get("/mcp/feeds/facilities.atom?token=feed", "")
+        if rr.Code != http.StatusOK || rr.Header().Get("Content-Type") != "application/atom+xml; charset=utf-8" {
+            t.Fatalf("feed status %d: %s", rr.Code, rr.Body.String())
+        }
+        var f atomFeed
+        if err := xml.Unmarshal(rr.Body.Bytes(), &f); err != nil {
+            t.Fatal(err)
+        }
+        return f
+    }
+    f := read()
+    if f.ID != "urn:sam-mcp:feed:facilities" || len(f.Entries) != 2 || f.Entries[0].ID != "urn:sam-mcp:notice:B" || f.Entries[0].Title != "Janitorial & grounds" {
+        t.Fatalf("unexpected feed %+v", f)
+    }
+
+    // After an amendment the notice keeps its entry id, with a later updated date.
+    rr := httptest.NewRecorder()
+    req := httptest.NewRequest(http.MethodPost, "/mcp/scheduled?profile=facilities", nil)
+    req.Header.Set("Authorization", "Bearer main")
+    s.Router().ServeHTTP(rr, req)
+    f = read()
+    if len(f.Entries) != 2 || f.Entries[0].ID != "urn:sam-mcp:notice:A" || f.Entries[0].Updated != "2026-03-03T00:00:00Z" || f.Updated != "2026-03-03T00:00:00Z" {
+        t.Fatalf("amendment not shown as an update: %+v", f)
+    }
+}
+
+func TestAccessLogRedactsToken(t *testing.T) {
+    var buf bytes.Buffer
+    f := redactingLogFormatter{&middleware.DefaultLogFormatter{Logger: log.New(&buf, "", 0), NoColor: true}}
+    req := httptest.NewRequest(http.MethodGet, "/mcp/feeds/facilities.atom?token=s3cret&x=1", nil)
+    f.NewLogEntry(req).Write(http.StatusOK, 0, nil, 0, nil)
+    if line := buf.String(); strings.Contains(line, "s3cret") || !strings.Contains(line, "/mcp/feeds/facilities.atom?token=REDACTED&x=1") {
+        t.Fatalf("token not redacted: %s", line)
+    }
+    if req.URL.Query().Get("token") != "s3cret" {
+        t.Fatal("request modified")
+    }
+}
+
+func TestDeadlineCalendar(t *testing.T) {
+    mod := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+    edt := time.FixedZone("EDT", -4*3600)
+    due, moved, dateOnly := time.Date(2026, 4, 1, 14, 0, 0, 0, edt), time.Date(2026, 4, 8, 14, 0, 0, 0, edt), time.Date(2026, 4, 15, 0, 0, 0, 0, time.UTC)
+    guard := sam.Opportunity{NoticeID: "A", Title: "Guard services", Agency: "GSA", Modified: mod, ResponseDeadline: &due, URL: "https://sam.gov/opp/A/view",
+        Description: "Site visit on March 10. Questions are due no later than March 12, 2026 at 3:00 PM Central."}
+    amended := guard
+    amended.Modified, amended.ResponseDeadline = mod.Add(24*time.Hour), &moved
+    q := &sequenceSam{runs: [][]sam.Opportunity{
+        {guard, {NoticeID: "B", Title: "Janitorial", Modified: mod, ResponseDeadline: &dateOnly, ResponseDeadlineDateOnly: true}, {NoticeID: "C", Title: "Sources sought", Modified: mod}},
+        {amended},
+    }}
+    profiles := []PrefetchProfile{{Name: "facilities", Description: "Facilities", Search: sam.SearchParams{Days: 7}, TTL: time.Hour}}
+    s := New(Config{Token: "main", FeedToken: "feed", PrefetchProfiles: profiles}, WithSamClient(q))
+    get := func(target string) *httptest.ResponseRecorder {
+        rr := httptest.NewRecorder()
+        s.Router().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+        return rr
+    }
+
+    rr := get("/mcp/feeds/facilities.ics?token=feed")
+    if rr.Code != http.StatusOK || rr.Header().Get("Content-Type") != "text/calendar; charset=utf-8" {
+        t.Fatalf("calendar status %d: %s", rr.Code, rr.Body.String())
+    }
+    cal := strings.ReplaceAll(rr.Body.String(), "\r\n ", "")
+    if n := strings.Count(cal, "BEGIN:VEVENT"); n != 3 {
+        t.Fatalf("expected response deadlines of A and B and the questions deadline of A, got %d events:\n%s", n, cal)
+    }
+    for _, want := range []string{
+        "X-WR-CALNAME:SAM.gov deadlines: Facilities",
+        "UID:A-response@sam-mcp\r\n",
+        "DTSTART:20260401T180000Z",
+        "SUMMARY:Response due: Guard services",
+        "UID:A-questions@sam-mcp\r\n",
+        // 3 PM Central in March is 20:00 UTC.
+        "DTSTART:20260312T200000Z",
+        "DTSTART;VALUE=DATE:20260415",
+        "TRIGGER:-P7D",
+        "TRIGGER:-PT2H",
+    } {
+        if !strings.Contains(cal, want) {
+            t.Errorf("calendar lacks %q:\n%s", want, cal)
+        }
+    }
+
+    // The amended deadline keeps its UID with a higher sequence.
+    req := httptest.NewRequest(http.MethodPost, "/mcp/scheduled?profile=facilities", nil)
+    req.Header.Set("Authorization", "Bearer main")
+    s.Router().ServeHTTP(httptest.NewRecorder(), req)
+    cal = strings.ReplaceAll(get("/mcp/feeds/facilities.ics?token=feed&alarms=1d").Body.String(), "\r\n ", "")
+    if !strings.Contains(cal, "UID:A-response@sam-mcp\r\n") || !strings.Contains(cal, "DTSTART:20260408T180000Z") || !strings.Contains(cal, "SEQUENCE:1") ||
+        strings.Count(cal, "BEGIN:VALARM") != 2 || !strings.Contains(cal, "TRIGGER:-P1D") {
+        t.Fatalf("amendment not reflected:\n%s", cal)
+    }
+    if rr := get("/mcp/feeds/facilities.ics?token=feed&alarms=soon"); rr.Code != http.StatusBadRequest {
+        t.Fatalf("expected 400 for bad alarms, got %d", rr.Code)
+    }
+}
+
+// describingSam serves descriptions keyed by description link.
+type describingSam struct {
+    sequenceSam
+    descriptions map[string]string
+}
+
+func (d *describingSam) NoticeDescription(_ context.Context, descriptionURL string) (string, error) {
+    return d.descriptions[descriptionURL], nil
+}
+
+func TestDiffOpportunity(t *testing.T) {
+    mod := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+    v1 := sam.Opportunity{NoticeID: "A", Title: "Guard services", Modified: mod, DescriptionURL: "desc/A/1", Attachments: []string{"sow.pdf"}}
+    v2 := v1
+    v2.Modified, v2.DescriptionURL, v2.Attachments = mod.Add(24*time.Hour), "desc/A/2", []string{"sow.pdf", "qa.pdf"}
+    d := &describingSam{
+        sequenceSam:  sequenceSam{runs: [][]sam.Opportunity{{v1}, {v2}}},
+        descriptions: map[string]string{"desc/A/1": "Scope.\nResponses due March 20.", "desc/A/2": "Scope.\nResponses due March 27."},
+    }
+    s := New(Config{}, WithSamClient(d))
+    var created struct{ ID string `json:"id"` }
+    if err := decodeResult(callTool(t, s, "saved_search_create", map[string]interface{}{"name": "Guards", "params": map[string]interface{}{"days": 7}}), &created); err != nil {
+        t.Fatal(err)
+    }
+    callTool(t, s, "saved_search_run", map[string]interface{}{"id": created.ID})
+    if vs := s.versions.List("A"); len(vs) != 1 || vs[0].DescriptionFetched {
+        t.Fatalf("search fetched the description: %+v", vs)
+    }
+
+    var out struct {
+        Versions []struct{ Version int `json:"version"` } `json:"versions"`
+        Diff     *struct {
+            Fields []struct {
+                Field string `json:"field"`
+            } `json:"fields"`
+            Description []struct{ Op, Text string } `json:"description"`
+            AttachmentsAdded []string `json:"attachmentsAdded"`
+        } `json:"diff"`
+    }
+    if err := decodeResult(callTool(t, s, "sam_diff_opportunity", map[string]interface{}{"noticeId": "A"}), &out); err != nil {
+        t.Fatal(err)
+    }
+    if len(out.Versions) != 1 || out.Diff != nil {
+        t.Fatalf("one version has nothing to compare: %+v", out)
+    }
+
+    callTool(t, s, "saved_search_run", map[string]interface{}{"id": created.ID})
+    rr := callTool(t, s, "sam_diff_opportunity", map[string]interface{}{"noticeId": "A"})
+    if err := decodeResult(rr, &out); err != nil {
+        t.Fatal(err)
+    }
+    if len(out.Versions) != 2 || out.Diff == nil || len(out.Diff.Fields) != 1 || out.Diff.Fields[0].Field != "modified" ||
+        strings.Join(out.Diff.AttachmentsAdded, ",") != "qa.pdf" {
+        t.Fatalf("unexpected diff: %+v", out)
+    }
+    var lines []string
+    for _, l := range out.Diff.Description {
+        lines = append(lines, l.Op+l.Text)
+    }
+    if strings.Join(lines, "|") != " Scope.|-Responses due March 20.|+Responses due March 27." {
+        t.Fatalf("unexpected description diff: %q", lines)
+    }
+    if res, _ := readResult(rr); len(res.Content) == 0 || !strings.Contains(res.Content[0].Text, "+ Responses due March 27.") {
+        t.Fatalf("unexpected text: %+v", res.Content)
+    }
+    if rr := callTool(t, s, "sam_diff_opportunity", map[string]interface{}{"noticeId": "A", "from": 2, "to": 1}); !isToolError(rr) {
+        t.Fatalf("expected tool error for reversed versions, got %s", rr.Body.String())
+    }
+}
+
+func TestHealthReportsSamEnv(t *testing.T) {
+    cases := []struct {
+        cfg  Config
+        opts []Option
+        want string
+    }{
+        {Config{}, nil, samEnvMock},
+        {Config{SamAPIKey: "k", SamEnv: "alpha"}, nil, "alpha"},
+        {Config{SamEnv: "alpha"}, []Option{WithSamClient(&fakeSam{})}, "alpha"},
+    }
+    for _, tc := range cases {
+        s := New(tc.cfg, tc.opts...)
+        rr := httptest.NewRecorder()
+        s.Router().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health", nil))
+        var resp map[string]string
+        if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+            t.Fatalf("invalid json: %v", err)
+        }
+        if resp["samEnv"] != tc.want {
+            t.Errorf("samEnv = %q, want %q", resp["samEnv"], tc.want)
+        }
+    }
+}
+
+// decodeResult decodes the structuredContent of a successful tools/call response into v.
+func decodeResult(rr *httptest.ResponseRecorder, v interface{}) error {
+    res, err := readResult(rr)
+    if err != nil {
+        return err
+    }
+    if res.IsError {
+        return fmt.Errorf("tool error: %s", res.StructuredContent)
+    }
+    return json.Unmarshal(res.StructuredContent, v)
+}
+
+type testResult struct {
+    Content []struct {
+        Type string `json:"type"`
+        Text string `json:"text"`
+    } `json:"content"`
+    StructuredContent json.RawMessage `json:"structuredContent"`
+    IsError           bool            `json:"isError"`
+}
+
+func readResult(rr *httptest.ResponseRecorder) (*testResult, error) {
+    if rr.Code != http.StatusOK {
+        return nil, fmt.Errorf("status %d: %s", rr.Code, rr.Body.String())
+    }
+    var res testResult
+    if err := json.Unmarshal(rr.Body.Bytes(), &res); err != nil {
+        return nil, err
+    }
+    return &res, nil
+}
+
+// decodeError decodes the structuredContent of an isError tools/call response into v.
+func decodeError(rr *httptest.ResponseRecorder, v interface{}) error {
+    res, err := readResult(rr)
+    if err != nil {
+        return err
+    }
+    if !res.IsError {
+        return fmt.Errorf("expected tool error, got %s", res.StructuredContent)
+    }
+    return json.Unmarshal(res.StructuredContent, v)
+}
+
+// isToolError reports whether rr holds a tools/call result with isError set.
+func isToolError(rr *httptest.ResponseRecorder) bool {
+    res, err := readResult(rr)
+    return err == nil && res.IsError
+}
+
+func callTool(t *testing.T, s *Server, name string, args map[string]interface{}) *httptest.ResponseRecorder {
+    t.Helper()
+    body, _ := json.Marshal(map[string]interface{}{"name": name, "arguments": args})
+    req := httptest.NewRequest(http.MethodPost, "/mcp/call", bytes.NewReader(body))
+    rr := httptest.NewRecorder()
+    s.Router().ServeHTTP(rr, req)
+    return rr
+}
+
+func TestEntityLookup(t *testing.T) {
+    fake := &fakeSam{}
+    s := New(Config{}, WithSamClient(fake))
+
+    if rr := callTool(t, s, "sam_entity_lookup", map[string]interface{}{}); !isToolError(rr) {
+        t.Fatalf("expected tool error without selector, got %d: %s", rr.Code, rr.Body.String())
+    }
+
+    for i := 0; i < 2; i++ {
+        rr := callTool(t, s, "sam_entity_lookup", map[string]interface{}{"uei": "ABC123DEF456"})
+        if rr.Code != http.StatusOK {
+            t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+        }
+        var resp struct{ Entities []sam.Entity `json:"entities"` }
+        if err := decodeResult(rr, &resp); err != nil {
+            t.Fatalf("invalid json: %v", err)
+        }
+        if len(resp.Entities) != 1 || resp.Entities[0].UEI != "ABC123DEF456" {
+            t.Fatalf("unexpected entities: %+v", resp.Entities)
+        }
+    }
+    if fake.entityCalls != 1 {
+        t.Fatalf("expected cached second lookup, client called %d times", fake.entityCalls)
+    }
+}
+
+func TestCheckExclusions(t *testing.T) {
+    s := New(Config{})
+    rr := callTool(t, s, "sam_check_exclusions", map[string]interface{}{"ueis": []string{"ABC123DEF456"}, "names": []string{"Excluded Corp"}})
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+    }
+    var resp struct{ Results []sam.ExclusionResult `json:"results"` }
+    if err := decodeResult(rr, &resp); err != nil {
+        t.Fatalf("invalid json: %v", err)
+    }
+    if len(resp.Results) != 2 || resp.Results[0].Excluded || !resp.Results[1].Excluded {
+        t.Fatalf("unexpected results: %+v", resp.Results)
+    }
+
+    if rr := callTool(t, s, "sam_check_exclusions", map[string]interface{}{}); !isToolError(rr) {
+        t.Fatalf("expected tool error without entities, got %d: %s", rr.Code, rr.Body.String())
+    }
+}
+
+type awardSam struct{ mockSamClient }
+
+func (awardSam) Search(_ context.Context, _ sam.SearchParams) ([]sam.Opportunity, error) {
+    return []sam.Opportunity{
+        {Title: "Award", Award: &sam.Award{Awardee: &sam.Awardee{Name: "Excluded Corp"}}},
+        {Title: "Solicitation"},
+    }, nil
+}
+
+func TestSamSearchAnnotatesExcludedAwardees(t *testing.T) {
+    s := New(Config{}, WithSamClient(awardSam{}))
+    rr := callTool(t, s, "sam_search", map[string]interface{}{"days": 7, "checkExclusions": true})
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+    }
+    var resp struct{ Results []sam.Opportunity `json:"results"` }
+    if err := decodeResult(rr, &resp); err != nil {
+        t.Fatalf("invalid json: %v", err)
+    }
+    ex := resp.Results[0].Award.Awardee.Excluded
+    if ex == nil || !*ex {
+        t.Fatalf("expected awardee flagged as excluded: %+v", resp.Results[0].Award.Awardee)
+    }
+
+    // The cached search must not carry the annotation into plain calls.
+    rr = callTool(t, s, "sam_search", map[string]interface{}{"days": 7})
+    resp.Results = nil
+    _ = decodeResult(rr, &resp)
+    if resp.Results[0].Award.Awardee.Excluded != nil {
+        t.Fatal("annotation leaked into cached results")
+    }
+}
+
+func TestContractAwards(t *testing.T) {
+    s := New(Config{})
+    if rr := callTool(t, s, "sam_contract_awards", map[string]interface{}{}); !isToolError(rr) {
+        t.Fatalf("expected tool error without filters, got %d: %s", rr.Code, rr.Body.String())
+    }
+    rr := callTool(t, s, "sam_contract_awards", map[string]interface{}{"solicitationNumber": "SOL-1"})
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+    }
+    var resp struct{ Awards []sam.ContractAward `json:"awards"` }
+    if err := decodeResult(rr, &resp); err != nil {
+        t.Fatalf("invalid json: %v", err)
+    }
+    if len(resp.Awards) != 1 || resp.Awards[0].SolicitationNumber != "SOL-1" || resp.Awards[0].Vendor.Name == "" {
+        t.Fatalf("unexpected awards: %+v", resp.Awards)
+    }
+}
+
+func TestResolveOrganization(t *testing.T) {
+    s := New(Config{})
+    rr := callTool(t, s, "sam_resolve_organization", map[string]interface{}{"name": "Army"})
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+    }
+    var resp struct{ Matches []sam.OrgMatch `json:"matches"` }
+    if err := decodeResult(rr, &resp); err != nil {
+        t.Fatalf("invalid json: %v", err)
+    }
+    if len(resp.Matches) == 0 || resp.Matches[0].Organization.Name != "DEPT OF THE ARMY" || resp.Matches[0].Path != "DEPT OF DEFENSE > DEPT OF THE ARMY" {
+        t.Fatalf("unexpected matches: %+v", resp.Matches)
+    }
+}
+
+// gatedOrgSam holds hierarchy requests until release is closed and counts department fetches.
+type gatedOrgSam struct {
+    mockSamClient
+    release     chan struct{}
+    departments atomic.Int32
+}
+
+func (g *gatedOrgSam) SearchOrganizations(ctx context.Context, p sam.OrgParams) (*sam.OrgPage, error) {
+    <-g.release
+    if p.Level == 1 {
+        g.departments.Add(1)
+    }
+    return g.mockSamClient.SearchOrganizations(ctx, p)
+}
+
+func TestOrgIndexLoadsOnce(t *testing.T) {
+    fake := &gatedOrgSam{release: make(chan struct{})}
+    s := New(Config{}, WithSamClient(fake))
+    first := s.startOrgIndexLoad()
+    var wg sync.WaitGroup
+    for i := 0; i < 4; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            if c := s.startOrgIndexLoad(); c != first {
+                t.Error("started a second hierarchy load while one was in flight")
+            }
+        }()
+    }
+    wg.Wait()
+    close(fake.release)
+    <-first.done
+    if first.err != nil || len(first.orgs) == 0 || fake.departments.Load() != 1 {
+        t.Fatalf("load = %d orgs, %v; departments fetched %d times", len(first.orgs), first.err, fake.departments.Load())
+    }
+}
+
+func TestSamSearchResolvesOrganization(t *testing.T) {
+    fake := &fakeSam{}
+    s := New(Config{}, WithSamClient(fake))
+    rr := callTool(t, s, "sam_search", map[string]interface{}{"days": 7, "organization": "Department of Veterans Affairs"})
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+    }
+    if len(fake.params) != 1 || fake.params[0].OrgCode != "100000135" {
+        t.Fatalf("organization not resolved: %+v", fake.params)
+    }
+    var resp map[string]interface{}
+    _ = decodeResult(rr, &resp)
+    if _, ok := resp["resolvedOrganization"]; !ok {
+        t.Fatal("expected resolvedOrganization in response")
+    }
+
+    // An agency code is resolved through the index; only a hierarchy id passes through.
+    for org, want := range map[string]string{"2100": "100000001", "100000135": "100000135"} {
+        fake := &fakeSam{}
+        s := New(Config{}, WithSamClient(fake))
+        if rr := callTool(t, s, "sam_search", map[string]interface{}{"days": 7, "organization": org}); rr.Code != http.StatusOK {
+            t.Fatalf("%s: expected 200, got %d: %s", org, rr.Code, rr.Body.String())
+        }
+        if len(fake.params) != 1 || fake.params[0].OrgCode != want {
+            t.Fatalf("%s: organizationCode = %+v, want %s", org, fake.params, want)
+        }
+    }
+}
+
+func TestAssistanceSearch(t *testing.T) {
+    s := New(Config{})
+    if rr := callTool(t, s, "sam_assistance_search", map[string]interface{}{}); !isToolError(rr) {
+        t.Fatalf("expected tool error without filters, got %d: %s", rr.Code, rr.Body.String())
+    }
+    rr := callTool(t, s, "sam_assistance_search", map[string]interface{}{"keyword": "science", "agency": "GSA"})
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+    }
+    var resp struct {
+        Listings []sam.AssistanceListing `json:"listings"`
+        Resolved *sam.OrgMatch           `json:"resolvedOrganization"`
+    }
+    if err := decodeResult(rr, &resp); err != nil {
+        t.Fatalf("invalid json: %v", err)
+    }
+    if len(resp.Listings) != 1 || resp.Listings[0].Number == "" {
+        t.Fatalf("unexpected listings: %+v", resp.Listings)
+    }
+    if resp.Resolved == nil || resp.Resolved.Organization.AgencyCode != "4700" {
+        t.Fatalf("agency not resolved: %+v", resp.Resolved)
+    }
+}
+
+func TestWageDetermination(t *testing.T) {
+    s := New(Config{})
+    for _, args := range []map[string]interface{}{
+        {}, {"number": "4281"}, {"number": "2015-4281", "revision": 0}, {"number": "2015-4281", "state": "VA"},
+        {"state": "Virginia"}, {"state": "VA", "revision": 3}, {"number": "2015-4281", "county": "Fairfax"},
+    } {
+        if rr := callTool(t, s, "sam_wage_determination", args); !isToolError(rr) {
+            t.Fatalf("expected tool error for %v, got %d: %s", args, rr.Code, rr.Body.String())
+        }
+    }
+    for _, tc := range []struct {
+        args map[string]interface{}
+        want wageResult
+    }{
+        {map[string]interface{}{"number": "2015-4281", "revision": 25},
+            wageResult{Number: "2015-4281", Type: sam.WDTypeSCA, Revision: 25, URL: "https://sam.gov/wage-determination/2015-4281/25"}},
+        {map[string]interface{}{"number": "va20240001", "revision": 25},
+            wageResult{Number: "VA20240001", Type: sam.WDTypeDBA, Revision: 25, URL: "https://sam.gov/wage-determination/VA20240001/25"}},
+        {map[string]interface{}{"state": "va", "county": "Fairfax"},
+            wageResult{State: "VA", County: "Fairfax", URL: "https://sam.gov/search/?index=wd&wdCounty=Fairfax&wdStateCode=VA"}},
+    } {
+        rr := callTool(t, s, "sam_wage_determination", tc.args)
+        if rr.Code != http.StatusOK {
+            t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+        }
+        var resp wageResult
+        if err := decodeResult(rr, &resp); err != nil {
+            t.Fatalf("invalid json: %v", err)
+        }
+        if resp != tc.want {
+            t.Fatalf("unexpected wage determination for %v: %+v", tc.args, resp)
+        }
+    }
+}
+
+func TestSamSearchValidatesNAICS(t *testing.T) {
+    s := New(Config{})
+    rr := callTool(t, s, "sam_search", map[string]interface{}{"days": 7, "naics": []string{"541511", "54151x"}})
+    if !isToolError(rr) {
+        t.Fatalf("expected tool error, got %d: %s", rr.Code, rr.Body.String())
+    }
+    var bad struct {
+        Error struct {
+            Data struct{ Invalid []naicsIssue `json:"invalid"` } `json:"data"`
+        } `json:"error"`
+    }
+    if err := decodeError(rr, &bad); err != nil {
+        t.Fatalf("invalid json: %v", err)
+    }
+    invalid := bad.Error.Data.Invalid
+    if len(invalid) != 1 || invalid[0].Code != "54151x" || len(invalid[0].Suggestions) == 0 {
+        t.Fatalf("unexpected issues: %+v", invalid)
+    }
+
+    rr = callTool(t, s, "sam_search", map[string]interface{}{"days": 7, "naics": []string{"541511"}})
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+    }
+    var resp struct{ Results []sam.Opportunity `json:"results"` }
+    if err := decodeResult(rr, &resp); err != nil {
+        t.Fatalf("invalid json: %v", err)
+    }
+    if resp.Results[0].NAICSTitle != "Custom Computer Programming Services" || resp.Results[0].PSCTitle == "" {
+        t.Fatalf("results not enriched: %+v", resp.Results[0])
+    }
+    if rr := callTool(t, s, "sam_search", map[string]interface{}{"days": 7, "naic": []string{"541511"}}); !isToolError(rr) {
+        t.Fatalf("expected tool error for misspelled naics, got %d: %s", rr.Code, rr.Body.String())
+    }
+}
+
+func TestNAICSAndPSCLookup(t *testing.T) {
+    s := New(Config{})
+    rr := callTool(t, s, "naics_lookup", map[string]interface{}{"query": "engineering services"})
+    var resp struct{ Codes []naicsCode `json:"codes"` }
+    if err := decodeResult(rr, &resp); err != nil {
+        t.Fatalf("invalid json: %v", err)
+    }
+    if len(resp.Codes) == 0 || resp.Codes[0].Code != "541330" || resp.Codes[0].SizeStandard == "" {
+        t.Fatalf("unexpected naics codes: %+v", resp.Codes)
+    }
+    rr = callTool(t, s, "psc_lookup", map[string]interface{}{"query": "R4", "limit": 3})
+    var psc struct{ Codes []pscCode `json:"codes"` }
+    if err := decodeResult(rr, &psc); err != nil {
+        t.Fatalf("invalid json: %v", err)
+    }
+    if len(psc.Codes) != 3 || psc.Codes[0].Kind != "service" {
+        t.Fatalf("unexpected psc codes: %+v", psc.Codes)
+    }
+}
+
+func TestCallValidatesArguments(t *testing.T) {
+    fake := &fakeSam{}
+    s := New(Config{}, WithSamClient(fake))
+    rr := callTool(t, s, "sam_search", map[string]interface{}{"limit": 500, "naics": []interface{}{"541511", 7}, "checkExclusions": "yes"})
+    if !isToolError(rr) {
+        t.Fatalf("expected tool error, got %d: %s", rr.Code, rr.Body.String())
+    }
+    var resp struct {
+        Error struct {
+            Code int `json:"code"`
+            Data struct{ Errors []mcp.FieldError `json:"errors"` } `json:"data"`
+        } `json:"error"`
+    }
+    if err := decodeError(rr, &resp); err != nil {
+        t.Fatalf("invalid json: %v", err)
+    }
+    if resp.Error.Code != mcp.CodeInvalidParams {
+        t.Fatalf("unexpected error code %d", resp.Error.Code)
+    }
+    got := map[string]string{}
+    for _, e := range resp.Error.Data.Errors {
+        got[e.Field] = e.Message
+    }
+    want := map[string]string{"days": "is required", "limit": "must be <= 100", "naics[1]": "must be a string", "checkExclusions": "must be a boolean"}
+    for f, m := range want {
+        if got[f] != m {
+            t.Errorf("field %s: got %q, want %q (all: %+v)", f, got[f], m, got)
+        }
+    }
+
+    // Declared defaults are applied before dispatch.
+    rr = callTool(t, s, "sam_search", map[string]interface{}{"days": 7})
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+    }
+    if len(fake.params) != 1 || fake.params[0].Limit != 25 {
+        t.Fatalf("default limit not applied: %+v", fake.params)
+    }
+}
+
+type echoArgs struct {
+    Text  string `json:"text" jsonschema:"required"`
+    Times int    `json:"times" jsonschema:"min=1,max=3,default=1"`
+}
+
+type echoResult struct {
+    Text string `json:"text"`
+}
+
+func TestWithToolsRegistersExternalTool(t *testing.T) {
+    echo := mcp.NewTool("echo", "Repeat text", func(_ context.Context, a echoArgs) (echoResult, error) {
+        return echoResult{Text: strings.Repeat(a.Text, a.Times)}, nil
+    })
+    s := New(Config{}, WithTools(echo))
+
+    rr := httptest.NewRecorder()
+    s.Router().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/mcp/tools", nil))
+    var list struct{ Tools []Tool `json:"tools"` }
+    if err := json.NewDecoder(rr.Body).Decode(&list); err != nil {
+        t.Fatalf("invalid json: %v", err)
+    }
+    last := list.Tools[len(list.Tools)-1]
+    if last.Name != "echo" || last.InputSchema["required"] == nil || last.OutputSchema["type"] != "object" {
+        t.Fatalf("unexpected tool listing: %+v", last)
+    }
+
+    rr = callTool(t, s, "echo", map[string]interface{}{"text": "ab"})
+    var resp echoResult
+    if err := decodeResult(rr, &resp); err != nil {
+        t.Fatalf("invalid json: %v", err)
+    }
+    if resp.Text != "ab" {
+        t.Fatalf("default not applied: %+v", resp)
+    }
+
+    if rr := callTool(t, s, "nope", nil); rr.Code != http.StatusNotFound {
+        t.Fatalf("expected 404 for unknown tool, got %d", rr.Code)
+    }
+}
+
+func TestResourcesListAndRead(t *testing.T) {
+    s := New(Config{})
+    callTool(t, s, "sam_search", map[string]interface{}{"days": 7})
+
+    rr := httptest.NewRecorder()
+    s.Router().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/mcp/resources", nil))
+    var list struct{ Resources []mcp.Resource `json:"resources"` }
+    if err := json.NewDecoder(rr.Body).Decode(&list); err != nil {
+        t.Fatalf("invalid json: %v", err)
+    }
+    found := false
+    for _, r := range list.Resources {
+        found = found || r.URI == "sam://opportunity/mock-0001"
+    }
+    if !found {
+        t.Fatalf("cached opportunity not listed: %+v", list.Resources)
+    }
+
+    read := func(uri string) *httptest.ResponseRecorder {
+        body, _ := json.Marshal(map[string]string{"uri": uri})
+        rr := httptest.NewRecorder()
+        s.Router().ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/mcp/resources/read", bytes.NewReader(body)))
+        return rr
+    }
+    rr = read("sam://opportunity/mock-0001")
+    var contents struct{ Contents []mcp.ResourceContents `json:"contents"` }
+    if err := json.NewDecoder(rr.Body).Decode(&contents); err != nil {
+        t.Fatalf("invalid json: %v", err)
+    }
+    var opp sam.Opportunity
+    if len(contents.Contents) != 1 || json.Unmarshal([]byte(contents.Contents[0].Text), &opp) != nil || opp.Title != "Example Opportunity" {
+        t.Fatalf("unexpected contents: %+v", contents)
+    }
+    if rr := read("sam://search/prefetch"); rr.Code != http.StatusOK {
+        t.Fatalf("expected 200 for prefetch search, got %d: %s", rr.Code, rr.Body.String())
+    }
+    for _, uri := range []string{"sam://opportunity/missing", "sam://search/nope", "https://example.com"} {
+        if rr := read(uri); rr.Code != http.StatusNotFound {
+            t.Errorf("%s: expected 404, got %d", uri, rr.Code)
+        }
+    }
+}
+
+type amendingSam struct {
+    mockSamClient
+    calls int
+}
+
+func (a *amendingSam) Search(_ context.Context, _ sam.SearchParams) ([]sam.Opportunity, error) {
+    a.calls++
+    return []sam.Opportunity{{NoticeID: "N1", Title: "Amended", Modified: time.Date(2026, 1, a.calls, 0, 0, 0, 0, time.UTC)}}, nil
+}
+
+func TestResourceSubscriptionNotifiesAmendments(t *testing.T) {
+    s := New(Config{}, WithSamClient(&amendingSam{}))
+    ts := httptest.NewServer(s.Router())
+    defer ts.Close()
+
+    resp, err := http.Get(ts.URL + "/mcp/events")
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer resp.Body.Close()
+    session := resp.Header.Get("Mcp-Session-Id")
+    events := bufio.NewScanner(resp.Body)
+
+    post := func(path string, body interface{}) *http.Response {
+        b, _ := json.Marshal(body)
+        req, _ := http.NewRequest(http.MethodPost, ts.URL+path, bytes.NewReader(b))
+        req.Header.Set("Mcp-Session-Id", session)
+        res, err := http.DefaultClient.Do(req)
+        if err != nil {
+            t.Fatal(err)
+        }
+        res.Body.Close()
+        return res
+    }
+    if res := post("/mcp/resources/subscribe", map[string]string{"uri": "sam://opportunity/N1"}); res.StatusCode != http.StatusOK {
+        t.Fatalf("subscribe: status %d", res.StatusCode)
+    }
+    post("/mcp/scheduled", nil)
+    post("/mcp/scheduled", nil)
+
+    var got []string
+    for events.Scan() && len(got) < 2 {
+        if line := events.Text(); strings.HasPrefix(line, "data: {\"jsonrpc\"") {
+            got = append(got, line)
+        }
+    }
+    if len(got) != 2 || !strings.Contains(got[0], "list_changed") || !strings.Contains(got[1], `"uri":"sam://opportunity/N1"`) {
+        t.Fatalf("unexpected notifications: %v", got)
+    }
+}
+
+func TestStartEndsEventStreamsOnCancel(t *testing.T) {
+    s := New(Config{})
+    ctx, cancel := context.WithCancel(context.Background())
+    s.Start(ctx)
+    ts := httptest.NewServer(s.Router())
+    defer ts.Close()
+
+    resp, err := http.Get(ts.URL + "/mcp/events")
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer resp.Body.Close()
+    cancel()
+    done := make(chan struct{})
+    go func() {
+        _, _ = io.Copy(io.Discard, resp.Body)
+        close(done)
+    }()
+    select {
+    case <-done:
+    case <-time.After(5 * time.Second):
+        t.Fatal("event stream still open after shutdown")
+    }
+}
+
+func TestPrompts(t *testing.T) {
+    s := New(Config{})
+    rr := httptest.NewRecorder()
+    s.Router().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/mcp/prompts", nil))
+    var list struct{ Prompts []mcp.Prompt `json:"prompts"` }
+    if err := json.NewDecoder(rr.Body).Decode(&list); err != nil {
+        t.Fatalf("invalid json: %v", err)
+    }
+    if len(list.Prompts) == 0 || list.Prompts[0].Name != "bid_no_bid" || len(list.Prompts[0].Arguments) != 2 {
+        t.Fatalf("unexpected prompts: %+v", list.Prompts)
+    }
+
+    get := func(name string, args map[string]string) *httptest.ResponseRecorder {
+        body, _ := json.Marshal(map[string]interface{}{"name": name, "arguments": args})
+        rr := httptest.NewRecorder()
+        s.Router().ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/mcp/prompts/get", bytes.NewReader(body)))
+        return rr
+    }
+    rr = get("bid_no_bid", map[string]string{"noticeId": "mock-0001", "companyProfile": "Small IT services firm"})
+    var res mcp.GetPromptResult
+    if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+        t.Fatalf("invalid json: %v", err)
+    }
+    if len(res.Messages) != 1 || !strings.Contains(res.Messages[0].Content.Text, "Example Opportunity") {
+        t.Fatalf("opportunity not embedded: %+v", res)
+    }
+    for _, tc := range []struct {
+        name string
+        args map[string]string
+    }{
+        {"nope", nil},
+        {"bid_no_bid", map[string]string{"noticeId": "mock-0001"}},
+        {"bid_no_bid", map[string]string{"noticeId": "missing", "companyProfile": "x"}},
+    } {
+        if rr := get(tc.name, tc.args); rr.Code != http.StatusBadRequest {
+            t.Errorf("%s %v: expected 400, got %d: %s", tc.name, tc.args, rr.Code, rr.Body.String())
+        }
+    }
+}
+
+func TestCompletion(t *testing.T) {
+    s := New(Config{})
+    callTool(t, s, "sam_search", map[string]interface{}{"days": 7})
+    complete := func(ref map[string]string, arg, value string) (*httptest.ResponseRecorder, mcp.Completion) {
+        body, _ := json.Marshal(map[string]interface{}{"ref": ref, "argument": map[string]string{"name": arg, "value": value}})
+        rr := httptest.NewRecorder()
+        s.Router().ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/mcp/completion/complete", bytes.NewReader(body)))
+        var resp struct{ Completion mcp.Completion `json:"completion"` }
+        _ = json.Unmarshal(rr.Body.Bytes(), &resp)
+        return rr, resp.Completion
+    }
+    search := map[string]string{"type": "ref/tool", "name": "sam_search"}
+    // A cold hierarchy index yields no organization candidates and loads in the background.
+    if _, c := complete(search, "organization", "arm"); len(c.Values) != 0 {
+        t.Errorf("expected no organization completions on a cold index, got %+v", c)
+    }
+    if _, err := s.loadOrgIndex(context.Background()); err != nil {
+        t.Fatal(err)
+    }
+    cases := []struct {
+        ref       map[string]string
+        arg, val  string
+        wantFirst string
+    }{
+        {search, "naics", "54151", "541511"},
+        {search, "naics", "janitorial", "561720"},
+        {search, "noticeType", "k", "k"},
+        {search, "noticeType", "sources", "r"},
+        {search, "organization", "Veterans", "VETERANS AFFAIRS, DEPARTMENT OF"},
+        {search, "organization", "arm", "DEPT OF THE ARMY"},
+        {map[string]string{"type": "ref/prompt", "name": "bid_no_bid"}, "noticeId", "mock", "mock-0001"},
+        {map[string]string{"type": "ref/resource", "uri": "sam://search/{savedSearchId}"}, "savedSearchId", "pre", "prefetch"},
+    }
+    for _, tc := range cases {
+        rr, c := complete(tc.ref, tc.arg, tc.val)
+        if rr.Code != http.StatusOK || len(c.Values) == 0 || c.Values[0] != tc.wantFirst || len(c.Candidates) != len(c.Values) {
+            t.Errorf("%s=%q: got %d %+v, want first %q", tc.arg, tc.val, rr.Code, c, tc.wantFirst)
+        }
+    }
+    if _, c := complete(search, "q", "cloud"); len(c.Values) != 0 {
+        t.Errorf("expected no completions for free text, got %+v", c)
+    }
+    if rr, _ := complete(map[string]string{"type": "ref/prompt", "name": "nope"}, "noticeId", ""); rr.Code != http.StatusBadRequest {
+        t.Errorf("expected 400 for unknown prompt, got %d", rr.Code)
+    }
 }
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"log"
+	"path/filepath"
+
+	"sam-mcp/internal/notify"
+	"sam-mcp/internal/savedsearch"
+	"sam-mcp/internal/snapshot"
+	"sam-mcp/internal/versions"
+)
+
+// State files kept in Config.DataDir.
+const (
+	savedSearchFile = "saved_searches.json"
+	snapshotFile    = "snapshots.json"
+	versionFile     = "versions.json"
+	deliveryFile    = "deliveries.json"
+	digestFile      = "digest.json"
+)
+
+// dataPath is the path of a state file; empty, meaning in memory, without a data directory.
+func dataPath(dataDir, name string) string {
+	if dataDir == "" {
+		return ""
+	}
+	return filepath.Join(dataDir, name)
+}
+
+// CheckDataDir reports the first state file in dataDir that cannot be loaded.
+func CheckDataDir(dataDir string) error {
+	if _, err := savedsearch.Open(dataPath(dataDir, savedSearchFile)); err != nil {
+		return err
+	}
+	if _, err := snapshot.Open(dataPath(dataDir, snapshotFile)); err != nil {
+		return err
+	}
+	if _, err := versions.Open(dataPath(dataDir, versionFile)); err != nil {
+		return err
+	}
+	if _, err := notify.Open(dataPath(dataDir, deliveryFile)); err != nil {
+		return err
+	}
+	_, err := notify.OpenDigest(dataPath(dataDir, digestFile))
+	return err
+}
+
+// The open functions below fall back to an in-memory store so that a damaged file is
+// reported rather than overwritten; cmd/sam-mcp-http calls CheckDataDir at startup.
+
+func openSavedSearches(dataDir string) *savedsearch.Store {
+	st, err := savedsearch.Open(dataPath(dataDir, savedSearchFile))
+	if err != nil {
+		log.Printf("ERROR: saved searches unavailable, changes will not persist: %v", err)
+		st, _ = savedsearch.Open("")
+	}
+	return st
+}
+
+func openSnapshots(dataDir string) *snapshot.Store {
+	st, err := snapshot.Open(dataPath(dataDir, snapshotFile))
+	if err != nil {
+		log.Printf("ERROR: search snapshots unavailable, changes will not persist: %v", err)
+		st, _ = snapshot.Open("")
+	}
+	return st
+}
+
+func openVersions(dataDir string) *versions.Store {
+	st, err := versions.Open(dataPath(dataDir, versionFile))
+	if err != nil {
+		log.Printf("ERROR: opportunity versions unavailable, changes will not persist: %v", err)
+		st, _ = versions.Open("")
+	}
+	return st
+}
+
+func openDeliveries(dataDir string) *notify.Queue {
+	q, err := notify.Open(dataPath(dataDir, deliveryFile))
+	if err != nil {
+		log.Printf("ERROR: notification queue unavailable, deliveries will not persist: %v", err)
+		q, _ = notify.Open("")
+	}
+	return q
+}
+
+func openDigests(dataDir string) *notify.Digest {
+	d, err := notify.OpenDigest(dataPath(dataDir, digestFile))
+	if err != nil {
+		log.Printf("ERROR: email digest inbox unavailable, held notices will not persist: %v", err)
+		d, _ = notify.OpenDigest("")
+	}
+	return d
+}
@@ -0,0 +1,276 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"sam-mcp/internal/sam"
+	"sam-mcp/internal/snapshot"
+)
+
+// Compact text renderings of tool results, returned as the text content block alongside
+// the structured payload. They favour one line per record so models can scan them cheaply.
+
+// joinNonEmpty joins the non-empty parts with "; ".
+func joinNonEmpty(parts ...string) string {
+	out := parts[:0:0]
+	for _, p := range parts {
+		if p != "" {
+			out = append(out, p)
+		}
+	}
+	return strings.Join(out, "; ")
+}
+
+func dateText(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+// prefixed returns label+" "+v, or "" when v is empty.
+func prefixed(label, v string) string {
+	if v == "" {
+		return ""
+	}
+	return label + " " + v
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return "1 " + one
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
+
+func (r *searchResult) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (samEnv %s)", plural(len(r.Results), "opportunity", "opportunities"), r.SamEnv)
+	if r.ResolvedOrganization != nil {
+		fmt.Fprintf(&b, "; organization resolved to %s (%s)", r.ResolvedOrganization.Path, r.ResolvedOrganization.Organization.ID)
+	}
+	for _, o := range r.Results {
+		b.WriteString("\n- " + opportunityText(o))
+	}
+	for _, w := range r.Warnings {
+		b.WriteString("\nwarning: " + w)
+	}
+	return b.String()
+}
+
+// opportunityText is the one-line rendering of an opportunity used in search results.
+func opportunityText(o sam.Opportunity) string {
+	return joinNonEmpty(o.Title, o.Agency, prefixed("notice", o.NoticeID), prefixed("solicitation", o.SolicitationNumber),
+		prefixed("NAICS", o.NAICS), prefixed("PSC", o.PSC), prefixed("modified", dateText(o.Modified)), awardText(o.Award), o.URL)
+}
+
+func awardText(a *sam.Award) string {
+	if a == nil || a.Awardee == nil {
+		return ""
+	}
+	s := "awarded to " + a.Awardee.Name
+	if a.Awardee.Excluded != nil && *a.Awardee.Excluded {
+		s += " (EXCLUDED)"
+	}
+	return s
+}
+
+func (r *entityResult) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s of %d (page %d, samEnv %s)", plural(len(r.Entities), "entity", "entities"), r.TotalRecords, r.Page, r.SamEnv)
+	for _, e := range r.Entities {
+		excluded := ""
+		if e.Excluded != nil && *e.Excluded {
+			excluded = "EXCLUDED"
+		}
+		b.WriteString("\n- " + joinNonEmpty(e.LegalName, prefixed("UEI", e.UEI), prefixed("CAGE", e.CAGE), e.RegistrationStatus,
+			prefixed("expires", dateText(e.ExpirationDate)), prefixed("primary NAICS", e.PrimaryNAICS), excluded))
+	}
+	return b.String()
+}
+
+func (r *exclusionsResult) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s checked (samEnv %s)", plural(len(r.Results), "entity", "entities"), r.SamEnv)
+	for _, res := range r.Results {
+		who := firstNonEmpty(res.Query.UEI, res.Query.Name)
+		if !res.Excluded {
+			b.WriteString("\n- " + who + ": no active exclusions")
+			continue
+		}
+		b.WriteString("\n- " + who + ": EXCLUDED")
+		for _, x := range res.Exclusions {
+			b.WriteString("\n  - " + joinNonEmpty(x.Name, x.Type, x.AgencyName, prefixed("active", dateText(x.ActiveDate)), prefixed("until", dateText(x.TerminationDate))))
+		}
+	}
+	return b.String()
+}
+
+func (r *awardsResult) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s of %d (samEnv %s)", plural(len(r.Awards), "award", "awards"), r.TotalRecords, r.SamEnv)
+	for _, a := range r.Awards {
+		b.WriteString("\n- " + joinNonEmpty(prefixed("PIID", a.PIID), a.Vendor.Name, prefixed("UEI", a.Vendor.UEI), a.AgencyName,
+			fmt.Sprintf("obligated $%.0f", a.TotalObligated), prefixed("signed", dateText(a.DateSigned)),
+			prefixed("ends", dateText(a.PeriodUltimateEnd)), prefixed("solicitation", a.SolicitationNumber)))
+	}
+	return b.String()
+}
+
+func (r *resolveOrgResult) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (samEnv %s)", plural(len(r.Matches), "match", "matches"), r.SamEnv)
+	for _, m := range r.Matches {
+		o := m.Organization
+		b.WriteString("\n- " + joinNonEmpty(m.Path, prefixed("id", o.ID), prefixed("agency code", o.AgencyCode), prefixed("office code", o.OfficeCode), fmt.Sprintf("score %.2f", m.Score)))
+	}
+	return b.String()
+}
+
+func (r *assistanceResult) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s of %d (samEnv %s)", plural(len(r.Listings), "listing", "listings"), r.TotalRecords, r.SamEnv)
+	for _, l := range r.Listings {
+		b.WriteString("\n- " + joinNonEmpty(l.Number, l.Title, l.Agency, strings.Join(l.AssistanceTypes, ", "), l.URL))
+	}
+	return b.String()
+}
+
+func (r *wageResult) Text() string {
+	if r.State != "" {
+		place := r.State
+		if r.County != "" {
+			place = r.County + ", " + r.State
+		}
+		return joinNonEmpty("Wage determinations for "+place, r.URL)
+	}
+	revision := "latest revision"
+	if r.Revision > 0 {
+		revision = fmt.Sprintf("revision %d", r.Revision)
+	}
+	return joinNonEmpty(r.Type+" wage determination "+r.Number, revision, r.URL)
+}
+
+func (r *naicsResult) Text() string {
+	var b strings.Builder
+	b.WriteString(plural(len(r.Codes), "NAICS code", "NAICS codes"))
+	for _, c := range r.Codes {
+		b.WriteString("\n- " + joinNonEmpty(c.Code+" "+c.Title, c.Level, prefixed("size standard", c.SizeStandard)))
+	}
+	return b.String()
+}
+
+func (r *pscResult) Text() string {
+	var b strings.Builder
+	b.WriteString(plural(len(r.Codes), "PSC code", "PSC codes"))
+	for _, c := range r.Codes {
+		b.WriteString("\n- " + joinNonEmpty(c.Code+" "+c.Title, c.Kind))
+	}
+	return b.String()
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func (r *savedSearchList) Text() string {
+	var b strings.Builder
+	b.WriteString(plural(len(r.SavedSearches), "saved search", "saved searches"))
+	for _, ss := range r.SavedSearches {
+		b.WriteString("\n- " + joinNonEmpty(ss.ID+": "+ss.Name, prefixed("owner", ss.Owner), prefixed("schedule", ss.Schedule),
+			prefixed("last run", dateText(derefTime(ss.LastRun))), plural(ss.SeenCount, "notice seen", "notices seen")))
+	}
+	return b.String()
+}
+
+func (r *savedSearchRun) Text() string {
+	isNew := make(map[string]bool, len(r.New))
+	for _, id := range r.New {
+		isNew[id] = true
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s, %d new (samEnv %s)", r.SavedSearch.Name, plural(len(r.Results), "opportunity", "opportunities"), len(r.New), r.SamEnv)
+	for _, o := range r.Results {
+		mark := ""
+		if isNew[o.NoticeID] {
+			mark = "[new] "
+		}
+		b.WriteString("\n- " + mark + opportunityText(o))
+	}
+	return b.String()
+}
+
+func derefTime(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
+
+func (r *whatsNewResult) Text() string {
+	var b strings.Builder
+	if r.LastRun == nil {
+		return r.SavedSearchID + " has not run yet; pass refresh to run it now"
+	}
+	since := "the last run"
+	if r.Since != nil {
+		since = r.Since.UTC().Format(time.RFC3339)
+	}
+	fmt.Fprintf(&b, "%s since %s (last run %s): %d added, %d amended, %d removed", r.SavedSearchID, since,
+		r.LastRun.UTC().Format(time.RFC3339), len(r.Added), len(r.Amended), len(r.Removed))
+	for _, group := range []struct {
+		label   string
+		changes []snapshot.Change
+	}{{"added", r.Added}, {"amended", r.Amended}, {"removed", r.Removed}} {
+		for _, c := range group.changes {
+			b.WriteString("\n- " + group.label + ": " + joinNonEmpty(c.Title, c.Agency, prefixed("notice", c.NoticeID),
+				prefixed("modified", dateText(c.Modified)), prefixed("changed", strings.Join(c.Fields, ", "))))
+		}
+	}
+	return b.String()
+}
+
+func (r *diffOpportunityResult) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s seen", joinNonEmpty(r.NoticeID, r.Title), plural(len(r.Versions), "version", "versions"))
+	if r.Diff == nil {
+		b.WriteString("; nothing to compare yet")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "; version %d (modified %s) to %d (modified %s)", r.From.Version, dateText(r.From.Modified), r.To.Version, dateText(r.To.Modified))
+	d := r.Diff
+	for _, f := range d.Fields {
+		fmt.Fprintf(&b, "\n- %s: %s -> %s", f.Field, rawText(f.From), rawText(f.To))
+	}
+	for _, a := range d.AttachmentsAdded {
+		b.WriteString("\n- attachment added: " + a)
+	}
+	for _, a := range d.AttachmentsRemoved {
+		b.WriteString("\n- attachment removed: " + a)
+	}
+	switch {
+	case len(d.Description) > 0:
+		b.WriteString("\ndescription:")
+		for _, l := range d.Description {
+			b.WriteString("\n" + l.Op + " " + l.Text)
+		}
+	case d.DescriptionChanged:
+		b.WriteString("\ndescription changed; text not available for both versions")
+	}
+	return b.String()
+}
+
+// rawText renders a JSON field value, or "(none)" when absent.
+func rawText(v []byte) string {
+	if len(v) == 0 {
+		return "(none)"
+	}
+	return string(v)
+}
@@ -0,0 +1,26 @@
+package server
+
+import "sam-mcp/internal/mcp"
+
+// builtinTools declares every tool implemented by this package, in listing order. Each
+// tool's input schema is generated from its args struct and enforced before dispatch.
+func (s *Server) builtinTools() []mcp.Handler {
+	return []mcp.Handler{
+		mcp.NewTool("sam_search", "Search SAM.gov opportunities", s.toolSamSearch),
+		mcp.NewTool("sam_entity_lookup", "Look up SAM.gov entity registrations by UEI, CAGE code or legal business name", s.toolEntityLookup),
+		mcp.NewTool("sam_check_exclusions", "Check UEIs or entity names against SAM.gov exclusions (debarments, suspensions)", s.toolCheckExclusions),
+		mcp.NewTool("sam_contract_awards", "Search contract award history (obligations, period of performance, vendor) to identify incumbents; pass a sam_search result's solicitationNumber to find who holds the current contract", s.toolContractAwards),
+		mcp.NewTool("sam_resolve_organization", "Resolve a department, sub-tier or office name (e.g. \"Army\", \"Department of Veterans Affairs\") to Federal Hierarchy codes and parent path", s.toolResolveOrganization),
+		mcp.NewTool("sam_assistance_search", "Search SAM.gov Assistance Listings (federal grants and other financial assistance programs, formerly CFDA)", s.toolAssistanceSearch),
+		mcp.NewTool("sam_wage_determination", "Link to the SAM.gov page for a Service Contract Act or Davis-Bacon wage determination by WD number and optional revision, where its occupation rates are published, or to the wage determination search for a state and optional county; sam_search results link to the wage determination search via wageDeterminationsUrl when the place of performance is known", s.toolWageDetermination),
+		mcp.NewTool("naics_lookup", "Find NAICS 2022 codes by code prefix (e.g. 5415) or keywords (e.g. janitorial), with SBA size standards", s.toolNAICSLookup),
+		mcp.NewTool("psc_lookup", "Find Product and Service Codes (PSC) by code prefix (e.g. DA, R4) or keywords (e.g. guard)", s.toolPSCLookup),
+		mcp.NewTool("saved_search_create", "Save a SAM.gov search with an owner, optional cron schedule and notification targets; its results are readable as sam://search/{id}", s.toolSavedSearchCreate),
+		mcp.NewTool("saved_search_list", "List saved searches, optionally for one owner", s.toolSavedSearchList),
+		mcp.NewTool("saved_search_update", "Change a saved search's name, owner, parameters, schedule or notification targets", s.toolSavedSearchUpdate),
+		mcp.NewTool("saved_search_delete", "Delete a saved search", s.toolSavedSearchDelete),
+		mcp.NewTool("saved_search_run", "Run a saved search now; reports which notices it returned for the first time", s.toolSavedSearchRun),
+		mcp.NewTool("sam_whats_new", "Report opportunities added, amended (with the changed fields) or removed since a given time or the last run of a prefetch profile or saved search", s.toolWhatsNew),
+		mcp.NewTool("sam_diff_opportunity", "Compare two versions of an opportunity seen by the server: changed fields, a line diff of the description and attachments added or removed; defaults to the latest amendment", s.toolDiffOpportunity),
+	}
+}
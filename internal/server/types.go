@@ -1,11 +1,9 @@
 package server
 
-// Tool describes an MCP tool and its input schema exposed by this server.
-type Tool struct {
-    Name        string                 `json:"name"`
-    Description string                 `json:"description"`
-    InputSchema map[string]interface{} `json:"inputSchema"`
-}
+import "sam-mcp/internal/mcp"
+
+// Tool describes an MCP tool and its input and output schemas exposed by this server.
+type Tool = mcp.Tool
 
 // CallRequest represents a request to invoke a specific MCP tool with arguments.
 type CallRequest struct {
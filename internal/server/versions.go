@@ -0,0 +1,166 @@
+package server
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"sam-mcp/internal/mcp"
+	"sam-mcp/internal/sam"
+	"sam-mcp/internal/versions"
+)
+
+// Description fetching. Descriptions served by link are fetched in the background, at
+// most maxDescriptionFetches per pass, so searches never wait on them.
+const (
+	maxDescriptionFetches = 10
+	descriptionPoll       = 10 * time.Minute
+	descriptionPause      = time.Minute
+)
+
+// recordVersions adds a version for each new or amended opportunity and wakes the
+// description fetcher. Failures are logged: version history must not fail the search that
+// fed it.
+func (s *Server) recordVersions(opps []sam.Opportunity) {
+	if err := s.versions.Observe(opps, time.Now()); err != nil {
+		log.Printf("WARN: opportunity versions not saved: %v", err)
+	}
+	select {
+	case s.describe <- struct{}{}:
+	default:
+	}
+}
+
+// runDescriptions fetches pending descriptions until ctx is cancelled: after each search,
+// every descriptionPoll for retries, and after a short pause while a backlog remains.
+func (s *Server) runDescriptions(ctx context.Context) {
+	for {
+		wait := descriptionPoll
+		if s.fetchDescriptions(ctx, s.versions.Pending(time.Now(), maxDescriptionFetches)) == maxDescriptionFetches {
+			wait = descriptionPause
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-s.describe:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// fetchDescriptions fetches and stores the given pending descriptions and returns how many
+// it tried. A failed link is retried with a backoff, and given up after repeated failures.
+func (s *Server) fetchDescriptions(ctx context.Context, pending []versions.Pending) int {
+	texts := make(map[versions.Pending]string)
+	for _, p := range pending {
+		if ctx.Err() != nil {
+			break
+		}
+		text, err := s.sam.NoticeDescription(ctx, p.DescriptionURL)
+		if err != nil {
+			log.Printf("WARN: description of %s not fetched: %v", p.NoticeID, err)
+			if err := s.versions.DescriptionFailed(p, time.Now()); err != nil {
+				log.Printf("WARN: opportunity versions not saved: %v", err)
+			}
+			continue
+		}
+		texts[p] = text
+	}
+	if err := s.versions.SetDescriptions(texts); err != nil {
+		log.Printf("WARN: opportunity descriptions not saved: %v", err)
+	}
+	return len(pending)
+}
+
+// diffOpportunityArgs are the sam_diff_opportunity arguments.
+type diffOpportunityArgs struct {
+	NoticeID string `json:"noticeId" jsonschema:"required,minLength=1"`
+	From     int    `json:"from" jsonschema:"min=1" description:"Version to compare from; defaults to the version before to"`
+	To       int    `json:"to" jsonschema:"min=1" description:"Version to compare to; defaults to the latest"`
+}
+
+// versionInfo summarizes a stored version.
+type versionInfo struct {
+	Version  int       `json:"version"`
+	Seen     time.Time `json:"seen"`
+	Modified time.Time `json:"modified"`
+	// DescriptionFetched reports whether the description text is available for diffing.
+	DescriptionFetched bool `json:"descriptionFetched"`
+}
+
+// diffOpportunityResult is the sam_diff_opportunity response. From and To are nil, and
+// Diff empty, while fewer than two versions have been seen.
+type diffOpportunityResult struct {
+	NoticeID string         `json:"noticeId"`
+	Title    string         `json:"title,omitempty"`
+	Versions []versionInfo  `json:"versions"`
+	From     *versionInfo   `json:"from,omitempty"`
+	To       *versionInfo   `json:"to,omitempty"`
+	Diff     *versions.Diff `json:"diff,omitempty"`
+}
+
+// toolDiffOpportunity serves the sam_diff_opportunity tool: what an amendment changed,
+// from the versions of the notice the server has seen.
+func (s *Server) toolDiffOpportunity(ctx context.Context, a diffOpportunityArgs) (*diffOpportunityResult, error) {
+	vs := s.versions.List(a.NoticeID)
+	if len(vs) == 0 {
+		o, err := s.opportunity(ctx, a.NoticeID)
+		if err != nil {
+			return nil, mcp.Upstream("sam api error: %v", err)
+		}
+		if o == nil {
+			return nil, mcp.InvalidParams("unknown notice: "+a.NoticeID, nil)
+		}
+		vs = s.versions.List(a.NoticeID)
+	}
+	// The latest description may still be waiting for the background fetcher.
+	if p, ok := s.versions.PendingNotice(a.NoticeID, time.Now()); ok {
+		s.fetchDescriptions(ctx, []versions.Pending{p})
+		vs = s.versions.List(a.NoticeID)
+	}
+	out := &diffOpportunityResult{NoticeID: a.NoticeID, Versions: make([]versionInfo, 0, len(vs))}
+	byNumber := make(map[int]versions.Version, len(vs))
+	for _, v := range vs {
+		out.Versions = append(out.Versions, infoOf(v))
+		byNumber[v.Number] = v
+	}
+	if len(vs) > 0 {
+		out.Title = vs[len(vs)-1].Opportunity.Title
+	}
+
+	to := a.To
+	if to == 0 && len(vs) > 0 {
+		to = vs[len(vs)-1].Number
+	}
+	from := a.From
+	if from == 0 {
+		// The version before to, which need not be to-1 once old versions are dropped.
+		for _, v := range vs {
+			if v.Number < to {
+				from = v.Number
+			}
+		}
+	}
+	if a.From == 0 && a.To == 0 && from == 0 {
+		return out, nil
+	}
+	vf, okf := byNumber[from]
+	vt, okt := byNumber[to]
+	switch {
+	case !okf || !okt:
+		return nil, mcp.InvalidParams("unknown version", map[string]interface{}{"from": from, "to": to, "versions": out.Versions})
+	case from >= to:
+		return nil, mcp.InvalidParams("from must be an earlier version than to", nil)
+	}
+	fi, ti := infoOf(vf), infoOf(vt)
+	d := versions.Compare(vf, vt)
+	out.From, out.To, out.Diff = &fi, &ti, &d
+	return out, nil
+}
+
+func infoOf(v versions.Version) versionInfo {
+	return versionInfo{Version: v.Number, Seen: v.Seen, Modified: v.Opportunity.Modified, DescriptionFetched: v.DescriptionFetched}
+}
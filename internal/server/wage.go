@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	"sam-mcp/internal/mcp"
+	"sam-mcp/internal/sam"
+)
+
+// wageArgs are the sam_wage_determination arguments: a WD number, or a place of
+// performance to search by.
+type wageArgs struct {
+	Number   string `json:"number" description:"Wage determination number, e.g. 2015-4281 (SCA) or VA20240001 (DBA)"`
+	Revision int    `json:"revision" jsonschema:"min=1" description:"Revision number; the latest when omitted"`
+	State    string `json:"state" description:"Two-letter state code to search wage determinations by place of performance, instead of number"`
+	County   string `json:"county" description:"County name narrowing a state search, e.g. Fairfax"`
+}
+
+// wageResult is the sam_wage_determination response.
+type wageResult struct {
+	Number   string `json:"number,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Revision int    `json:"revision,omitempty"`
+	State    string `json:"state,omitempty"`
+	County   string `json:"county,omitempty"`
+	URL      string `json:"url"`
+}
+
+// toolWageDetermination serves the sam_wage_determination tool: the SAM.gov page for an
+// SCA or DBA wage determination, where its occupation rates are published, or the
+// wage determination search for a state and county. SAM.gov has no public wage
+// determination API, so numbers are checked and linked rather than fetched.
+func (s *Server) toolWageDetermination(_ context.Context, a wageArgs) (*wageResult, error) {
+	number := strings.ToUpper(strings.TrimSpace(a.Number))
+	state := strings.ToUpper(strings.TrimSpace(a.State))
+	county := strings.TrimSpace(a.County)
+	switch {
+	case number != "" && state != "":
+		return nil, mcp.InvalidParams("give either number or state, not both", nil)
+	case state != "":
+		if len(state) != 2 || !isLetters(state) {
+			return nil, mcp.InvalidParams("state must be a two-letter code such as VA", nil)
+		}
+		if a.Revision > 0 {
+			return nil, mcp.InvalidParams("revision applies to a number, not a state search", nil)
+		}
+		return &wageResult{State: state, County: county, URL: sam.WageDeterminationSearchURL(state, county)}, nil
+	case number == "":
+		return nil, mcp.InvalidParams("number or state is required", nil)
+	case county != "":
+		return nil, mcp.InvalidParams("county narrows a state search; give state too", nil)
+	}
+	wdType := sam.WageDeterminationType(number)
+	if wdType == "" {
+		return nil, mcp.InvalidParams("number must be an SCA (e.g. 2015-4281) or DBA (e.g. VA20240001) wage determination number", nil)
+	}
+	return &wageResult{Number: number, Type: wdType, Revision: a.Revision, URL: sam.WageDeterminationURL(number, a.Revision)}, nil
+}
+
+// isLetters reports whether s is made of ASCII letters.
+func isLetters(s string) bool {
+	for _, r := range s {
+		if (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') {
+			return false
+		}
+	}
+	return true
+}
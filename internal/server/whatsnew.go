@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"sam-mcp/internal/mcp"
+	"sam-mcp/internal/scheduler"
+	"sam-mcp/internal/snapshot"
+)
+
+// whatsNewArgs are the sam_whats_new arguments.
+type whatsNewArgs struct {
+	SavedSearchID string    `json:"savedSearchId" jsonschema:"required" description:"Prefetch profile name or saved search id"`
+	Since         time.Time `json:"since" description:"Report changes detected after this time; by default, the changes found by the latest run"`
+	Refresh       bool      `json:"refresh" description:"Run the search now before reporting"`
+}
+
+// whatsNewResult is the sam_whats_new response.
+type whatsNewResult struct {
+	SavedSearchID string     `json:"savedSearchId"`
+	Since         *time.Time `json:"since,omitempty"`
+	// LastRun is when the search last ran; nil if it never has.
+	LastRun *time.Time        `json:"lastRun,omitempty"`
+	Added   []snapshot.Change `json:"added"`
+	Amended []snapshot.Change `json:"amended"`
+	// Removed lists notices the search no longer returns (archived, cancelled or outside
+	// its posted-date window).
+	Removed []snapshot.Change `json:"removed"`
+}
+
+// toolWhatsNew serves the sam_whats_new tool: notices added, amended or removed between
+// runs of a prefetch profile or saved search.
+func (s *Server) toolWhatsNew(ctx context.Context, a whatsNewArgs) (*whatsNewResult, error) {
+	var key string
+	if _, ok := s.profile(a.SavedSearchID); ok {
+		key = profileJob(a.SavedSearchID)
+		if a.Refresh {
+			if _, err := s.scheduler.RunNow(ctx, key); err != nil && !errors.Is(err, scheduler.ErrRunning) {
+				return nil, mcp.Upstream("sam api error: %v", err)
+			}
+		}
+	} else if _, ok := s.savedSearches.Get(a.SavedSearchID); ok {
+		key = savedSearchJob(a.SavedSearchID)
+		if a.Refresh {
+			if _, err := s.runSavedSearch(ctx, a.SavedSearchID); err != nil {
+				return nil, mcp.Upstream("sam api error: %v", err)
+			}
+		}
+	} else {
+		return nil, mcp.InvalidParams("unknown prefetch profile or saved search: "+a.SavedSearchID, nil)
+	}
+
+	changes, lastRun := s.snapshots.Since(key, a.Since)
+	out := &whatsNewResult{SavedSearchID: a.SavedSearchID}
+	out.Added, out.Amended, out.Removed = snapshot.Summarize(changes)
+	if !a.Since.IsZero() {
+		out.Since = &a.Since
+	}
+	if !lastRun.IsZero() {
+		out.LastRun = &lastRun
+	}
+	return out, nil
+}
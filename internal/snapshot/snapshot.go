@@ -0,0 +1,296 @@
+// Package snapshot keeps, for each prefetch profile and saved search, the notices its last
+// run returned and a log of the notices added, amended and removed between runs.
+package snapshot
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"sam-mcp/internal/jsonfile"
+	"sam-mcp/internal/sam"
+)
+
+// Change kinds.
+const (
+	Added   = "added"
+	Amended = "amended"
+	// Removed marks a notice the search no longer returns: archived, cancelled or outside
+	// the search's posted-date window.
+	Removed = "removed"
+)
+
+// Retention of the change log, per source.
+const (
+	MaxAge     = 90 * 24 * time.Hour
+	MaxChanges = 2000
+)
+
+// Change is one difference between consecutive runs of a search.
+type Change struct {
+	Kind     string    `json:"kind"`
+	NoticeID string    `json:"noticeId"`
+	Title    string    `json:"title,omitempty"`
+	Agency   string    `json:"agency,omitempty"`
+	Modified time.Time `json:"modified"`
+	// Detected is when the run that found the change happened.
+	Detected time.Time `json:"detected"`
+	// Fields lists the opportunity fields (by JSON name) that differ, for amended notices.
+	Fields []string `json:"fields,omitempty"`
+}
+
+// entry is the state of one notice in a snapshot.
+type entry struct {
+	Title    string                     `json:"title,omitempty"`
+	Agency   string                     `json:"agency,omitempty"`
+	Modified time.Time                  `json:"modified"`
+	Fields   map[string]json.RawMessage `json:"fields"`
+}
+
+// source is the snapshot and change log of one search.
+type source struct {
+	LastRun time.Time        `json:"lastRun"`
+	Notices map[string]entry `json:"notices"`
+	Changes []Change         `json:"changes"`
+}
+
+// Store holds snapshots in memory, writing them to a JSON file after every run.
+type Store struct {
+	mu      sync.Mutex
+	path    string
+	sources map[string]*source
+}
+
+// Open loads the store at path, which need not exist yet. An empty path keeps snapshots in
+// memory only.
+func Open(path string) (*Store, error) {
+	st := &Store{path: path, sources: make(map[string]*source)}
+	if path == "" {
+		return st, nil
+	}
+	if _, err := jsonfile.Load(path, &st.sources); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// Record compares a run's results with the source's previous snapshot, appends the changes
+// to its log and makes the results the new snapshot. On a source's first run every notice
+// is reported as added.
+func (st *Store) Record(key string, opps []sam.Opportunity, at time.Time) ([]Change, error) {
+	at = at.UTC()
+	next := make(map[string]entry, len(opps))
+	for _, o := range opps {
+		if o.NoticeID != "" {
+			next[o.NoticeID] = newEntry(o)
+		}
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	src := st.sources[key]
+	if src == nil {
+		src = &source{}
+		st.sources[key] = src
+	}
+	changes := make([]Change, 0)
+	for id, e := range next {
+		prev, ok := src.Notices[id]
+		switch {
+		case !ok:
+			changes = append(changes, e.change(Added, id, at, nil))
+		default:
+			if fields := ChangedFields(prev.Fields, e.Fields); len(fields) > 0 {
+				changes = append(changes, e.change(Amended, id, at, fields))
+			}
+		}
+	}
+	for id, e := range src.Notices {
+		if _, ok := next[id]; !ok {
+			changes = append(changes, e.change(Removed, id, at, nil))
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Kind != changes[j].Kind {
+			return kindOrder[changes[i].Kind] < kindOrder[changes[j].Kind]
+		}
+		return changes[i].NoticeID < changes[j].NoticeID
+	})
+
+	src.LastRun = at
+	src.Notices = next
+	src.Changes = append(src.Changes, changes...)
+	src.prune(at)
+	return changes, st.save()
+}
+
+// Since returns the changes detected after t, oldest first, and when the source last ran.
+// A zero t selects the changes found by the last run.
+func (st *Store) Since(key string, t time.Time) ([]Change, time.Time) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	src := st.sources[key]
+	if src == nil {
+		return nil, time.Time{}
+	}
+	out := make([]Change, 0)
+	for _, c := range src.Changes {
+		if (t.IsZero() && c.Detected.Equal(src.LastRun)) || (!t.IsZero() && c.Detected.After(t)) {
+			out = append(out, c)
+		}
+	}
+	return out, src.LastRun
+}
+
+// LastRun reports when a source last ran; zero if it never has.
+func (st *Store) LastRun(key string) time.Time {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if src := st.sources[key]; src != nil {
+		return src.LastRun
+	}
+	return time.Time{}
+}
+
+// Summarize folds a run of changes, oldest first, into one change per notice: a notice
+// added and later amended is reported as added, amendments are merged, and a notice added
+// and removed again within the changes is left out.
+func Summarize(changes []Change) (added, amended, removed []Change) {
+	type state struct {
+		c     Change
+		added bool
+	}
+	byID := make(map[string]*state)
+	var order []string
+	for _, c := range changes {
+		st, ok := byID[c.NoticeID]
+		if !ok {
+			byID[c.NoticeID] = &state{c: c, added: c.Kind == Added}
+			order = append(order, c.NoticeID)
+			continue
+		}
+		switch c.Kind {
+		case Added:
+			st.c, st.added = c, true
+		case Amended:
+			fields := mergeFields(st.c.Fields, c.Fields)
+			st.c = c
+			st.c.Fields = fields
+			if st.added {
+				st.c.Kind, st.c.Fields = Added, nil
+			}
+		case Removed:
+			st.c = c
+		}
+	}
+	added, amended, removed = []Change{}, []Change{}, []Change{}
+	for _, id := range order {
+		st := byID[id]
+		switch {
+		case st.c.Kind == Removed && st.added:
+		case st.c.Kind == Removed:
+			removed = append(removed, st.c)
+		case st.added:
+			added = append(added, st.c)
+		default:
+			amended = append(amended, st.c)
+		}
+	}
+	return added, amended, removed
+}
+
+func mergeFields(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var out []string
+	for _, f := range append(append([]string(nil), a...), b...) {
+		if !seen[f] {
+			seen[f] = true
+			out = append(out, f)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Forget drops a source's snapshot and change log.
+func (st *Store) Forget(key string) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if _, ok := st.sources[key]; !ok {
+		return nil
+	}
+	delete(st.sources, key)
+	return st.save()
+}
+
+// save writes the store. Callers hold st.mu.
+func (st *Store) save() error {
+	if st.path == "" {
+		return nil
+	}
+	return jsonfile.Save(st.path, st.sources)
+}
+
+var kindOrder = map[string]int{Added: 0, Amended: 1, Removed: 2}
+
+// prune drops changes older than MaxAge and beyond the newest MaxChanges.
+func (src *source) prune(now time.Time) {
+	cut := 0
+	for cut < len(src.Changes) && now.Sub(src.Changes[cut].Detected) > MaxAge {
+		cut++
+	}
+	if n := len(src.Changes) - cut; n > MaxChanges {
+		cut += n - MaxChanges
+	}
+	if cut > 0 {
+		src.Changes = append([]Change(nil), src.Changes[cut:]...)
+	}
+}
+
+func newEntry(o sam.Opportunity) entry {
+	return entry{Title: o.Title, Agency: o.Agency, Modified: o.Modified, Fields: Fields(o)}
+}
+
+func (e entry) change(kind, id string, at time.Time, fields []string) Change {
+	return Change{Kind: kind, NoticeID: id, Title: e.Title, Agency: e.Agency, Modified: e.Modified, Detected: at, Fields: fields}
+}
+
+// Fields returns an opportunity's fields keyed by JSON name, leaving out the raw SAM.gov
+// record and the titles derived from the bundled catalogs.
+func Fields(o sam.Opportunity) map[string]json.RawMessage {
+	o.Raw, o.NAICSTitle, o.PSCTitle = nil, "", ""
+	raw, _ := json.Marshal(o)
+	var out map[string]json.RawMessage
+	_ = json.Unmarshal(raw, &out)
+	return out
+}
+
+// ChangedFields lists the keys whose values differ between two field sets, sorted.
+func ChangedFields(prev, next map[string]json.RawMessage) []string {
+	var out []string
+	for k, v := range next {
+		if !jsonEqual(prev[k], v) {
+			out = append(out, k)
+		}
+	}
+	for k := range prev {
+		if _, ok := next[k]; !ok {
+			out = append(out, k)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func jsonEqual(a, b json.RawMessage) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	var av, bv interface{}
+	if json.Unmarshal(a, &av) != nil || json.Unmarshal(b, &bv) != nil {
+		return string(a) == string(b)
+	}
+	return reflect.DeepEqual(av, bv)
+}
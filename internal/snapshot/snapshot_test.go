@@ -0,0 +1,81 @@
+package snapshot
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"sam-mcp/internal/sam"
+)
+
+func TestRecordDetectsChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshots.json")
+	st, _ := Open(path)
+	day1 := time.Date(2026, 3, 2, 6, 0, 0, 0, time.UTC)
+	mod := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	first := []sam.Opportunity{
+		{NoticeID: "A", Title: "Guard services", Modified: mod, NAICS: "561612"},
+		{NoticeID: "B", Title: "Janitorial", Modified: mod},
+	}
+	changes, err := st.Record("prefetch:x", first, day1)
+	if err != nil || len(changes) != 2 || changes[0].Kind != Added {
+		t.Fatalf("first run: %+v %v", changes, err)
+	}
+
+	st, _ = Open(path) // the snapshot survives a restart
+	day2 := day1.Add(24 * time.Hour)
+	second := []sam.Opportunity{
+		{NoticeID: "A", Title: "Guard services (amended)", Modified: mod.Add(24 * time.Hour), NAICS: "561612", Raw: map[string]any{"x": 1}},
+		{NoticeID: "C", Title: "Landscaping", Modified: mod},
+	}
+	changes, err = st.Record("prefetch:x", second, day2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Change{
+		{Kind: Added, NoticeID: "C", Title: "Landscaping", Modified: mod, Detected: day2},
+		{Kind: Amended, NoticeID: "A", Title: "Guard services (amended)", Modified: mod.Add(24 * time.Hour), Detected: day2, Fields: []string{"modified", "title"}},
+		{Kind: Removed, NoticeID: "B", Title: "Janitorial", Modified: mod, Detected: day2},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("changes = %+v\nwant %+v", changes, want)
+	}
+
+	if latest, last := st.Since("prefetch:x", time.Time{}); len(latest) != 3 || !last.Equal(day2) {
+		t.Fatalf("latest run: %+v %s", latest, last)
+	}
+	if all, _ := st.Since("prefetch:x", day1.Add(-time.Minute)); len(all) != 5 {
+		t.Fatalf("since day 1: %+v", all)
+	}
+	if none, last := st.Since("unknown", time.Time{}); none != nil || !last.IsZero() {
+		t.Fatal("unknown source should have no changes")
+	}
+	if err := st.Forget("prefetch:x"); err != nil {
+		t.Fatal(err)
+	}
+	if changes, _ := st.Record("prefetch:x", second, day2); len(changes) != 2 {
+		t.Fatalf("forgotten source should start over: %+v", changes)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	c := func(kind, id string, fields ...string) Change {
+		return Change{Kind: kind, NoticeID: id, Fields: fields}
+	}
+	added, amended, removed := Summarize([]Change{
+		c(Added, "A"), c(Amended, "A", "title"),
+		c(Amended, "B", "title"), c(Amended, "B", "modified"),
+		c(Added, "C"), c(Removed, "C"),
+		c(Removed, "D"),
+	})
+	if len(added) != 1 || added[0].NoticeID != "A" || added[0].Fields != nil {
+		t.Errorf("added = %+v", added)
+	}
+	if len(amended) != 1 || !reflect.DeepEqual(amended[0].Fields, []string{"modified", "title"}) {
+		t.Errorf("amended = %+v", amended)
+	}
+	if len(removed) != 1 || removed[0].NoticeID != "D" {
+		t.Errorf("removed = %+v", removed)
+	}
+}
@@ -0,0 +1,197 @@
+package versions
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"sam-mcp/internal/snapshot"
+)
+
+// Line operations in a description diff.
+const (
+	OpSame    = " "
+	OpAdded   = "+"
+	OpRemoved = "-"
+	// OpSkipped stands for a run of unchanged lines left out of the diff.
+	OpSkipped = "@"
+)
+
+// diffContext is the number of unchanged lines kept around each change.
+const diffContext = 2
+
+// maxDiffCells bounds the line-diff table; larger descriptions are diffed as a whole
+// replacement.
+const maxDiffCells = 4_000_000
+
+// FieldChange is one opportunity field whose value differs between two versions.
+type FieldChange struct {
+	Field string          `json:"field"`
+	From  json.RawMessage `json:"from,omitempty"`
+	To    json.RawMessage `json:"to,omitempty"`
+}
+
+// Line is one line of a description diff.
+type Line struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// Diff compares two versions of a notice.
+type Diff struct {
+	// Fields lists changed fields other than the description and attachments, by JSON name.
+	Fields []FieldChange `json:"fields"`
+	// Description is a line diff of the description text; nil when it did not change or
+	// either version's text was never fetched.
+	Description []Line `json:"description,omitempty"`
+	// DescriptionChanged reports a changed description link or text, even when the text
+	// itself is unavailable.
+	DescriptionChanged bool     `json:"descriptionChanged"`
+	AttachmentsAdded   []string `json:"attachmentsAdded"`
+	AttachmentsRemoved []string `json:"attachmentsRemoved"`
+}
+
+// Compare diffs version a against the later version b.
+func Compare(a, b Version) Diff {
+	fa, fb := snapshot.Fields(a.Opportunity), snapshot.Fields(b.Opportunity)
+	d := Diff{Fields: []FieldChange{}}
+	for _, f := range snapshot.ChangedFields(fa, fb) {
+		switch f {
+		case "description", "descriptionUrl":
+			d.DescriptionChanged = true
+		case "attachments":
+		default:
+			d.Fields = append(d.Fields, FieldChange{Field: f, From: fa[f], To: fb[f]})
+		}
+	}
+	if a.DescriptionFetched && b.DescriptionFetched {
+		d.DescriptionChanged = a.Opportunity.Description != b.Opportunity.Description
+		if d.DescriptionChanged {
+			d.Description = diffLines(splitLines(a.Opportunity.Description), splitLines(b.Opportunity.Description))
+		}
+	}
+	d.AttachmentsAdded = minus(b.Opportunity.Attachments, a.Opportunity.Attachments)
+	d.AttachmentsRemoved = minus(a.Opportunity.Attachments, b.Opportunity.Attachments)
+	return d
+}
+
+// splitLines breaks text into non-empty lines, splitting long paragraphs at sentence ends
+// so that an edit to one sentence does not show the whole paragraph as replaced.
+func splitLines(text string) []string {
+	var out []string
+	for _, l := range strings.Split(text, "\n") {
+		l = strings.TrimSpace(l)
+		for len(l) > 200 {
+			i := strings.Index(l[100:], ". ")
+			if i < 0 {
+				break
+			}
+			out = append(out, l[:100+i+1])
+			l = strings.TrimSpace(l[100+i+1:])
+		}
+		if l != "" {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// diffLines returns the changes turning a into b, from a longest common subsequence of
+// lines, keeping diffContext unchanged lines around each change.
+func diffLines(a, b []string) []Line {
+	var all []Line
+	if len(a)*len(b) > maxDiffCells {
+		for _, l := range a {
+			all = append(all, Line{OpRemoved, l})
+		}
+		for _, l := range b {
+			all = append(all, Line{OpAdded, l})
+		}
+		return all
+	}
+	// lcs[i][j] is the LCS length of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			all = append(all, Line{OpSame, a[i]})
+			i, j = i+1, j+1
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			all = append(all, Line{OpRemoved, a[i]})
+			i++
+		default:
+			all = append(all, Line{OpAdded, b[j]})
+			j++
+		}
+	}
+	return trimContext(all)
+}
+
+// trimContext replaces unchanged lines further than diffContext from any change with
+// OpSkipped markers.
+func trimContext(all []Line) []Line {
+	keep := make([]bool, len(all))
+	for i, l := range all {
+		if l.Op == OpSame {
+			continue
+		}
+		for k := max(0, i-diffContext); k <= min(len(all)-1, i+diffContext); k++ {
+			keep[k] = true
+		}
+	}
+	var out []Line
+	skipped := 0
+	for i, l := range all {
+		if keep[i] {
+			if skipped > 0 {
+				out = append(out, skippedLine(skipped))
+				skipped = 0
+			}
+			out = append(out, l)
+			continue
+		}
+		skipped++
+	}
+	if skipped > 0 {
+		out = append(out, skippedLine(skipped))
+	}
+	return out
+}
+
+func skippedLine(n int) Line {
+	if n == 1 {
+		return Line{OpSkipped, "1 unchanged line"}
+	}
+	return Line{OpSkipped, fmt.Sprintf("%d unchanged lines", n)}
+}
+
+// minus returns the elements of a not in b, sorted.
+func minus(a, b []string) []string {
+	in := make(map[string]bool, len(b))
+	for _, s := range b {
+		in[s] = true
+	}
+	out := []string{}
+	for _, s := range a {
+		if !in[s] {
+			out = append(out, s)
+			in[s] = true
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,229 @@
+// Package versions keeps every version of an opportunity the server has seen, so that
+// amendments can be compared field by field.
+package versions
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"sam-mcp/internal/jsonfile"
+	"sam-mcp/internal/sam"
+	"sam-mcp/internal/snapshot"
+)
+
+// Retention limits.
+const (
+	// MaxVersions is the number of versions kept per notice; the oldest are dropped.
+	MaxVersions = 20
+	// MaxIdle drops notices that have not been seen for this long.
+	MaxIdle = 180 * 24 * time.Hour
+	// MaxDescriptionFailures stops fetching a description link after this many failures.
+	MaxDescriptionFailures = 5
+	// DescriptionRetry is the wait after a failed description fetch, doubled after each
+	// further failure.
+	DescriptionRetry = time.Hour
+)
+
+// lastSeenFlush is how long LastSeen updates may stay in memory before Observe writes
+// them; a lost update only delays the idle sweep.
+const lastSeenFlush = 24 * time.Hour
+
+// Version is one distinct state of an opportunity.
+type Version struct {
+	Number int `json:"version"`
+	// Seen is when this version was first observed; LastSeen when it was last returned.
+	Seen     time.Time `json:"seen"`
+	LastSeen time.Time `json:"lastSeen"`
+	// Opportunity is the notice as observed, without the raw SAM.gov record.
+	Opportunity sam.Opportunity `json:"opportunity"`
+	// DescriptionFetched reports whether Opportunity.Description holds the notice text.
+	// Descriptions served by link are fetched separately and may be missing.
+	DescriptionFetched bool `json:"descriptionFetched"`
+	// DescriptionFailures counts failed fetches of the description link; NextFetch is when
+	// it may be tried again.
+	DescriptionFailures int        `json:"descriptionFailures,omitempty"`
+	NextFetch           *time.Time `json:"nextFetch,omitempty"`
+}
+
+// Pending identifies a version whose description still has to be fetched.
+type Pending struct {
+	NoticeID       string
+	Number         int
+	DescriptionURL string
+	// Seen is when the version was first observed.
+	Seen time.Time
+}
+
+// Store holds versions in memory, writing them to a JSON file when a version is added or
+// removed. LastSeen updates alone are written at most once per lastSeenFlush.
+type Store struct {
+	mu      sync.Mutex
+	path    string
+	notices map[string][]*Version
+	// flushed is when the store was last written, in observation time.
+	flushed time.Time
+}
+
+// Open loads the store at path, which need not exist yet. An empty path keeps versions in
+// memory only.
+func Open(path string) (*Store, error) {
+	st := &Store{path: path, notices: make(map[string][]*Version)}
+	if path == "" {
+		return st, nil
+	}
+	if _, err := jsonfile.Load(path, &st.notices); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// Observe records the opportunities returned by a search at the given time, adding a
+// version for each notice that is new or differs from its latest version.
+func (st *Store) Observe(opps []sam.Opportunity, at time.Time) error {
+	at = at.UTC()
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.flushed.IsZero() {
+		st.flushed = at
+	}
+	changed := false
+	for _, o := range opps {
+		if o.NoticeID == "" {
+			continue
+		}
+		o.Raw, o.NAICSTitle, o.PSCTitle = nil, "", ""
+		vs := st.notices[o.NoticeID]
+		if n := len(vs); n > 0 && !differs(vs[n-1].Opportunity, o) {
+			vs[n-1].LastSeen = at
+			continue
+		}
+		v := &Version{Number: 1, Seen: at, LastSeen: at, Opportunity: o, DescriptionFetched: o.DescriptionURL == ""}
+		if n := len(vs); n > 0 {
+			v.Number = vs[n-1].Number + 1
+		}
+		vs = append(vs, v)
+		if len(vs) > MaxVersions {
+			vs = append([]*Version(nil), vs[len(vs)-MaxVersions:]...)
+		}
+		st.notices[o.NoticeID] = vs
+		changed = true
+	}
+	for id, vs := range st.notices {
+		if at.Sub(vs[len(vs)-1].LastSeen) > MaxIdle {
+			delete(st.notices, id)
+			changed = true
+		}
+	}
+	if !changed && at.Sub(st.flushed) < lastSeenFlush {
+		return nil
+	}
+	st.flushed = at
+	return st.save()
+}
+
+// Pending returns up to limit latest versions whose description link is due to be
+// fetched at the given time, newest first. Only a notice's latest version is fetched: the
+// link serves the current text, which would be wrong for an older version.
+func (st *Store) Pending(at time.Time, limit int) []Pending {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	var pending []Pending
+	for id, vs := range st.notices {
+		if v := vs[len(vs)-1]; due(v, at) {
+			pending = append(pending, Pending{NoticeID: id, Number: v.Number, DescriptionURL: v.Opportunity.DescriptionURL, Seen: v.Seen})
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		if !pending[i].Seen.Equal(pending[j].Seen) {
+			return pending[i].Seen.After(pending[j].Seen)
+		}
+		return pending[i].NoticeID < pending[j].NoticeID
+	})
+	if len(pending) > limit {
+		pending = pending[:limit]
+	}
+	return pending
+}
+
+// PendingNotice returns a notice's latest version if its description is due to be fetched.
+func (st *Store) PendingNotice(noticeID string, at time.Time) (Pending, bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	vs := st.notices[noticeID]
+	if len(vs) == 0 || !due(vs[len(vs)-1], at) {
+		return Pending{}, false
+	}
+	v := vs[len(vs)-1]
+	return Pending{NoticeID: noticeID, Number: v.Number, DescriptionURL: v.Opportunity.DescriptionURL, Seen: v.Seen}, true
+}
+
+func due(v *Version, at time.Time) bool {
+	return !v.DescriptionFetched && v.Opportunity.DescriptionURL != "" && v.DescriptionFailures < MaxDescriptionFailures &&
+		(v.NextFetch == nil || !v.NextFetch.After(at))
+}
+
+// SetDescriptions stores fetched description text, keyed by pending version.
+func (st *Store) SetDescriptions(texts map[Pending]string) error {
+	if len(texts) == 0 {
+		return nil
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	for p, text := range texts {
+		for _, v := range st.notices[p.NoticeID] {
+			if v.Number == p.Number {
+				v.Opportunity.Description, v.DescriptionFetched = text, true
+				v.DescriptionFailures, v.NextFetch = 0, nil
+			}
+		}
+	}
+	return st.save()
+}
+
+// DescriptionFailed records a failed fetch of a pending version's description, backing
+// off before the next attempt and giving up after MaxDescriptionFailures.
+func (st *Store) DescriptionFailed(p Pending, at time.Time) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	for _, v := range st.notices[p.NoticeID] {
+		if v.Number == p.Number {
+			v.DescriptionFailures++
+			next := at.UTC().Add(DescriptionRetry << (v.DescriptionFailures - 1))
+			v.NextFetch = &next
+		}
+	}
+	return st.save()
+}
+
+// List returns a notice's versions, oldest first.
+func (st *Store) List(noticeID string) []Version {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	vs := st.notices[noticeID]
+	out := make([]Version, len(vs))
+	for i, v := range vs {
+		out[i] = *v
+	}
+	return out
+}
+
+// save writes the store. Callers hold st.mu.
+func (st *Store) save() error {
+	if st.path == "" {
+		return nil
+	}
+	return jsonfile.Save(st.path, st.notices)
+}
+
+// differs reports whether an observed opportunity differs from a stored version. A
+// description served by link is compared through the link only, since the stored
+// version may hold the fetched text.
+func differs(stored, observed sam.Opportunity) bool {
+	a, b := snapshot.Fields(stored), snapshot.Fields(observed)
+	if observed.DescriptionURL != "" {
+		delete(a, "description")
+		delete(b, "description")
+	}
+	return len(snapshot.ChangedFields(a, b)) > 0
+}
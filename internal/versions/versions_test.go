@@ -0,0 +1,171 @@
+package versions
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"sam-mcp/internal/sam"
+)
+
+func TestObserveAddsVersionsOnChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "versions.json")
+	st, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t0 := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	o := sam.Opportunity{NoticeID: "n1", Title: "Guard services", Modified: t0, DescriptionURL: "https://api.sam.gov/desc?noticeid=n1"}
+
+	if err := st.Observe([]sam.Opportunity{o}, t0); err != nil {
+		t.Fatal(err)
+	}
+	pending := st.Pending(t0, 10)
+	if len(pending) != 1 || pending[0].Number != 1 {
+		t.Fatalf("first observe: %+v", pending)
+	}
+	if err := st.SetDescriptions(map[Pending]string{pending[0]: "Scope."}); err != nil {
+		t.Fatal(err)
+	}
+	// Unchanged: no new version, and the fetched text does not count as a change.
+	_ = st.Observe([]sam.Opportunity{o}, t0.Add(time.Hour))
+	if pending := st.Pending(t0.Add(time.Hour), 10); len(pending) != 0 {
+		t.Fatalf("unchanged notice still pending: %+v", pending)
+	}
+	o.Title, o.Modified = "Guard services (amended)", t0.Add(24*time.Hour)
+	_ = st.Observe([]sam.Opportunity{o}, t0.Add(25*time.Hour))
+	if pending := st.Pending(t0.Add(25*time.Hour), 10); len(pending) != 1 || pending[0].Number != 2 {
+		t.Fatalf("amendment not pending: %+v", pending)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vs := reopened.List("n1")
+	if len(vs) != 2 || vs[0].Opportunity.Description != "Scope." || !vs[0].DescriptionFetched || vs[1].DescriptionFetched {
+		t.Fatalf("unexpected versions: %+v", vs)
+	}
+	if !vs[0].LastSeen.Equal(t0.Add(time.Hour)) {
+		t.Fatalf("last seen not updated: %v", vs[0].LastSeen)
+	}
+}
+
+func TestObserveRetention(t *testing.T) {
+	st, _ := Open("")
+	t0 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < MaxVersions+5; i++ {
+		_ = st.Observe([]sam.Opportunity{{NoticeID: "n1", Modified: t0.AddDate(0, 0, i)}}, t0)
+	}
+	vs := st.List("n1")
+	if len(vs) != MaxVersions || vs[0].Number != 6 || vs[len(vs)-1].Number != MaxVersions+5 {
+		t.Fatalf("unexpected retention: %d versions from %d", len(vs), vs[0].Number)
+	}
+	_ = st.Observe([]sam.Opportunity{{NoticeID: "n2"}}, t0.Add(MaxIdle+time.Hour))
+	if len(st.List("n1")) != 0 || len(st.List("n2")) != 1 {
+		t.Fatal("idle notice not pruned")
+	}
+}
+
+func TestObserveDefersLastSeen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "versions.json")
+	st, _ := Open(path)
+	t0 := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	o := sam.Opportunity{NoticeID: "n1", Title: "Guard services"}
+	lastSeen := func() time.Time {
+		reopened, err := Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return reopened.List("n1")[0].LastSeen
+	}
+
+	_ = st.Observe([]sam.Opportunity{o}, t0)
+	_ = st.Observe([]sam.Opportunity{o}, t0.Add(time.Hour))
+	if got := lastSeen(); !got.Equal(t0) {
+		t.Fatalf("unchanged search rewrote the store: last seen %v", got)
+	}
+	_ = st.Observe([]sam.Opportunity{o}, t0.Add(lastSeenFlush))
+	if got := lastSeen(); !got.Equal(t0.Add(lastSeenFlush)) {
+		t.Fatalf("last seen not flushed: %v", got)
+	}
+}
+
+func TestPendingNewestFirst(t *testing.T) {
+	st, _ := Open("")
+	t0 := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	opp := func(id string) sam.Opportunity {
+		return sam.Opportunity{NoticeID: id, DescriptionURL: "https://api.sam.gov/desc?noticeid=" + id}
+	}
+	_ = st.Observe([]sam.Opportunity{opp("b")}, t0)
+	_ = st.Observe([]sam.Opportunity{opp("d")}, t0.Add(time.Hour))
+	_ = st.Observe([]sam.Opportunity{opp("a")}, t0.Add(2*time.Hour))
+	_ = st.Observe([]sam.Opportunity{opp("a"), opp("b"), opp("c"), opp("d")}, t0.Add(3*time.Hour))
+	pending := st.Pending(t0.Add(3*time.Hour), 10)
+	var got []string
+	for _, p := range pending {
+		got = append(got, p.NoticeID)
+	}
+	if strings.Join(got, ",") != "c,a,d,b" {
+		t.Fatalf("pending order = %v, want newest first", got)
+	}
+	if pending := st.Pending(t0.Add(3*time.Hour), 2); len(pending) != 2 || pending[1].NoticeID != "a" {
+		t.Fatalf("limit not applied: %+v", pending)
+	}
+}
+
+func TestDescriptionFailuresBackOff(t *testing.T) {
+	st, _ := Open("")
+	t0 := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	_ = st.Observe([]sam.Opportunity{{NoticeID: "n1", DescriptionURL: "https://api.sam.gov/desc?noticeid=n1"}}, t0)
+	at := t0
+	for i := 0; i < MaxDescriptionFailures; i++ {
+		p, ok := st.PendingNotice("n1", at)
+		if !ok {
+			t.Fatalf("not due after %d failures at %v", i, at)
+		}
+		_ = st.DescriptionFailed(p, at)
+		wait := DescriptionRetry << i
+		if _, ok := st.PendingNotice("n1", at.Add(wait-time.Minute)); ok {
+			t.Fatalf("due before the %s backoff", wait)
+		}
+		at = at.Add(wait)
+	}
+	if pending := st.Pending(at.Add(365*24*time.Hour), 10); len(pending) != 0 {
+		t.Fatalf("failing link not given up: %+v", pending)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	a := Version{Number: 1, DescriptionFetched: true, Opportunity: sam.Opportunity{
+		NoticeID: "n1", Title: "Guard services", Attachments: []string{"a.pdf", "b.pdf"},
+		Description: "Intro.\nLine one.\nLine two.\nLine three.\nLine four.\nLine five.\nOld closing.",
+	}}
+	b := a
+	b.Number = 2
+	b.Opportunity.Title = "Guard services (amended)"
+	b.Opportunity.Attachments = []string{"b.pdf", "c.pdf"}
+	b.Opportunity.Description = "Intro.\nLine one.\nLine two.\nLine three.\nLine four.\nLine five.\nNew closing."
+
+	d := Compare(a, b)
+	if len(d.Fields) != 1 || d.Fields[0].Field != "title" || string(d.Fields[0].To) != `"Guard services (amended)"` {
+		t.Fatalf("unexpected fields: %+v", d.Fields)
+	}
+	if strings.Join(d.AttachmentsAdded, ",") != "c.pdf" || strings.Join(d.AttachmentsRemoved, ",") != "a.pdf" {
+		t.Fatalf("unexpected attachments: +%v -%v", d.AttachmentsAdded, d.AttachmentsRemoved)
+	}
+	var got []string
+	for _, l := range d.Description {
+		got = append(got, l.Op+l.Text)
+	}
+	want := "@4 unchanged lines| Line four.| Line five.|-Old closing.|+New closing."
+	if !d.DescriptionChanged || strings.Join(got, "|") != want {
+		t.Fatalf("description diff = %q, want %q", strings.Join(got, "|"), want)
+	}
+
+	b.DescriptionFetched = false
+	if d := Compare(a, b); !d.DescriptionChanged || d.Description != nil {
+		t.Fatalf("diff of unfetched description: %+v", d)
+	}
+}